@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -10,7 +11,7 @@ import (
 )
 
 const (
-	accountsURL = "https://api.xero.com/api.xro/2.0/Accounts"
+	accountsPath = "Accounts"
 )
 
 //Account represents individual accounts in a Xero organisation
@@ -23,25 +24,25 @@ type Account struct {
 	Name string `json:"Name,omitempty"`
 
 	// See Account Types
-	Type string `json:"Type,omitempty"`
+	Type AccountType `json:"Type,omitempty"`
 
 	// For bank accounts only (Account Type BANK)
 	BankAccountNumber string `json:"BankAccountNumber,omitempty"`
 
 	// Accounts with a status of ACTIVE can be updated to ARCHIVED. See Account Status Codes
-	Status string `json:"Status,omitempty"`
+	Status AccountStatus `json:"Status,omitempty"`
 
 	// Description of the Account. Valid for all types of accounts except bank accounts (max length = 4000)
 	Description string `json:"Description,omitempty"`
 
 	// For bank accounts only. See Bank Account types
-	BankAccountType string `json:"BankAccountType,omitempty"`
+	BankAccountType BankAccountType `json:"BankAccountType,omitempty"`
 
 	// For bank accounts only
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
 
 	// See Tax Types
-	TaxType string `json:"TaxType,omitempty"`
+	TaxType TaxType `json:"TaxType,omitempty"`
 
 	// Boolean – describes whether account can have payments applied to it
 	EnablePaymentsToAccount bool `json:"EnablePaymentsToAccount,omitempty"`
@@ -53,7 +54,7 @@ type Account struct {
 	AccountID string `json:"AccountID,omitempty"`
 
 	// See Account Class Types
-	Class string `json:"Class,omitempty"`
+	Class AccountClass `json:"Class,omitempty"`
 
 	// If this is a system account then this element is returned. See System Account types. Note that non-system accounts may have this element set as either “” or null.
 	SystemAccount string `json:"SystemAccount,omitempty"`
@@ -68,7 +69,7 @@ type Account struct {
 	HasAttachments bool `json:"HasAttachments,omitempty"`
 
 	// Last modified date UTC format
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
 }
 
 //Accounts contains a collection of Accounts
@@ -76,20 +77,6 @@ type Accounts struct {
 	Accounts []Account `json:"Accounts,omitempty"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (a *Accounts) convertDates() error {
-	var err error
-	for n := len(a.Accounts) - 1; n >= 0; n-- {
-		a.Accounts[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(a.Accounts[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func unmarshalAccount(accountResponseBytes []byte) (*Accounts, error) {
 	var accountResponse *Accounts
 	err := json.Unmarshal(accountResponseBytes, &accountResponse)
@@ -97,21 +84,20 @@ func unmarshalAccount(accountResponseBytes []byte) (*Accounts, error) {
 		return nil, err
 	}
 
-	err = accountResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
-
 	return accountResponse, err
 }
 
 //FindAccountsModifiedSince will get all accounts modified after a specified date.
 //additional querystringParameters such as where and order can be added as a map
 func FindAccountsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Accounts, error) {
-	additionalHeaders := map[string]string{}
-	additionalHeaders["If-Modified-Since"] = modifiedSince.Format(time.RFC3339)
+	return FindAccountsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
 
-	accountResponseBytes, err := helpers.Find(cl, accountsURL, additionalHeaders, queryParameters)
+// FindAccountsModifiedSinceContext is the same as FindAccountsModifiedSince but the request is bound to the given context
+func FindAccountsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Accounts, error) {
+	additionalHeaders := helpers.ModifiedSinceHeaders(modifiedSince)
+
+	accountResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, accountsPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -122,7 +108,12 @@ func FindAccountsModifiedSince(cl *http.Client, modifiedSince time.Time, queryPa
 //FindAccounts will get all accounts. These account will not have details like line items.
 //additional querystringParameters such as where and order can be added as a map
 func FindAccounts(cl *http.Client, queryParameters map[string]string) (*Accounts, error) {
-	accountResponseBytes, err := helpers.Find(cl, accountsURL, nil, queryParameters)
+	return FindAccountsContext(context.Background(), cl, queryParameters)
+}
+
+// FindAccountsContext is the same as FindAccounts but the request is bound to the given context
+func FindAccountsContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*Accounts, error) {
+	accountResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, accountsPath), nil, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +123,12 @@ func FindAccounts(cl *http.Client, queryParameters map[string]string) (*Accounts
 
 //FindAccount will get a single account - accountID must be a GUID for an account
 func FindAccount(cl *http.Client, accountID uuid.UUID) (*Account, error) {
-	accountResponseBytes, err := helpers.Find(cl, accountsURL+"/"+accountID.String(), nil, nil)
+	return FindAccountContext(context.Background(), cl, accountID)
+}
+
+// FindAccountContext is the same as FindAccount but the request is bound to the given context
+func FindAccountContext(ctx context.Context, cl *http.Client, accountID uuid.UUID) (*Account, error) {
+	accountResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, accountsPath, accountID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +144,12 @@ func FindAccount(cl *http.Client, accountID uuid.UUID) (*Account, error) {
 
 // RemoveAccount will get a single account - accountID must be a GUID for an account
 func RemoveAccount(cl *http.Client, accountID uuid.UUID) (*Accounts, error) {
-	accountResponseBytes, err := helpers.Remove(cl, accountsURL+"/"+accountID.String())
+	return RemoveAccountContext(context.Background(), cl, accountID)
+}
+
+// RemoveAccountContext is the same as RemoveAccount but the request is bound to the given context
+func RemoveAccountContext(ctx context.Context, cl *http.Client, accountID uuid.UUID) (*Accounts, error) {
+	accountResponseBytes, err := helpers.RemoveContext(ctx, cl, helpers.AccountingURL(ctx, accountsPath, accountID.String()))
 	if err != nil {
 		return nil, err
 	}
@@ -158,11 +159,19 @@ func RemoveAccount(cl *http.Client, accountID uuid.UUID) (*Accounts, error) {
 
 // Create will create accounts given an Accounts struct
 func (a *Accounts) Create(cl *http.Client) (*Accounts, error) {
+	return a.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (a *Accounts) CreateContext(ctx context.Context, cl *http.Client) (*Accounts, error) {
+	if err := validate(ctx, a); err != nil {
+		return nil, err
+	}
 	buf, err := json.Marshal(a)
 	if err != nil {
 		return nil, err
 	}
-	accountResponseBytes, err := helpers.Create(cl, accountsURL, buf)
+	accountResponseBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, accountsPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -173,6 +182,14 @@ func (a *Accounts) Create(cl *http.Client) (*Accounts, error) {
 // Update will update an account given an Accounts struct
 // This will only handle single account - you cannot update multiple accounts in a single call
 func (a *Account) Update(cl *http.Client) (*Accounts, error) {
+	return a.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (a *Account) UpdateContext(ctx context.Context, cl *http.Client) (*Accounts, error) {
+	if err := validate(ctx, a); err != nil {
+		return nil, err
+	}
 	acc := Accounts{
 		Accounts: []Account{*a},
 	}
@@ -180,10 +197,191 @@ func (a *Account) Update(cl *http.Client) (*Accounts, error) {
 	if err != nil {
 		return nil, err
 	}
-	accountResponseBytes, err := helpers.Update(cl, accountsURL+"/"+a.AccountID, buf)
+	accountResponseBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, accountsPath, a.AccountID), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalAccount(accountResponseBytes)
 }
+
+// Patch will send only the given fields of the account, even when they are
+// zero, so e.g. Patch(cl, "Description") can clear the description
+func (a *Account) Patch(cl *http.Client, fields ...string) (*Accounts, error) {
+	return a.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (a *Account) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (*Accounts, error) {
+	buf, err := patchBody("Accounts", "AccountID", a, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, accountsPath, a.AccountID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalAccount(buf)
+}
+
+// AccountType is the type of an account
+type AccountType string
+
+// The account types
+const (
+	AccountTypeBank                    AccountType = "BANK"
+	AccountTypeCurrent                 AccountType = "CURRENT"
+	AccountTypeCurrLiab                AccountType = "CURRLIAB"
+	AccountTypeDepreciatn              AccountType = "DEPRECIATN"
+	AccountTypeDirectCosts             AccountType = "DIRECTCOSTS"
+	AccountTypeEquity                  AccountType = "EQUITY"
+	AccountTypeExpense                 AccountType = "EXPENSE"
+	AccountTypeFixed                   AccountType = "FIXED"
+	AccountTypeInventory               AccountType = "INVENTORY"
+	AccountTypeLiability               AccountType = "LIABILITY"
+	AccountTypeNonCurrent              AccountType = "NONCURRENT"
+	AccountTypeOtherIncome             AccountType = "OTHERINCOME"
+	AccountTypeOverheads               AccountType = "OVERHEADS"
+	AccountTypePrepayment              AccountType = "PREPAYMENT"
+	AccountTypeRevenue                 AccountType = "REVENUE"
+	AccountTypeSales                   AccountType = "SALES"
+	AccountTypeTermLiab                AccountType = "TERMLIAB"
+	AccountTypePAYGLiability           AccountType = "PAYGLIABILITY"
+	AccountTypeSuperannuationExpense   AccountType = "SUPERANNUATIONEXPENSE"
+	AccountTypeSuperannuationLiability AccountType = "SUPERANNUATIONLIABILITY"
+	AccountTypeWagesExpense            AccountType = "WAGESEXPENSE"
+)
+
+// IsValid reports whether the account type is one of the documented values
+func (v AccountType) IsValid() bool {
+	switch v {
+	case AccountTypeBank,
+		AccountTypeCurrent,
+		AccountTypeCurrLiab,
+		AccountTypeDepreciatn,
+		AccountTypeDirectCosts,
+		AccountTypeEquity,
+		AccountTypeExpense,
+		AccountTypeFixed,
+		AccountTypeInventory,
+		AccountTypeLiability,
+		AccountTypeNonCurrent,
+		AccountTypeOtherIncome,
+		AccountTypeOverheads,
+		AccountTypePrepayment,
+		AccountTypeRevenue,
+		AccountTypeSales,
+		AccountTypeTermLiab,
+		AccountTypePAYGLiability,
+		AccountTypeSuperannuationExpense,
+		AccountTypeSuperannuationLiability,
+		AccountTypeWagesExpense:
+		return true
+	}
+	return false
+}
+
+// AccountClass is the class of an account, given by its type
+type AccountClass string
+
+// The account classes
+const (
+	AccountClassAsset     AccountClass = "ASSET"
+	AccountClassEquity    AccountClass = "EQUITY"
+	AccountClassExpense   AccountClass = "EXPENSE"
+	AccountClassLiability AccountClass = "LIABILITY"
+	AccountClassRevenue   AccountClass = "REVENUE"
+)
+
+// IsValid reports whether the account class is one of the documented values
+func (v AccountClass) IsValid() bool {
+	switch v {
+	case AccountClassAsset,
+		AccountClassEquity,
+		AccountClassExpense,
+		AccountClassLiability,
+		AccountClassRevenue:
+		return true
+	}
+	return false
+}
+
+// AccountStatus is the status of an account
+type AccountStatus string
+
+// The account statuses
+const (
+	AccountStatusActive   AccountStatus = "ACTIVE"
+	AccountStatusArchived AccountStatus = "ARCHIVED"
+	AccountStatusDeleted  AccountStatus = "DELETED"
+)
+
+// IsValid reports whether the status is one of the documented values
+func (v AccountStatus) IsValid() bool {
+	switch v {
+	case AccountStatusActive,
+		AccountStatusArchived,
+		AccountStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// BankAccountType is the type of a bank account
+type BankAccountType string
+
+// The bank account types
+const (
+	BankAccountTypeBank       BankAccountType = "BANK"
+	BankAccountTypeCreditCard BankAccountType = "CREDITCARD"
+	BankAccountTypePayPal     BankAccountType = "PAYPAL"
+)
+
+// IsValid reports whether the bank account type is one of the documented values
+func (v BankAccountType) IsValid() bool {
+	switch v {
+	case BankAccountTypeBank,
+		BankAccountTypeCreditCard,
+		BankAccountTypePayPal:
+		return true
+	}
+	return false
+}
+
+// Validate checks the account against the rules documented by Xero, the
+// required elements are only checked when creating it
+func (a *Account) Validate() error {
+	r := rules{}
+	if a.AccountID == "" {
+		r.required("Code", a.Code != "")
+		r.required("Name", a.Name != "")
+		r.required("Type", a.Type != "")
+		if a.Type == AccountTypeBank {
+			r.required("BankAccountNumber", a.BankAccountNumber != "")
+		}
+	}
+	r.maxLength("Code", a.Code, 10)
+	r.maxLength("Name", a.Name, 150)
+	r.maxLength("Description", a.Description, 4000)
+	r.enum("Type", a.Type, a.Type != "")
+	r.enum("Status", a.Status, a.Status != "")
+	r.enum("Class", a.Class, a.Class != "")
+	r.enum("BankAccountType", a.BankAccountType, a.BankAccountType != "")
+	if a.Type != "" && a.Type != AccountTypeBank {
+		if a.BankAccountNumber != "" {
+			r.add("BankAccountNumber", "is only valid for bank accounts")
+		}
+		if a.BankAccountType != "" {
+			r.add("BankAccountType", "is only valid for bank accounts")
+		}
+	}
+	if a.Type == AccountTypeBank && a.Description != "" {
+		r.add("Description", "is not valid for bank accounts")
+	}
+	return r.err()
+}
+
+// Validate checks every account of the collection
+func (a *Accounts) Validate() error {
+	return validateAll("Accounts", len(a.Accounts), func(n int) validator { return &a.Accounts[n] })
+}
@@ -2,7 +2,7 @@ package accounting
 
 //Address is an address for a contact
 type Address struct {
-	AddressType string `json:"AddressType,omitempty"`
+	AddressType AddressType `json:"AddressType,omitempty"`
 
 	// max length = 500
 	AddressLine1 string `json:"AddressLine1,omitempty"`
@@ -31,3 +31,40 @@ type Address struct {
 	// max length = 255
 	AttentionTo string `json:"AttentionTo,omitempty"`
 }
+
+// AddressType is the type of an address of a contact
+type AddressType string
+
+// The address types
+const (
+	AddressTypePOBox    AddressType = "POBOX"
+	AddressTypeStreet   AddressType = "STREET"
+	AddressTypeDelivery AddressType = "DELIVERY"
+)
+
+// IsValid reports whether the address type is one of the documented values
+func (v AddressType) IsValid() bool {
+	switch v {
+	case AddressTypePOBox,
+		AddressTypeStreet,
+		AddressTypeDelivery:
+		return true
+	}
+	return false
+}
+
+// Validate checks the address against the rules documented by Xero
+func (a *Address) Validate() error {
+	r := rules{}
+	r.enum("AddressType", a.AddressType, a.AddressType != "")
+	r.maxLength("AddressLine1", a.AddressLine1, 500)
+	r.maxLength("AddressLine2", a.AddressLine2, 500)
+	r.maxLength("AddressLine3", a.AddressLine3, 500)
+	r.maxLength("AddressLine4", a.AddressLine4, 500)
+	r.maxLength("City", a.City, 255)
+	r.maxLength("Region", a.Region, 255)
+	r.maxLength("PostalCode", a.PostalCode, 50)
+	r.maxLength("Country", a.Country, 50)
+	r.maxLength("AttentionTo", a.AttentionTo, 255)
+	return r.err()
+}
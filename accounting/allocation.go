@@ -1,13 +1,25 @@
 package accounting
 
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	allocationsPath = "Allocations"
+)
+
 //Allocation allocated an overpayment or Prepayment to an Invoice
 type Allocation struct {
 
 	// the amount being applied to the invoice
-	AppliedAmount float64 `json:"AppliedAmount,omitempty"`
+	AppliedAmount Decimal `json:"AppliedAmount,omitempty"`
 
 	// the date the prepayment is applied YYYY-MM-DD (read-only). This will be the latter of the invoice date and the prepayment date.
-	Date string `json:"Date,omitempty"`
+	Date *Date `json:"Date,omitempty"`
 
 	//The Invoice that the allocation will be made to
 	Invoice InvoiceID `json:"Invoice,omitempty"`
@@ -17,3 +29,42 @@ type Allocation struct {
 type Allocations struct {
 	Allocations []Allocation `json:"Allocations"`
 }
+
+// allocate sends the allocations to the Allocations endpoint of a credit
+func allocate(ctx context.Context, cl *http.Client, endpoint string, allocations []Allocation) (*Allocations, error) {
+	a := &Allocations{
+		Allocations: allocations,
+	}
+	if err := validate(ctx, a); err != nil {
+		return nil, err
+	}
+	buf, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	allocationResponseBytes, err := helpers.CreateContext(ctx, cl, endpoint, buf)
+	if err != nil {
+		return nil, err
+	}
+	var allocationResponse *Allocations
+	if err := json.Unmarshal(allocationResponseBytes, &allocationResponse); err != nil {
+		return nil, err
+	}
+	return allocationResponse, nil
+}
+
+// Validate checks the allocation against the rules documented by Xero
+func (a *Allocation) Validate() error {
+	r := rules{}
+	r.required("Invoice", a.Invoice.InvoiceID != "" || a.Invoice.InvoiceNumber != "")
+	r.required("AppliedAmount", !a.AppliedAmount.IsZero())
+	if a.AppliedAmount.Sign() < 0 {
+		r.add("AppliedAmount", "can't be negative")
+	}
+	return r.err()
+}
+
+// Validate checks all the allocations of the collection
+func (a *Allocations) Validate() error {
+	return validateAll("Allocations", len(a.Allocations), func(n int) validator { return &a.Allocations[n] })
+}
@@ -0,0 +1,96 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+)
+
+func TestAllocate(t *testing.T) {
+	const (
+		prepaymentID  = "c3d4e8a5-0b4d-4a3c-9e2f-5c0c8a6f9d71"
+		overpaymentID = "8f2b6c1e-4d7a-4c3b-b5e9-2a1d0f6e7c84"
+		invoiceID     = "6a539484-9a5f-41e3-a2c1-a6be5d4f8af0"
+	)
+	tests := []struct {
+		name string
+		path string
+		id   string
+	}{
+		{name: "prepayment", path: "Prepayments", id: prepaymentID},
+		{name: "overpayment", path: "Overpayments", id: overpaymentID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := xerotest.NewServer()
+			defer s.Close()
+			tenantID := s.TenantID()
+			cl := s.Client(tenantID)
+			ctx := WithValidation(helpers.WithEndpoints(context.Background(), s.Endpoints()))
+			contact := Contact{Name: "Acme"}
+			if err := s.Seed(tenantID, "Prepayments", Prepayment{PrepaymentID: prepaymentID, Contact: contact, Total: "500"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Seed(tenantID, "Overpayments", Overpayment{OverpaymentID: overpaymentID, Contact: contact, Total: "500"}); err != nil {
+				t.Fatal(err)
+			}
+
+			// the same calls through the prepayment or the overpayment
+			allocate := func(allocations ...Allocation) (*Allocations, error) {
+				if tt.path == "Prepayments" {
+					p := Prepayment{PrepaymentID: tt.id}
+					return p.AllocateContext(ctx, cl, allocations...)
+				}
+				o := Overpayment{OverpaymentID: tt.id}
+				return o.AllocateContext(ctx, cl, allocations...)
+			}
+			find := func() ([]Allocation, error) {
+				if tt.path == "Prepayments" {
+					p, err := FindPrepaymentContext(ctx, cl, uuid.Must(uuid.FromString(tt.id)))
+					if err != nil {
+						return nil, err
+					}
+					return p.Allocations, nil
+				}
+				o, err := FindOverpaymentContext(ctx, cl, uuid.Must(uuid.FromString(tt.id)))
+				if err != nil {
+					return nil, err
+				}
+				return o.Allocations, nil
+			}
+
+			allocated, err := allocate(
+				Allocation{Invoice: InvoiceID{InvoiceID: invoiceID}, AppliedAmount: "120.25"},
+				Allocation{Invoice: InvoiceID{InvoiceNumber: "INV-2"}, AppliedAmount: "30"},
+			)
+			if err != nil {
+				t.Fatalf("AllocateContext() error = %v", err)
+			}
+			if len(allocated.Allocations) != 2 {
+				t.Fatalf("AllocateContext() = %+v, want 2 allocations", allocated)
+			}
+			allocations, err := find()
+			if err != nil {
+				t.Fatalf("find error = %v", err)
+			}
+			if len(allocations) != 2 ||
+				allocations[0].Invoice.InvoiceID != invoiceID || !allocations[0].AppliedAmount.Equal("120.25") ||
+				allocations[1].Invoice.InvoiceNumber != "INV-2" || !allocations[1].AppliedAmount.Equal("30") {
+				t.Errorf("allocations = %+v, want the ones of both invoices", allocations)
+			}
+
+			// an invalid allocation isn't sent
+			_, err = allocate(Allocation{Invoice: InvoiceID{InvoiceID: invoiceID}, AppliedAmount: "-1"})
+			if !errors.Is(err, helpers.ErrValidation) {
+				t.Fatalf("AllocateContext() error = %v, want a validation error", err)
+			}
+			if allocations, err := find(); err != nil || len(allocations) != 2 {
+				t.Errorf("allocations = %+v %v, want the 2 first ones only", allocations, err)
+			}
+		})
+	}
+}
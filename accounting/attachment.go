@@ -0,0 +1,201 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	attachmentsPath        = "Attachments"
+	includeOnlineParameter = "IncludeOnline"
+	defaultMimeType        = "application/octet-stream"
+)
+
+// Attachment is a file attached to a document, e.g. the scan of a receipt
+// attached to a bank transaction. The documents having attachments show
+// HasAttachments, see https://developer.xero.com/documentation/api/attachments
+// for the supported ones
+type Attachment struct {
+
+	// Xero generated unique identifier for the attachment
+	AttachmentID string `json:"AttachmentID,omitempty"`
+
+	// The name of the file, unique for the document
+	FileName string `json:"FileName,omitempty"`
+
+	// The URL of the file in Xero
+	URL string `json:"Url,omitempty"`
+
+	// The MIME type of the file e.g. image/png or application/pdf
+	MimeType string `json:"MimeType,omitempty"`
+
+	// The size of the file in bytes
+	ContentLength int64 `json:"ContentLength,omitempty"`
+
+	// Include the file with the online invoice, only supported by the
+	// invoices and the credit notes
+	IncludeOnline bool `json:"IncludeOnline,omitempty"`
+}
+
+// Attachments is a collection of Attachments
+type Attachments struct {
+	Attachments []Attachment `json:"Attachments"`
+}
+
+// AttachmentContent is the content of a downloaded attachment, it must be
+// closed after reading it
+type AttachmentContent struct {
+	io.ReadCloser
+
+	// The MIME type of the file
+	MimeType string
+}
+
+func unmarshalAttachment(attachmentResponseBytes []byte) (*Attachments, error) {
+	var attachmentResponse *Attachments
+	err := json.Unmarshal(attachmentResponseBytes, &attachmentResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachmentResponse, err
+}
+
+// FindAttachments will get the attachments of the document with the given
+// docType and id, e.g. FindAttachments(cl, AttachmentDocTypeInvoices, invoiceID). The
+// content of the files is downloaded with FindAttachmentContent
+func FindAttachments(cl *http.Client, docType AttachmentDocType, id string) (*Attachments, error) {
+	return FindAttachmentsContext(context.Background(), cl, docType, id)
+}
+
+// FindAttachmentsContext is the same as FindAttachments but the request is bound to the given context
+func FindAttachmentsContext(ctx context.Context, cl *http.Client, docType AttachmentDocType, id string) (*Attachments, error) {
+	attachmentResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, string(docType), id, attachmentsPath), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalAttachment(attachmentResponseBytes)
+}
+
+// FindAttachmentContent will download the attachment with the given ID of the
+// document with the given docType and id. The content must be closed after
+// reading it
+func FindAttachmentContent(cl *http.Client, docType AttachmentDocType, id string, attachmentID uuid.UUID) (*AttachmentContent, error) {
+	return FindAttachmentContentContext(context.Background(), cl, docType, id, attachmentID)
+}
+
+// FindAttachmentContentContext is the same as FindAttachmentContent but the request is bound to the given context
+func FindAttachmentContentContext(ctx context.Context, cl *http.Client, docType AttachmentDocType, id string, attachmentID uuid.UUID) (*AttachmentContent, error) {
+	return findAttachmentContent(ctx, cl, helpers.AccountingURL(ctx, string(docType), id, attachmentsPath, attachmentID.String()))
+}
+
+// FindAttachmentContentByFileName will download the attachment with the given
+// file name of the document with the given docType and id. The content must
+// be closed after reading it
+func FindAttachmentContentByFileName(cl *http.Client, docType AttachmentDocType, id string, fileName string) (*AttachmentContent, error) {
+	return FindAttachmentContentByFileNameContext(context.Background(), cl, docType, id, fileName)
+}
+
+// FindAttachmentContentByFileNameContext is the same as FindAttachmentContentByFileName but the request is bound to the given context
+func FindAttachmentContentByFileNameContext(ctx context.Context, cl *http.Client, docType AttachmentDocType, id string, fileName string) (*AttachmentContent, error) {
+	return findAttachmentContent(ctx, cl, helpers.AccountingURL(ctx, string(docType), id, attachmentsPath, url.PathEscape(fileName)))
+}
+
+func findAttachmentContent(ctx context.Context, cl *http.Client, endpoint string) (*AttachmentContent, error) {
+	body, mimeType, err := helpers.FindRawContext(ctx, cl, endpoint, "")
+	if err != nil {
+		return nil, err
+	}
+	return &AttachmentContent{ReadCloser: body, MimeType: mimeType}, nil
+}
+
+// Create will upload the content of the attachment to the document with the
+// given docType and id, using its FileName and MimeType (application/octet-stream
+// when empty). The FileName is part of the URL, so it is required even when
+// the validation is not enabled. The content is streamed as it is, when it is
+// a *bytes.Reader, a *bytes.Buffer or a *strings.Reader the request can be
+// retried too
+func (a *Attachment) Create(cl *http.Client, docType AttachmentDocType, id string, content io.Reader) (*Attachments, error) {
+	return a.CreateContext(context.Background(), cl, docType, id, content)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (a *Attachment) CreateContext(ctx context.Context, cl *http.Client, docType AttachmentDocType, id string, content io.Reader) (*Attachments, error) {
+	if a.FileName == "" {
+		return nil, missing("FileName")
+	}
+	if err := validate(ctx, a); err != nil {
+		return nil, err
+	}
+	endpoint := helpers.AccountingURL(ctx, string(docType), id, attachmentsPath, url.PathEscape(a.FileName))
+	if a.IncludeOnline {
+		endpoint += "?" + includeOnlineParameter + "=" + strconv.FormatBool(a.IncludeOnline)
+	}
+	mimeType := a.MimeType
+	if mimeType == "" {
+		mimeType = defaultMimeType
+	}
+	attachmentResponseBytes, err := helpers.CreateRawContext(ctx, cl, endpoint, mimeType, content)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalAttachment(attachmentResponseBytes)
+}
+
+// Validate checks the attachment against the rules documented by Xero
+func (a *Attachment) Validate() error {
+	r := rules{}
+	r.required("FileName", a.FileName != "")
+	return r.err()
+}
+
+// AttachmentDocType is the type of the documents supporting attachments, it is
+// the path of their endpoint.
+//
+// Prepayments and overpayments show HasAttachments but Xero has no
+// Attachments endpoint for them: their files are the ones of the bank
+// transaction they were received or spent with, so they are listed and
+// uploaded with AttachmentDocTypeBankTransactions and the ID of that
+// BankTransaction, the one with the same PrepaymentID or OverpaymentID
+type AttachmentDocType string
+
+// The document types supporting attachments
+const (
+	AttachmentDocTypeInvoices          AttachmentDocType = "Invoices"
+	AttachmentDocTypeReceipts          AttachmentDocType = "Receipts"
+	AttachmentDocTypeCreditNotes       AttachmentDocType = "CreditNotes"
+	AttachmentDocTypeRepeatingInvoices AttachmentDocType = "RepeatingInvoices"
+	AttachmentDocTypeBankTransactions  AttachmentDocType = "BankTransactions"
+	AttachmentDocTypeBankTransfers     AttachmentDocType = "BankTransfers"
+	AttachmentDocTypeContacts          AttachmentDocType = "Contacts"
+	AttachmentDocTypeAccounts          AttachmentDocType = "Accounts"
+	AttachmentDocTypeManualJournals    AttachmentDocType = "ManualJournals"
+	AttachmentDocTypePurchaseOrders    AttachmentDocType = "PurchaseOrders"
+	AttachmentDocTypeQuotes            AttachmentDocType = "Quotes"
+)
+
+// IsValid reports whether the document type is one of the documented values
+func (v AttachmentDocType) IsValid() bool {
+	switch v {
+	case AttachmentDocTypeInvoices,
+		AttachmentDocTypeReceipts,
+		AttachmentDocTypeCreditNotes,
+		AttachmentDocTypeRepeatingInvoices,
+		AttachmentDocTypeBankTransactions,
+		AttachmentDocTypeBankTransfers,
+		AttachmentDocTypeContacts,
+		AttachmentDocTypeAccounts,
+		AttachmentDocTypeManualJournals,
+		AttachmentDocTypePurchaseOrders,
+		AttachmentDocTypeQuotes:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+)
+
+func TestAttachments(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	invoiceID := "6a539484-9a5f-41e3-a2c1-a6be5d4f8af0"
+	if err := s.Seed(tenantID, "Invoices", Invoice{InvoiceID: invoiceID, InvoiceNumber: "INV-1"}); err != nil {
+		t.Fatal(err)
+	}
+	cl := s.Client(tenantID)
+	ctx := helpers.WithEndpoints(context.Background(), s.Endpoints())
+
+	// every byte value, which isn't valid UTF-8 nor JSON
+	content := make([]byte, 512)
+	for n := range content {
+		content[n] = byte(n)
+	}
+	attachment := Attachment{FileName: "scan 1#.png", MimeType: "image/png", IncludeOnline: true}
+	uploaded, err := attachment.CreateContext(ctx, cl, AttachmentDocTypeInvoices, invoiceID, bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("CreateContext() error = %v", err)
+	}
+	if len(uploaded.Attachments) != 1 {
+		t.Fatalf("CreateContext() = %+v, want the uploaded attachment", uploaded)
+	}
+	got := uploaded.Attachments[0]
+	if got.AttachmentID == "" || got.FileName != attachment.FileName || got.MimeType != "image/png" || got.ContentLength != int64(len(content)) || !got.IncludeOnline {
+		t.Errorf("CreateContext() = %+v, want the attachment of %d bytes", got, len(content))
+	}
+
+	listed, err := FindAttachmentsContext(ctx, cl, AttachmentDocTypeInvoices, invoiceID)
+	if err != nil {
+		t.Fatalf("FindAttachmentsContext() error = %v", err)
+	}
+	if len(listed.Attachments) != 1 || listed.Attachments[0].AttachmentID != got.AttachmentID {
+		t.Errorf("FindAttachmentsContext() = %+v, want the uploaded attachment", listed)
+	}
+
+	download := map[string]func() (*AttachmentContent, error){
+		"by ID": func() (*AttachmentContent, error) {
+			return FindAttachmentContentContext(ctx, cl, AttachmentDocTypeInvoices, invoiceID, uuid.Must(uuid.FromString(got.AttachmentID)))
+		},
+		"by file name": func() (*AttachmentContent, error) {
+			return FindAttachmentContentByFileNameContext(ctx, cl, AttachmentDocTypeInvoices, invoiceID, attachment.FileName)
+		},
+	}
+	for name, find := range download {
+		t.Run(name, func(t *testing.T) {
+			downloaded, err := find()
+			if err != nil {
+				t.Fatalf("download error = %v", err)
+			}
+			defer downloaded.Close()
+			buf, err := ioutil.ReadAll(downloaded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf, content) || downloaded.MimeType != "image/png" {
+				t.Errorf("downloaded %d bytes of %s, want the %d bytes uploaded", len(buf), downloaded.MimeType, len(content))
+			}
+		})
+	}
+
+	// the FileName is part of the URL, so it is checked without WithValidation
+	sent := len(s.Requests())
+	_, err = (&Attachment{}).CreateContext(ctx, cl, AttachmentDocTypeInvoices, invoiceID, bytes.NewReader(content))
+	if !errors.Is(err, helpers.ErrValidation) {
+		t.Errorf("CreateContext() error = %v, want a validation error", err)
+	}
+	if got := len(s.Requests()); got != sent {
+		t.Errorf("%d requests sent, want none", got-sent)
+	}
+}
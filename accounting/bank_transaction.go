@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -10,14 +11,14 @@ import (
 )
 
 const (
-	bankTransactionURL = "https://api.xero.com/api.xro/2.0/BankTransactions"
+	bankTransactionPath = "BankTransactions"
 )
 
 //BankTransaction is a bank transaction
 type BankTransaction struct {
 
 	// See Bank Transaction Types
-	Type string `json:"Type"`
+	Type BankTransactionType `json:"Type"`
 
 	// See Contacts
 	Contact Contact `json:"Contact"`
@@ -29,7 +30,7 @@ type BankTransaction struct {
 	IsReconciled bool `json:"IsReconciled,omitempty"`
 
 	// Date of transaction – YYYY-MM-DD
-	Date string `json:"DateString,omitempty"`
+	Date *Date `json:"DateString,omitempty"`
 
 	// Reference for the transaction. Only supported for SPEND and RECEIVE transactions.
 	Reference string `json:"Reference,omitempty"`
@@ -38,25 +39,25 @@ type BankTransaction struct {
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
 
 	// Exchange rate to base currency when money is spent or received. e.g. 0.7500 Only used for bank transactions in non base currency. If this isn’t specified for non base currency accounts then either the user-defined rate (preference) or the XE.com day rate will be used. Setting currency is only supported on overpayments.
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate Decimal `json:"CurrencyRate,omitempty"`
 
 	// URL link to a source document – shown as “Go to App Name”
 	URL string `json:"Url,omitempty"`
 
 	// See Bank Transaction Status Codes
-	Status string `json:"Status,omitempty"`
+	Status BankTransactionStatus `json:"Status,omitempty"`
 
 	// Line amounts are exclusive of tax by default if you don’t specify this element. See Line Amount Types
-	LineAmountTypes string `json:"LineAmountTypes,omitempty"`
+	LineAmountTypes LineAmountType `json:"LineAmountTypes,omitempty"`
 
 	// Total of bank transaction excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal Decimal `json:"SubTotal,omitempty"`
 
 	// Total tax on bank transaction
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax Decimal `json:"TotalTax,omitempty"`
 
 	// Total of bank transaction tax inclusive
-	Total float64 `json:"Total,omitempty"`
+	Total Decimal `json:"Total,omitempty"`
 
 	// Xero generated unique identifier for bank transaction
 	BankTransactionID string `json:"BankTransactionID,omitempty"`
@@ -71,10 +72,20 @@ type BankTransaction struct {
 	OverpaymentID string `json:"OverpaymentID,omitempty"`
 
 	// Last modified date UTC format
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Boolean to indicate if a bank transaction has an attachment
 	HasAttachments bool `json:"HasAttachments,omitempty"`
+
+	// OK, WARNING or ERROR when the request was sent with summarizeErrors=false,
+	// see CreateAll
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the bank transaction returned with summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+
+	// Warnings of the bank transaction returned with summarizeErrors=false
+	Warnings []helpers.Warning `json:"Warnings,omitempty"`
 }
 
 //BankTransactions contains a collection of BankTransactions
@@ -82,20 +93,6 @@ type BankTransactions struct {
 	BankTransactions []BankTransaction `json:"BankTransactions"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (b *BankTransactions) convertDates() error {
-	var err error
-	for n := len(b.BankTransactions) - 1; n >= 0; n-- {
-		b.BankTransactions[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(b.BankTransactions[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func unmarshalBankTransaction(bankTransactionResponseBytes []byte) (*BankTransactions, error) {
 	var bankTransactionResponse *BankTransactions
 	err := json.Unmarshal(bankTransactionResponseBytes, &bankTransactionResponse)
@@ -103,19 +100,20 @@ func unmarshalBankTransaction(bankTransactionResponseBytes []byte) (*BankTransac
 		return nil, err
 	}
 
-	err = bankTransactionResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
-
 	return bankTransactionResponse, err
 }
 
 // FindBankTransactions will get all BankTransactions. These BankTransaction will not have details like line items by default.
 // If you need details then then add a 'page' querystringParameter and get 100 BankTransactions at a time
 // additional querystringParameters such as where, page, order can be added as a map
+// Use NewBankTransactionIterator or FindAllBankTransactions for walking all the pages
 func FindBankTransactions(cl *http.Client, queryParameters map[string]string) (*BankTransactions, error) {
-	bankTransactionsBytes, err := helpers.Find(cl, bankTransactionURL, nil, queryParameters)
+	return FindBankTransactionsContext(context.Background(), cl, queryParameters)
+}
+
+// FindBankTransactionsContext is the same as FindBankTransactions but the request is bound to the given context
+func FindBankTransactionsContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*BankTransactions, error) {
+	bankTransactionsBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, bankTransactionPath), nil, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -128,10 +126,14 @@ func FindBankTransactions(cl *http.Client, queryParameters map[string]string) (*
 // If you need details then then add a 'page' querystringParameter and get 100 BankTransactions at a time
 // additional querystringParameters such as where, page, order can be added as a map
 func FindBankTransactionsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransactions, error) {
-	additionalHeaders := map[string]string{}
-	additionalHeaders["If-Modified-Since"] = modifiedSince.Format(time.RFC3339)
+	return FindBankTransactionsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindBankTransactionsModifiedSinceContext is the same as FindBankTransactionsModifiedSince but the request is bound to the given context
+func FindBankTransactionsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransactions, error) {
+	additionalHeaders := helpers.ModifiedSinceHeaders(modifiedSince)
 
-	bankTransactionsBytes, err := helpers.Find(cl, bankTransactionURL, additionalHeaders, queryParameters)
+	bankTransactionsBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, bankTransactionPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +143,12 @@ func FindBankTransactionsModifiedSince(cl *http.Client, modifiedSince time.Time,
 
 //FindBankTransaction will get a single BankTransaction - BankTransactionID can be a GUID for an BankTransaction or an BankTransaction number
 func FindBankTransaction(cl *http.Client, bankTransactionID uuid.UUID) (*BankTransaction, error) {
-	bankTransactionBytes, err := helpers.Find(cl, bankTransactionURL+"/"+bankTransactionID.String(), nil, nil)
+	return FindBankTransactionContext(context.Background(), cl, bankTransactionID)
+}
+
+// FindBankTransactionContext is the same as FindBankTransaction but the request is bound to the given context
+func FindBankTransactionContext(ctx context.Context, cl *http.Client, bankTransactionID uuid.UUID) (*BankTransaction, error) {
+	bankTransactionBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, bankTransactionPath, bankTransactionID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -157,11 +164,19 @@ func FindBankTransaction(cl *http.Client, bankTransactionID uuid.UUID) (*BankTra
 
 // Create will create accounts given an Accounts struct
 func (b *BankTransactions) Create(cl *http.Client) (*BankTransactions, error) {
+	return b.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (b *BankTransactions) CreateContext(ctx context.Context, cl *http.Client) (*BankTransactions, error) {
+	if err := validate(ctx, b); err != nil {
+		return nil, err
+	}
 	buf, err := json.Marshal(b)
 	if err != nil {
 		return nil, err
 	}
-	bankTransactionBytes, err := helpers.Create(cl, bankTransactionURL, buf)
+	bankTransactionBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, bankTransactionPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -169,9 +184,84 @@ func (b *BankTransactions) Create(cl *http.Client) (*BankTransactions, error) {
 	return unmarshalBankTransaction(bankTransactionBytes)
 }
 
+// BankTransactionResult is the result of an element of BankTransactions.CreateAll or BankTransactions.UpdateAll,
+// either the bank transaction returned by Xero or the error that stopped it
+type BankTransactionResult struct {
+	BankTransaction BankTransaction
+	Err             error
+}
+
+// CreateAll will create the bank transactions in requests of BatchSize elements
+// with summarizeErrors=false, so the valid ones are created even when others
+// are rejected. The results are in the order of the bank transactions, the error is
+// the first one failing a whole request
+func (b *BankTransactions) CreateAll(cl *http.Client) ([]BankTransactionResult, error) {
+	return b.CreateAllContext(context.Background(), cl)
+}
+
+// CreateAllContext is the same as CreateAll but the requests are bound to the given context
+func (b *BankTransactions) CreateAllContext(ctx context.Context, cl *http.Client) ([]BankTransactionResult, error) {
+	return b.sendAll(ctx, cl, false)
+}
+
+// UpdateAll is the same as CreateAll but the bank transactions with an ID are
+// updated instead of created
+func (b *BankTransactions) UpdateAll(cl *http.Client) ([]BankTransactionResult, error) {
+	return b.UpdateAllContext(context.Background(), cl)
+}
+
+// UpdateAllContext is the same as UpdateAll but the requests are bound to the given context
+func (b *BankTransactions) UpdateAllContext(ctx context.Context, cl *http.Client) ([]BankTransactionResult, error) {
+	return b.sendAll(ctx, cl, true)
+}
+
+func (b *BankTransactions) sendAll(ctx context.Context, cl *http.Client, update bool) ([]BankTransactionResult, error) {
+	results := make([]BankTransactionResult, len(b.BankTransactions))
+	sender := batch{
+		n: len(b.BankTransactions),
+		element: func(n int) validator {
+			return &b.BankTransactions[n]
+		},
+		payload: func(indexes []int) interface{} {
+			chunk := BankTransactions{}
+			for _, n := range indexes {
+				chunk.BankTransactions = append(chunk.BankTransactions, b.BankTransactions[n])
+			}
+			return chunk
+		},
+		decode: func(buf []byte, indexes []int) error {
+			var saved BankTransactions
+			if err := json.Unmarshal(buf, &saved); err != nil {
+				return err
+			}
+			for k, n := range indexes {
+				if k >= len(saved.BankTransactions) {
+					results[n].Err = errMissingElement(n)
+					continue
+				}
+				results[n].BankTransaction = saved.BankTransactions[k]
+				results[n].Err = elementError(n, saved.BankTransactions[k].StatusAttributeString, saved.BankTransactions[k].ValidationErrors)
+			}
+			return nil
+		},
+		fail: func(n int, err error) {
+			results[n] = BankTransactionResult{BankTransaction: b.BankTransactions[n], Err: err}
+		},
+	}
+	return results, sender.send(ctx, cl, helpers.AccountingURL(ctx, bankTransactionPath), update)
+}
+
 // Update will update an account given an Accounts struct
 // This will only handle single account - you cannot update multiple accounts in a single call
 func (b *BankTransaction) Update(cl *http.Client) (*BankTransactions, error) {
+	return b.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (b *BankTransaction) UpdateContext(ctx context.Context, cl *http.Client) (*BankTransactions, error) {
+	if err := validate(ctx, b); err != nil {
+		return nil, err
+	}
 	bt := BankTransactions{
 		BankTransactions: []BankTransaction{*b},
 	}
@@ -179,10 +269,171 @@ func (b *BankTransaction) Update(cl *http.Client) (*BankTransactions, error) {
 	if err != nil {
 		return nil, err
 	}
-	bankTransactionBytes, err := helpers.Update(cl, bankTransactionURL+"/"+b.BankTransactionID, buf)
+	bankTransactionBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, bankTransactionPath, b.BankTransactionID), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalBankTransaction(bankTransactionBytes)
 }
+
+// Patch will update only the given fields of the bank transaction, zero values
+// included. Use the BankTransaction field names, e.g. Patch(cl, "Reference")
+// or Patch(cl, "Date")
+func (b *BankTransaction) Patch(cl *http.Client, fields ...string) (*BankTransactions, error) {
+	return b.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (b *BankTransaction) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (*BankTransactions, error) {
+	buf, err := patchBody("BankTransactions", "BankTransactionID", b, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, bankTransactionPath, b.BankTransactionID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalBankTransaction(buf)
+}
+
+// BankTransactionIterator walks all the pages of bank transactions, use Next to advance and BankTransaction to get
+// the current one
+type BankTransactionIterator struct {
+	pager
+	bankTransactions []BankTransaction
+	current          BankTransaction
+}
+
+// NewBankTransactionIterator will build an iterator over all the bank transactions matching the
+// given queryParameters (where, order...), when modifiedSince is not zero only
+// the bank transactions modified after it are returned
+func NewBankTransactionIterator(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) *BankTransactionIterator {
+	return &BankTransactionIterator{
+		pager: newPager(ctx, cl, helpers.AccountingURL(ctx, bankTransactionPath), modifiedSince, queryParameters),
+	}
+}
+
+// Next advances to the next bank transaction, fetching the next page when needed. It
+// returns false when there are no more bank transactions or an error happened, see Err
+func (it *BankTransactionIterator) Next() bool {
+	for len(it.bankTransactions) == 0 {
+		buf, ok := it.fetch()
+		if !ok {
+			return false
+		}
+		page, err := unmarshalBankTransaction(buf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.BankTransactions) == 0 {
+			return it.finish()
+		}
+		it.bankTransactions = page.BankTransactions
+	}
+	it.current = it.bankTransactions[0]
+	it.bankTransactions = it.bankTransactions[1:]
+	return true
+}
+
+// BankTransaction returns the current bank transaction
+func (it *BankTransactionIterator) BankTransaction() *BankTransaction {
+	return &it.current
+}
+
+// FindAllBankTransactions will get the bank transactions of all the pages, see NewBankTransactionIterator
+func FindAllBankTransactions(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransactions, error) {
+	return FindAllBankTransactionsContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindAllBankTransactionsContext is the same as FindAllBankTransactions but the requests are bound to the given context
+func FindAllBankTransactionsContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransactions, error) {
+	all := &BankTransactions{BankTransactions: []BankTransaction{}}
+	it := NewBankTransactionIterator(ctx, cl, modifiedSince, queryParameters)
+	for it.Next() {
+		all.BankTransactions = append(all.BankTransactions, *it.BankTransaction())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// BankTransactionType is the type of a bank transaction, also used by the prepayments and overpayments
+type BankTransactionType string
+
+// The bank transaction types
+const (
+	BankTransactionTypeReceive            BankTransactionType = "RECEIVE"
+	BankTransactionTypeReceiveOverpayment BankTransactionType = "RECEIVE-OVERPAYMENT"
+	BankTransactionTypeReceivePrepayment  BankTransactionType = "RECEIVE-PREPAYMENT"
+	BankTransactionTypeSpend              BankTransactionType = "SPEND"
+	BankTransactionTypeSpendOverpayment   BankTransactionType = "SPEND-OVERPAYMENT"
+	BankTransactionTypeSpendPrepayment    BankTransactionType = "SPEND-PREPAYMENT"
+	BankTransactionTypeReceiveTransfer    BankTransactionType = "RECEIVE-TRANSFER"
+	BankTransactionTypeSpendTransfer      BankTransactionType = "SPEND-TRANSFER"
+)
+
+// IsValid reports whether the bank transaction type is one of the documented values
+func (v BankTransactionType) IsValid() bool {
+	switch v {
+	case BankTransactionTypeReceive,
+		BankTransactionTypeReceiveOverpayment,
+		BankTransactionTypeReceivePrepayment,
+		BankTransactionTypeSpend,
+		BankTransactionTypeSpendOverpayment,
+		BankTransactionTypeSpendPrepayment,
+		BankTransactionTypeReceiveTransfer,
+		BankTransactionTypeSpendTransfer:
+		return true
+	}
+	return false
+}
+
+// BankTransactionStatus is the status of a bank transaction
+type BankTransactionStatus string
+
+// The bank transaction statuses
+const (
+	BankTransactionStatusAuthorised BankTransactionStatus = "AUTHORISED"
+	BankTransactionStatusDeleted    BankTransactionStatus = "DELETED"
+	BankTransactionStatusVoided     BankTransactionStatus = "VOIDED"
+)
+
+// IsValid reports whether the status is one of the documented values
+func (v BankTransactionStatus) IsValid() bool {
+	switch v {
+	case BankTransactionStatusAuthorised,
+		BankTransactionStatusDeleted,
+		BankTransactionStatusVoided:
+		return true
+	}
+	return false
+}
+
+// Validate checks the bank transaction against the rules documented by Xero,
+// the required elements are only checked when creating it
+func (b *BankTransaction) Validate() error {
+	r := rules{}
+	if b.BankTransactionID == "" {
+		r.required("Type", b.Type != "")
+		r.required("Contact", b.Contact.isReference())
+		r.required("LineItems", len(b.LineItems) > 0)
+		r.required("BankAccount", b.BankAccount.Code != "" || b.BankAccount.AccountID != "")
+	}
+	r.enum("Type", b.Type, b.Type != "")
+	r.enum("Status", b.Status, b.Status != "")
+	r.enum("LineAmountTypes", b.LineAmountTypes, b.LineAmountTypes != "")
+	r.maxLength("Reference", b.Reference, 255)
+	if b.Reference != "" && b.Type != "" && b.Type != BankTransactionTypeSpend && b.Type != BankTransactionTypeReceive {
+		r.add("Reference", "is only supported for SPEND and RECEIVE transactions")
+	}
+	r.lineItems(b.LineItems, false)
+	return r.err()
+}
+
+// Validate checks every bank transaction of the collection
+func (b *BankTransactions) Validate() error {
+	return validateAll("BankTransactions", len(b.BankTransactions), func(n int) validator { return &b.BankTransactions[n] })
+}
@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -10,23 +11,23 @@ import (
 )
 
 const (
-	bankTransferURL = "https://api.xero.com/api.xro/2.0/BankTransfers"
+	bankTransferPath = "BankTransfers"
 )
 
 //BankTransfer is a record of monies transferred from one bank account to another
 type BankTransfer struct {
 
 	//
-	Amount float64 `json:"Amount"`
+	Amount Decimal `json:"Amount"`
 
 	// The date of the Transfer YYYY-MM-DD
-	Date string `json:"Date,omitempty"`
+	Date *Date `json:"Date,omitempty"`
 
 	// The identifier of the Bank Transfer
 	BankTransferID string `json:"BankTransferID,omitempty"`
 
 	// The currency rate
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate Decimal `json:"CurrencyRate,omitempty"`
 
 	// The Bank Transaction ID for the source account
 	FromBankTransactionID string `json:"FromBankTransactionID,omitempty"`
@@ -38,7 +39,7 @@ type BankTransfer struct {
 	HasAttachments bool `json:"HasAttachments,omitempty"`
 
 	// UTC timestamp of creation date of bank transfer
-	CreatedDateUTC string `json:"CreatedDateUTC,omitempty"`
+	CreatedDateUTC *DateTime `json:"CreatedDateUTC,omitempty"`
 
 	// The source BankAccount
 	FromBankAccount BankAccount `json:"FromBankAccount,omitempty"`
@@ -52,24 +53,6 @@ type BankTransfers struct {
 	BankTransfers []BankTransfer `json:"BankTransfers"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (b *BankTransfers) convertDates() error {
-	var err error
-	for n := len(b.BankTransfers) - 1; n >= 0; n-- {
-		b.BankTransfers[n].Date, err = helpers.DotNetJSONTimeToRFC3339(b.BankTransfers[n].Date, false)
-		if err != nil {
-			return err
-		}
-		b.BankTransfers[n].CreatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(b.BankTransfers[n].CreatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func unmarshalBankTransfer(bankTransferResponseBytes []byte) (*BankTransfers, error) {
 	var bankTransferResponse *BankTransfers
 	err := json.Unmarshal(bankTransferResponseBytes, &bankTransferResponse)
@@ -77,11 +60,6 @@ func unmarshalBankTransfer(bankTransferResponseBytes []byte) (*BankTransfers, er
 		return nil, err
 	}
 
-	err = bankTransferResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
-
 	return bankTransferResponse, err
 }
 
@@ -90,10 +68,14 @@ func unmarshalBankTransfer(bankTransferResponseBytes []byte) (*BankTransfers, er
 // If you need details then add a 'page' querystringParameter and get 100 BankTransfers at a time
 // additional querystringParameters such as where and order can be added as a map
 func FindBankTransfersModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransfers, error) {
-	additionalHeaders := map[string]string{}
-	additionalHeaders["If-Modified-Since"] = modifiedSince.Format(time.RFC3339)
+	return FindBankTransfersModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
 
-	bankTransferBytes, err := helpers.Find(cl, bankTransferURL, additionalHeaders, queryParameters)
+// FindBankTransfersModifiedSinceContext is the same as FindBankTransfersModifiedSince but the request is bound to the given context
+func FindBankTransfersModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BankTransfers, error) {
+	additionalHeaders := helpers.ModifiedSinceHeaders(modifiedSince)
+
+	bankTransferBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, bankTransferPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -105,7 +87,12 @@ func FindBankTransfersModifiedSince(cl *http.Client, modifiedSince time.Time, qu
 // If you need details then add a 'page' querystringParameter and get 100 BankTransfers at a time
 // additional querystringParameters such as where and order can be added as a map
 func FindBankTransfers(cl *http.Client, queryParameters map[string]string) (*BankTransfers, error) {
-	bankTransferBytes, err := helpers.Find(cl, bankTransferURL, nil, queryParameters)
+	return FindBankTransfersContext(context.Background(), cl, queryParameters)
+}
+
+// FindBankTransfersContext is the same as FindBankTransfers but the request is bound to the given context
+func FindBankTransfersContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*BankTransfers, error) {
+	bankTransferBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, bankTransferPath), nil, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +102,12 @@ func FindBankTransfers(cl *http.Client, queryParameters map[string]string) (*Ban
 
 // FindBankTransfer will get a single bankTransfer - bankTransferID can be a GUID for an bankTransfer or an bankTransfer number
 func FindBankTransfer(cl *http.Client, bankTransferID uuid.UUID) (*BankTransfer, error) {
-	bankTransferBytes, err := helpers.Find(cl, bankTransferURL+"/"+bankTransferID.String(), nil, nil)
+	return FindBankTransferContext(context.Background(), cl, bankTransferID)
+}
+
+// FindBankTransferContext is the same as FindBankTransfer but the request is bound to the given context
+func FindBankTransferContext(ctx context.Context, cl *http.Client, bankTransferID uuid.UUID) (*BankTransfer, error) {
+	bankTransferBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, bankTransferPath, bankTransferID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -131,14 +123,43 @@ func FindBankTransfer(cl *http.Client, bankTransferID uuid.UUID) (*BankTransfer,
 
 // Create will create bankTransfers given a BankTransfers struct
 func (b *BankTransfers) Create(cl *http.Client) (*BankTransfers, error) {
+	return b.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (b *BankTransfers) CreateContext(ctx context.Context, cl *http.Client) (*BankTransfers, error) {
+	if err := validate(ctx, b); err != nil {
+		return nil, err
+	}
 	buf, err := json.Marshal(b)
 	if err != nil {
 		return nil, err
 	}
-	bankTransferBytes, err := helpers.Create(cl, bankTransferURL, buf)
+	bankTransferBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, bankTransferPath), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalBankTransfer(bankTransferBytes)
 }
+
+// Validate checks the bank transfer against the rules documented by Xero
+func (b *BankTransfer) Validate() error {
+	r := rules{}
+	if b.Amount.Sign() <= 0 {
+		r.add("Amount", "must be greater than 0")
+	}
+	from := b.FromBankAccount.Code != "" || b.FromBankAccount.AccountID != ""
+	to := b.ToBankAccount.Code != "" || b.ToBankAccount.AccountID != ""
+	r.required("FromBankAccount", from)
+	r.required("ToBankAccount", to)
+	if from && to && b.FromBankAccount == b.ToBankAccount {
+		r.add("ToBankAccount", "must be different from the FromBankAccount")
+	}
+	return r.err()
+}
+
+// Validate checks every bank transfer of the collection
+func (b *BankTransfers) Validate() error {
+	return validateAll("BankTransfers", len(b.BankTransfers), func(n int) validator { return &b.BankTransfers[n] })
+}
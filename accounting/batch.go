@@ -0,0 +1,161 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	// BatchSize is the number of elements sent in each request by CreateAll and
+	// UpdateAll, as recommended by Xero
+	BatchSize = 50
+
+	summarizeErrorsParameter = "summarizeErrors"
+	statusAttributeError     = "ERROR"
+)
+
+// ElementError is an element of a CreateAll or UpdateAll rejected by Xero, it
+// matches helpers.ErrValidation with errors.Is
+type ElementError struct {
+	// Index of the element in the collection
+	Index            int
+	ValidationErrors []helpers.ValidationError
+}
+
+// Error method will return the validation messages of the element
+func (e *ElementError) Error() string {
+	messages := make([]string, len(e.ValidationErrors))
+	for n, v := range e.ValidationErrors {
+		messages[n] = v.Message
+	}
+	return fmt.Sprintf("xero: element %d rejected: %s", e.Index, strings.Join(messages, "; "))
+}
+
+// Is method will make errors.Is(err, helpers.ErrValidation) true
+func (e *ElementError) Is(target error) bool {
+	return target == helpers.ErrValidation
+}
+
+// elementError returns the ElementError of an element returned with
+// summarizeErrors=false, or nil when it was saved
+func elementError(index int, status string, validationErrors []helpers.ValidationError) error {
+	if status != statusAttributeError && len(validationErrors) == 0 {
+		return nil
+	}
+	return &ElementError{Index: index, ValidationErrors: validationErrors}
+}
+
+// batch sends the elements of a collection in chunks of BatchSize with
+// summarizeErrors=false, so each element gets its own result
+type batch struct {
+	// n is the number of elements of the collection
+	n int
+	// element returns the element at the given index for validating it
+	element func(int) validator
+	// payload builds the request body with the elements at the given indexes
+	payload func(indexes []int) interface{}
+	// decode reads the elements returned for the given indexes and sets their
+	// results
+	decode func(buf []byte, indexes []int) error
+	// fail sets the error as the result of the element at the given index
+	fail func(int, error)
+}
+
+// send creates the elements with PUT, or creates or updates them with POST
+// when update is true. The elements failing the validation of the context
+// are not sent, the others are sent with the ones of the same BatchSize range
+// of the collection. It returns the first error of a whole request, e.g. a
+// network error, after setting it as the result of its elements
+func (b batch) send(ctx context.Context, cl *http.Client, endpoint string, update bool) error {
+	endpoint += "?" + summarizeErrorsParameter + "=false"
+
+	var first error
+	for start := 0; start < b.n; start += BatchSize {
+		end := start + BatchSize
+		if end > b.n {
+			end = b.n
+		}
+		indexes := make([]int, 0, end-start)
+		for i := start; i < end; i++ {
+			if err := validate(ctx, b.element(i)); err != nil {
+				b.fail(i, err)
+				continue
+			}
+			indexes = append(indexes, i)
+		}
+		if len(indexes) == 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			b.failAll(indexes, err)
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		err := b.sendChunk(chunkContext(ctx, start/BatchSize, end-start, indexes), cl, endpoint, update, indexes)
+		if err != nil {
+			b.failAll(indexes, err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+func (b batch) sendChunk(ctx context.Context, cl *http.Client, endpoint string, update bool, indexes []int) error {
+	buf, err := json.Marshal(b.payload(indexes))
+	if err != nil {
+		return err
+	}
+	if update {
+		buf, err = helpers.UpdateContext(ctx, cl, endpoint, buf)
+	} else {
+		buf, err = helpers.CreateContext(ctx, cl, endpoint, buf)
+	}
+	if err != nil {
+		return err
+	}
+	return b.decode(buf, indexes)
+}
+
+// chunkContext derives the Idempotency-Key of each request from the one of
+// the context, so the chunks aren't taken for retries of the first one. The
+// key is the one of the BatchSize range of the collection, so the elements
+// rejected by the validation don't move the others to a key already used for
+// different ones, and it also gets a hash of the indexes sent when some of
+// the range were left out, so fixing them doesn't replay the response of a
+// different body
+func chunkContext(ctx context.Context, chunk int, size int, indexes []int) context.Context {
+	key := helpers.IdempotencyKeyFromContext(ctx)
+	if key == "" {
+		return ctx
+	}
+	key = fmt.Sprintf("%s-%d", key, chunk)
+	if len(indexes) < size {
+		h := fnv.New32a()
+		for _, i := range indexes {
+			fmt.Fprintf(h, "%d,", i)
+		}
+		key = fmt.Sprintf("%s-%08x", key, h.Sum32())
+	}
+	return helpers.WithIdempotencyKey(ctx, key)
+}
+
+func (b batch) failAll(indexes []int, err error) {
+	for _, i := range indexes {
+		b.fail(i, err)
+	}
+}
+
+// errMissingElement is the result of an element not returned by Xero
+func errMissingElement(index int) error {
+	return fmt.Errorf("xero: element %d missing in the response", index)
+}
@@ -1,51 +1,269 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
-	batchPaymentURL = "https://api.xero.com/api.xro/2.0/BatchPayments"
+	batchPaymentPath = "BatchPayments"
 )
 
-// BatchPayment type will keep information related with a bank
+// BatchPayment pays or receives several invoices in a single bank transaction
 type BatchPayment struct {
 
-	// A user defined bank account number.
+	// Bank account the payments are paid from or received to
+	Account *Account `json:"Account,omitempty"`
+
+	// Date the payments are made (YYYY-MM-DD)
+	Date *Date `json:"Date,omitempty"`
+
+	// Payments of the batch, each one with an Invoice and an Amount
+	Payments []Payment `json:"Payments,omitempty"`
+
+	// See Batch Payment Types
+	Type BatchPaymentType `json:"Type,omitempty"`
+
+	// See Batch Payment Status Codes
+	Status BatchPaymentStatus `json:"Status,omitempty"`
+
+	// A user defined bank account number, the bank details of a contact
 	BankAccountNumber string `json:"BankAccountNumber,omitempty"`
 
-	// Full name of bank account
+	// Full name of bank account, the bank details of a contact
 	BankAccountName string `json:"BankAccountName,omitempty"`
 
-	// Details of the Batch payment
-	Details string `json:"Details,omitempty"`
+	// (NZ Only) Optional references for the batch payment transaction (max length = 12)
+	Reference string `json:"Reference,omitempty"`
+
+	// (NZ Only) Optional references for the batch payment transaction (max length = 12)
+	Particulars string `json:"Particulars,omitempty"`
 
-	// Code of the Batch payment
+	// (NZ Only) Optional references for the batch payment transaction (max length = 12)
 	Code string `json:"Code,omitempty"`
 
-	// Reference of the Batch payment
-	Reference string `json:"Reference,omitempty"`
+	// (Non-NZ Only) The information shown on the bank statement of the transaction (max length = 18)
+	Details string `json:"Details,omitempty"`
+
+	// (UK Only) Only shows on the statement line in Xero (max length = 18)
+	Narrative string `json:"Narrative,omitempty"`
+
+	// The total of the payments of the batch
+	TotalAmount Decimal `json:"TotalAmount,omitempty"`
+
+	// Boolean that tells if the batch payment has been reconciled (read only)
+	IsReconciled bool `json:"IsReconciled,omitempty"`
+
+	// UTC timestamp of last update to the batch payment
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
+
+	// The Xero generated unique identifier for the batch payment
+	BatchPaymentID string `json:"BatchPaymentID,omitempty"`
 }
 
-func unmarshalBatchPayment(batchPaymentResponseBytes []byte) ([]BatchPayment, error) {
-	response := struct {
-		Payments []BatchPayment `json:"BatchPayments,omitempty"`
-	}{}
-	err := json.Unmarshal(batchPaymentResponseBytes, &response)
+// BatchPayments is a collection of BatchPayments
+type BatchPayments struct {
+	BatchPayments []BatchPayment `json:"BatchPayments"`
+}
+
+// batchPaymentFields are the fields of a batch payment without its methods, so
+// they can be embedded in its payload
+type batchPaymentFields BatchPayment
+
+// batchPaymentPayload is how a batch payment is sent when it is created, with
+// its payments sent as references like in Payments.Create
+type batchPaymentPayload struct {
+	batchPaymentFields
+	Payments []paymentPayload `json:"Payments,omitempty"`
+}
+
+// batchPaymentsPayload is how a collection of batch payments is sent when it is
+// created
+type batchPaymentsPayload struct {
+	BatchPayments []batchPaymentPayload `json:"BatchPayments"`
+}
+
+func newBatchPaymentsPayload(batchPayments []BatchPayment) batchPaymentsPayload {
+	out := batchPaymentsPayload{}
+	for _, b := range batchPayments {
+		out.BatchPayments = append(out.BatchPayments, batchPaymentPayload{
+			batchPaymentFields: batchPaymentFields(b),
+			Payments:           newPaymentsPayload(b.Payments).Payments,
+		})
+	}
+	return out
+}
+
+func unmarshalBatchPayment(batchPaymentResponseBytes []byte) (*BatchPayments, error) {
+	var batchPaymentResponse *BatchPayments
+	err := json.Unmarshal(batchPaymentResponseBytes, &batchPaymentResponse)
 	if err != nil {
 		return nil, err
 	}
-	return response.Payments, nil
+	return batchPaymentResponse, nil
 }
 
 // FindBatchPayments will get all the batch payments
 func FindBatchPayments(cl *http.Client) ([]BatchPayment, error) {
-	batchPayments, err := helpers.Find(cl, batchPaymentURL, nil, nil)
+	return FindBatchPaymentsContext(context.Background(), cl)
+}
+
+// FindBatchPaymentsContext is the same as FindBatchPayments but the request is bound to the given context
+func FindBatchPaymentsContext(ctx context.Context, cl *http.Client) ([]BatchPayment, error) {
+	batchPayments, err := FindBatchPaymentsModifiedSinceContext(ctx, cl, time.Time{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return batchPayments.BatchPayments, nil
+}
+
+// FindBatchPaymentsModifiedSince will get the batch payments modified after the given date, a zero
+// modifiedSince will not filter by date. Additional querystringParameters such as where and order
+// can be added as a map
+func FindBatchPaymentsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BatchPayments, error) {
+	return FindBatchPaymentsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindBatchPaymentsModifiedSinceContext is the same as FindBatchPaymentsModifiedSince but the request is bound to the given context
+func FindBatchPaymentsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*BatchPayments, error) {
+	batchPayments, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, batchPaymentPath), helpers.ModifiedSinceHeaders(modifiedSince), queryParameters)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalBatchPayment(batchPayments)
 }
+
+// FindBatchPayment will get a single batch payment - batchPaymentID must be a GUID for a batch payment
+func FindBatchPayment(cl *http.Client, batchPaymentID uuid.UUID) (*BatchPayment, error) {
+	return FindBatchPaymentContext(context.Background(), cl, batchPaymentID)
+}
+
+// FindBatchPaymentContext is the same as FindBatchPayment but the request is bound to the given context
+func FindBatchPaymentContext(ctx context.Context, cl *http.Client, batchPaymentID uuid.UUID) (*BatchPayment, error) {
+	batchPayments, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, batchPaymentPath, batchPaymentID.String()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	b, err := unmarshalBatchPayment(batchPayments)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.BatchPayments) > 0 {
+		return &b.BatchPayments[0], nil
+	}
+	return nil, nil
+}
+
+// Create will create the given batch payments, each one paying all its
+// invoices in a single bank transaction
+func (b *BatchPayments) Create(cl *http.Client) (*BatchPayments, error) {
+	return b.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (b *BatchPayments) CreateContext(ctx context.Context, cl *http.Client) (*BatchPayments, error) {
+	if err := validate(ctx, b); err != nil {
+		return nil, err
+	}
+	buf, err := json.Marshal(newBatchPaymentsPayload(b.BatchPayments))
+	if err != nil {
+		return nil, err
+	}
+	batchPayments, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, batchPaymentPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalBatchPayment(batchPayments)
+}
+
+// RemoveBatchPayment will delete the batch payment with the given ID, Xero
+// reverses it and its payments by setting its status to DELETED
+func RemoveBatchPayment(cl *http.Client, batchPaymentID uuid.UUID) (*BatchPayments, error) {
+	return RemoveBatchPaymentContext(context.Background(), cl, batchPaymentID)
+}
+
+// RemoveBatchPaymentContext is the same as RemoveBatchPayment but the request is bound to the given context
+func RemoveBatchPaymentContext(ctx context.Context, cl *http.Client, batchPaymentID uuid.UUID) (*BatchPayments, error) {
+	buf, err := json.Marshal(map[string]BatchPaymentStatus{"Status": BatchPaymentStatusDeleted})
+	if err != nil {
+		return nil, err
+	}
+	batchPayments, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, batchPaymentPath, batchPaymentID.String()), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalBatchPayment(batchPayments)
+}
+
+// BatchPaymentType is the type of a batch payment, PAYBATCH for the bills and
+// RECBATCH for the sales invoices
+type BatchPaymentType string
+
+// The batch payment types
+const (
+	BatchPaymentTypePayBatch BatchPaymentType = "PAYBATCH"
+	BatchPaymentTypeRecBatch BatchPaymentType = "RECBATCH"
+)
+
+// IsValid reports whether the type is one of the documented values
+func (v BatchPaymentType) IsValid() bool {
+	switch v {
+	case BatchPaymentTypePayBatch,
+		BatchPaymentTypeRecBatch:
+		return true
+	}
+	return false
+}
+
+// BatchPaymentStatus is the status of a batch payment
+type BatchPaymentStatus string
+
+// The batch payment statuses
+const (
+	BatchPaymentStatusAuthorised BatchPaymentStatus = "AUTHORISED"
+	BatchPaymentStatusDeleted    BatchPaymentStatus = "DELETED"
+)
+
+// IsValid reports whether the status is one of the documented values
+func (v BatchPaymentStatus) IsValid() bool {
+	switch v {
+	case BatchPaymentStatusAuthorised,
+		BatchPaymentStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// Validate checks the batch payment against the rules documented by Xero
+func (b *BatchPayment) Validate() error {
+	r := rules{}
+	if b.BatchPaymentID == "" {
+		r.required("Account", b.Account != nil && (b.Account.AccountID != "" || b.Account.Code != ""))
+		r.required("Date", b.Date != nil && !b.Date.IsZero())
+		r.required("Payments", len(b.Payments) > 0)
+	}
+	r.enum("Type", b.Type, b.Type != "")
+	r.enum("Status", b.Status, b.Status != "")
+	r.maxLength("Reference", b.Reference, 12)
+	r.maxLength("Particulars", b.Particulars, 12)
+	r.maxLength("Code", b.Code, 12)
+	r.maxLength("Details", b.Details, 18)
+	r.maxLength("Narrative", b.Narrative, 18)
+	for n, p := range b.Payments {
+		field := fmt.Sprintf("Payments[%d]", n)
+		r.required(field+".Invoice", p.Invoice != nil && (p.Invoice.InvoiceID != "" || p.Invoice.InvoiceNumber != ""))
+		r.required(field+".Amount", !p.Amount.IsZero())
+	}
+	return r.err()
+}
+
+// Validate checks all the batch payments of the collection
+func (b *BatchPayments) Validate() error {
+	return validateAll("BatchPayments", len(b.BatchPayments), func(n int) validator { return &b.BatchPayments[n] })
+}
@@ -0,0 +1,88 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+)
+
+func TestBatchPayments(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	cl := s.Client(tenantID)
+	ctx := WithValidation(helpers.WithEndpoints(context.Background(), s.Endpoints()))
+
+	batch := BatchPayments{BatchPayments: []BatchPayment{{
+		Account:   &Account{Code: "090", Name: "Business Bank Account"},
+		Date:      NewDate(2020, time.March, 2),
+		Reference: "March run",
+		Payments: []Payment{
+			{Invoice: &Invoice{InvoiceID: "6a539484-9a5f-41e3-a2c1-a6be5d4f8af0", Reference: "Order 1"}, Amount: "100"},
+			{Invoice: &Invoice{InvoiceNumber: "INV-2"}, Amount: "50.25", Reference: "Order 2"},
+		},
+	}}}
+	created, err := batch.CreateContext(ctx, cl)
+	if err != nil {
+		t.Fatalf("CreateContext() error = %v", err)
+	}
+	if len(created.BatchPayments) != 1 || created.BatchPayments[0].BatchPaymentID == "" {
+		t.Fatalf("CreateContext() = %+v, want the created batch payment", created)
+	}
+	batchPaymentID := uuid.Must(uuid.FromString(created.BatchPayments[0].BatchPaymentID))
+
+	// the invoices of the payments are sent as references
+	var saved struct {
+		Payments []struct {
+			Invoice   map[string]interface{}
+			Amount    Decimal
+			Reference string
+		}
+	}
+	if err := json.Unmarshal(s.Records(tenantID, "BatchPayments")[0], &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Payments) != 2 ||
+		len(saved.Payments[0].Invoice) != 1 || !saved.Payments[0].Amount.Equal("100") ||
+		saved.Payments[1].Invoice["InvoiceNumber"] != "INV-2" || saved.Payments[1].Reference != "Order 2" {
+		t.Errorf("saved Payments = %+v, want the invoice references and the amounts", saved.Payments)
+	}
+
+	found, err := FindBatchPaymentContext(ctx, cl, batchPaymentID)
+	if err != nil {
+		t.Fatalf("FindBatchPaymentContext() error = %v", err)
+	}
+	if found.Reference != "March run" || found.Date == nil || found.Date.String() != "2020-03-02" || len(found.Payments) != 2 {
+		t.Errorf("FindBatchPaymentContext() = %+v, want the created batch payment", found)
+	}
+	all, err := FindBatchPaymentsContext(ctx, cl)
+	if err != nil || len(all) != 1 {
+		t.Errorf("FindBatchPaymentsContext() = %d batch payments %v, want 1", len(all), err)
+	}
+
+	removed, err := RemoveBatchPaymentContext(ctx, cl, batchPaymentID)
+	if err != nil {
+		t.Fatalf("RemoveBatchPaymentContext() error = %v", err)
+	}
+	if len(removed.BatchPayments) != 1 || removed.BatchPayments[0].Status != BatchPaymentStatusDeleted {
+		t.Errorf("RemoveBatchPaymentContext() = %+v, want the batch payment DELETED", removed)
+	}
+
+	invalid := BatchPayments{BatchPayments: []BatchPayment{{
+		Account:   &Account{Code: "090"},
+		Date:      NewDate(2020, time.March, 2),
+		Reference: "Reference too long",
+		Payments:  []Payment{{Amount: "10"}},
+	}}}
+	_, err = invalid.CreateContext(ctx, cl)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Field != "BatchPayments[0].Reference" || errs[1].Field != "BatchPayments[0].Payments[0].Invoice" {
+		t.Errorf("CreateContext() error = %v, want the Reference and the Invoice of the payment", err)
+	}
+}
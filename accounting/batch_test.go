@@ -0,0 +1,266 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/auth"
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+	"golang.org/x/oauth2"
+)
+
+func TestChunkContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		chunk   int
+		indexes []int
+		want    string
+	}{
+		{name: "no key", chunk: 0, indexes: []int{0, 1, 2}, want: ""},
+		{name: "no key later chunk", chunk: 3, indexes: []int{150}, want: ""},
+		{name: "first chunk", key: "import-42", chunk: 0, indexes: []int{0, 1, 2}, want: "import-42-0"},
+		{name: "later chunk", key: "import-42", chunk: 7, indexes: []int{350, 351, 352}, want: "import-42-7"},
+		{name: "elements left out", key: "import-42", chunk: 0, indexes: []int{0, 2}, want: "import-42-0-dbc93dc7"},
+		{name: "other elements left out", key: "import-42", chunk: 0, indexes: []int{1, 2}, want: "import-42-0-bde85f1e"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = helpers.WithIdempotencyKey(ctx, tt.key)
+			}
+			if got := helpers.IdempotencyKeyFromContext(chunkContext(ctx, tt.chunk, 3, tt.indexes)); got != tt.want {
+				t.Errorf("chunkContext() key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// sentChunk is a request of a batch seen by chunkRecorder
+type sentChunk struct {
+	method   string
+	query    string
+	key      string
+	elements int
+}
+
+// chunkRecorder is a http.RoundTripper keeping the batches sent to the Server
+type chunkRecorder struct {
+	T      http.RoundTripper
+	mu     sync.Mutex
+	chunks []sentChunk
+}
+
+func (c *chunkRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		var contacts Contacts
+		if err := json.Unmarshal(body, &contacts); err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.chunks = append(c.chunks, sentChunk{
+			method:   req.Method,
+			query:    req.URL.RawQuery,
+			key:      req.Header.Get("Idempotency-Key"),
+			elements: len(contacts.Contacts),
+		})
+		c.mu.Unlock()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	return c.T.RoundTrip(req)
+}
+
+func TestCreateAllChunks(t *testing.T) {
+	tests := []struct {
+		name       string
+		contacts   int
+		invalid    []int
+		key        string
+		failure    *xerotest.Failure
+		wantChunks []sentChunk
+		wantFailed []int
+		wantErr    bool
+	}{
+		{
+			name:     "single chunk",
+			contacts: 3,
+			wantChunks: []sentChunk{
+				{method: http.MethodPut, query: "summarizeErrors=false", elements: 3},
+			},
+		},
+		{
+			name:     "chunks of BatchSize",
+			contacts: 2*BatchSize + 20,
+			key:      "import-1",
+			wantChunks: []sentChunk{
+				{method: http.MethodPut, query: "summarizeErrors=false", key: "import-1-0", elements: BatchSize},
+				{method: http.MethodPut, query: "summarizeErrors=false", key: "import-1-1", elements: BatchSize},
+				{method: http.MethodPut, query: "summarizeErrors=false", key: "import-1-2", elements: 20},
+			},
+		},
+		{
+			name:     "invalid elements are not sent",
+			contacts: BatchSize + 1,
+			invalid:  []int{0, BatchSize},
+			wantChunks: []sentChunk{
+				{method: http.MethodPut, query: "summarizeErrors=false", elements: BatchSize - 1},
+			},
+			wantFailed: []int{0, BatchSize},
+		},
+		{
+			name:     "invalid elements keep the ranges",
+			contacts: 2 * BatchSize,
+			invalid:  []int{0},
+			key:      "import-2",
+			wantChunks: []sentChunk{
+				{method: http.MethodPut, query: "summarizeErrors=false", key: "import-2-0-6487b974", elements: BatchSize - 1},
+				{method: http.MethodPut, query: "summarizeErrors=false", key: "import-2-1", elements: BatchSize},
+			},
+			wantFailed: []int{0},
+		},
+		{
+			name:     "failed chunk",
+			contacts: BatchSize + 5,
+			failure:  &xerotest.Failure{Path: "Contacts", StatusCode: http.StatusInternalServerError},
+			wantChunks: []sentChunk{
+				{method: http.MethodPut, query: "summarizeErrors=false", elements: BatchSize},
+				{method: http.MethodPut, query: "summarizeErrors=false", elements: 5},
+			},
+			wantFailed: rangeOf(0, BatchSize),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := xerotest.NewServer()
+			defer s.Close()
+			tenantID := s.AddTenant("Demo Company")
+			if tt.failure != nil {
+				s.Fail(*tt.failure)
+			}
+			recorder := &chunkRecorder{T: http.DefaultTransport}
+			cl := &http.Client{
+				Transport: &auth.Transport{
+					Base:   &auth.XeroTransport{T: recorder, TenantID: tenantID},
+					Source: oauth2.StaticTokenSource(s.Token()),
+				},
+			}
+			ctx := WithValidation(helpers.WithEndpoints(context.Background(), s.Endpoints()))
+			if tt.key != "" {
+				ctx = helpers.WithIdempotencyKey(ctx, tt.key)
+			}
+
+			contacts := Contacts{}
+			for n := 0; n < tt.contacts; n++ {
+				contacts.Contacts = append(contacts.Contacts, Contact{Name: fmt.Sprintf("Contact %03d", n)})
+			}
+			for _, n := range tt.invalid {
+				contacts.Contacts[n].Name = ""
+			}
+
+			results, err := contacts.CreateAllContext(ctx, cl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateAllContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(recorder.chunks) != len(tt.wantChunks) {
+				t.Fatalf("sent %d chunks %+v, want %d", len(recorder.chunks), recorder.chunks, len(tt.wantChunks))
+			}
+			keys := map[string]bool{}
+			for n, chunk := range recorder.chunks {
+				if chunk.key == "" || keys[chunk.key] {
+					t.Errorf("chunk %d has the Idempotency-Key %q, want a new one", n, chunk.key)
+				}
+				keys[chunk.key] = true
+				if tt.key == "" {
+					// the keys are generated when the context doesn't have one
+					chunk.key = ""
+				}
+				if chunk != tt.wantChunks[n] {
+					t.Errorf("chunk %d = %+v, want %+v", n, chunk, tt.wantChunks[n])
+				}
+			}
+
+			if len(results) != tt.contacts {
+				t.Fatalf("%d results, want %d", len(results), tt.contacts)
+			}
+			failed := map[int]bool{}
+			for _, n := range tt.wantFailed {
+				failed[n] = true
+			}
+			for n, result := range results {
+				switch {
+				case failed[n] && result.Err == nil:
+					t.Errorf("result %d has no error", n)
+				case !failed[n] && result.Err != nil:
+					t.Errorf("result %d error = %v", n, result.Err)
+				case !failed[n] && (uuid.FromStringOrNil(result.Contact.ContactID) == uuid.Nil || result.Contact.Name != contacts.Contacts[n].Name):
+					t.Errorf("result %d = %s %q, want the contact %q saved", n, result.Contact.ContactID, result.Contact.Name, contacts.Contacts[n].Name)
+				}
+			}
+			for _, n := range tt.invalid {
+				if !errors.Is(results[n].Err, helpers.ErrValidation) {
+					t.Errorf("result %d error = %v, want a validation error", n, results[n].Err)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateAllRetryKey(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	tenantID := s.AddTenant("Demo Company")
+	cl := s.Client(tenantID)
+	ctx := WithValidation(helpers.WithEndpoints(context.Background(), s.Endpoints()))
+	ctx = helpers.WithIdempotencyKey(ctx, "import-3")
+
+	contacts := Contacts{}
+	for n := 0; n < 2*BatchSize; n++ {
+		contacts.Contacts = append(contacts.Contacts, Contact{Name: fmt.Sprintf("Contact %03d", n)})
+	}
+	contacts.Contacts[0].Name = ""
+	first, err := contacts.CreateAllContext(ctx, cl)
+	if err != nil {
+		t.Fatalf("CreateAllContext() error = %v", err)
+	}
+
+	// the range of the fixed element is sent again with a new key, the other
+	// one is replayed
+	contacts.Contacts[0].Name = "Contact 000"
+	retry, err := contacts.CreateAllContext(ctx, cl)
+	if err != nil {
+		t.Fatalf("CreateAllContext() retry error = %v", err)
+	}
+	for n, result := range retry {
+		if result.Err != nil || result.Contact.Name != contacts.Contacts[n].Name {
+			t.Errorf("retry result %d = %q %v, want the contact %q", n, result.Contact.Name, result.Err, contacts.Contacts[n].Name)
+		}
+		replayed := result.Contact.ContactID == first[n].Contact.ContactID
+		if replayed != (n >= BatchSize) {
+			t.Errorf("retry result %d replayed = %v, want %v", n, replayed, n >= BatchSize)
+		}
+	}
+}
+
+func rangeOf(from int, to int) []int {
+	n := make([]int, 0, to-from)
+	for i := from; i < to; i++ {
+		n = append(n, i)
+	}
+	return n
+}
@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -8,7 +9,7 @@ import (
 )
 
 const (
-	brandingThemeURL = "https://api.xero.com/api.xro/2.0/BrandingThemes"
+	brandingThemePath = "BrandingThemes"
 )
 
 //BrandingTheme applies structure and visuals to an invoice when printed or sent
@@ -24,7 +25,7 @@ type BrandingTheme struct {
 	SortOrder float64 `json:"SortOrder,omitempty" xml:"SortOrder,omitempty"`
 
 	// UTC timestamp of creation date of branding theme
-	CreatedDateUTC string `json:"CreatedDateUTC,omitempty" xml:"CreatedDateUTC,omitempty"`
+	CreatedDateUTC *DateTime `json:"CreatedDateUTC,omitempty" xml:"CreatedDateUTC,omitempty"`
 }
 
 func unmarshalBrandingTheme(brandingThemeBytes []byte) ([]BrandingTheme, error) {
@@ -35,19 +36,17 @@ func unmarshalBrandingTheme(brandingThemeBytes []byte) ([]BrandingTheme, error)
 	if err != nil {
 		return nil, err
 	}
-
-	for n := len(response.Themes) - 1; n >= 0; n-- {
-		response.Themes[n].CreatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(response.Themes[n].CreatedDateUTC, true)
-		if err != nil {
-			return nil, err
-		}
-	}
 	return response.Themes, nil
 }
 
 // FindBrandingThemes will get all BrandingThemes.
 func FindBrandingThemes(cl *http.Client) ([]BrandingTheme, error) {
-	brandingThemeBytes, err := helpers.Find(cl, brandingThemeURL, nil, nil)
+	return FindBrandingThemesContext(context.Background(), cl)
+}
+
+// FindBrandingThemesContext is the same as FindBrandingThemes but the request is bound to the given context
+func FindBrandingThemesContext(ctx context.Context, cl *http.Client) ([]BrandingTheme, error) {
+	brandingThemeBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, brandingThemePath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
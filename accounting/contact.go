@@ -1,15 +1,18 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
-	contactsURL = "https://api.xero.com/api.xro/2.0/Contacts"
+	contactsPath = "Contacts"
 )
 
 //Contact is a debtor/customer or creditor/supplier in a Xero Organisation
@@ -25,7 +28,7 @@ type Contact struct {
 	AccountNumber string `json:"AccountNumber,omitempty"`
 
 	// Current status of a contact – see contact status types
-	ContactStatus string `json:"ContactStatus,omitempty"`
+	ContactStatus ContactStatus `json:"ContactStatus,omitempty"`
 
 	// Full name of contact/organisation (max length = 255)
 	Name string `json:"Name,omitempty"`
@@ -52,10 +55,10 @@ type Contact struct {
 	TaxNumber string `json:"TaxNumber,omitempty"`
 
 	// Default tax type used for contact on AR Contacts
-	AccountsReceivableTaxType string `json:"AccountsReceivableTaxType,omitempty"`
+	AccountsReceivableTaxType TaxType `json:"AccountsReceivableTaxType,omitempty"`
 
 	// Default tax type used for contact on AP Contacts
-	AccountsPayableTaxType string `json:"AccountsPayableTaxType,omitempty"`
+	AccountsPayableTaxType TaxType `json:"AccountsPayableTaxType,omitempty"`
 
 	// Store certain address types for a contact – see address types
 	Addresses *[]Address `json:"Addresses,omitempty"`
@@ -94,7 +97,7 @@ type Contact struct {
 	TrackingCategoryOption string `json:"TrackingCategoryOption,omitempty"`
 
 	// UTC timestamp of last update to contact
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Displays which contact groups a contact is included in
 	ContactGroups *[]ContactGroup `json:"ContactGroups,omitempty"`
@@ -106,13 +109,23 @@ type Contact struct {
 	BatchPayments BatchPayment `json:"BatchPayments,omitempty"`
 
 	// The default discount rate for the contact (read only)
-	Discount float64 `json:"Discount,omitempty"`
+	Discount Decimal `json:"Discount,omitempty"`
 
 	// The raw AccountsReceivable(sales Contacts) and AccountsPayable(bills) outstanding and overdue amounts, not converted to base currency (read only)
 	Balances Balances `json:"Balances,omitempty"`
 
 	// A boolean to indicate if a contact has an attachment
 	HasAttachments bool `json:"HasAttachments,omitempty"`
+
+	// OK, WARNING or ERROR when the request was sent with summarizeErrors=false,
+	// see CreateAll
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the contact returned with summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+
+	// Warnings of the contact returned with summarizeErrors=false
+	Warnings []helpers.Warning `json:"Warnings,omitempty"`
 }
 
 //Contacts contains a collection of Contacts
@@ -130,22 +143,8 @@ type Balances struct {
 //Balance is the raw AccountsReceivable(sales invoices) and AccountsPayable(bills)
 //outstanding and overdue amounts, not converted to base currency
 type Balance struct {
-	Outstanding float64 `json:"Outstanding,omitempty"`
-	Overdue     float64 `json:"Overdue,omitempty"`
-}
-
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (c *Contacts) convertDates() error {
-	var err error
-	for n := len(c.Contacts) - 1; n >= 0; n-- {
-		c.Contacts[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(c.Contacts[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	Outstanding Decimal `json:"Outstanding,omitempty"`
+	Overdue     Decimal `json:"Overdue,omitempty"`
 }
 
 // unmarshalContact intermediate function used for apply the the changes in dates
@@ -158,18 +157,35 @@ func unmarshalContact(contactResponseBytes []byte) (*Contacts, error) {
 		return nil, err
 	}
 
-	err = contactResponse.convertDates()
+	return contactResponse, err
+}
+
+// FindContacts will get all the contacts from Xero linked with the given
+// tenantID. Only the first page is returned, use NewContactIterator or
+// FindAllContacts for getting all of them
+func FindContacts(cl *http.Client) (*Contacts, error) {
+	return FindContactsContext(context.Background(), cl)
+}
+
+// FindContactsContext is the same as FindContacts but the request is bound to the given context
+func FindContactsContext(ctx context.Context, cl *http.Client) (*Contacts, error) {
+	contactResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, contactsPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
+	return unmarshalContact(contactResponseBytes)
+}
 
-	return contactResponse, err
+// FindContactsModifiedSince will get the contacts modified after the given date, a zero
+// modifiedSince will not filter by date. Additional querystringParameters such as
+// where, page and order can be added as a map
+func FindContactsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Contacts, error) {
+	return FindContactsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
 }
 
-// FindContacts will get all the contacts from Xero linked with the given
-// tenantID
-func FindContacts(cl *http.Client) (*Contacts, error) {
-	contactResponseBytes, err := helpers.Find(cl, contactsURL, nil, nil)
+// FindContactsModifiedSinceContext is the same as FindContactsModifiedSince but the request is bound to the given context
+func FindContactsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Contacts, error) {
+	contactResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, contactsPath), helpers.ModifiedSinceHeaders(modifiedSince), queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +194,12 @@ func FindContacts(cl *http.Client) (*Contacts, error) {
 
 // FindContact will find the contact info with the given contactID
 func FindContact(cl *http.Client, contactID uuid.UUID) (*Contact, error) {
-	contactResponseBytes, err := helpers.Find(cl, contactsURL+"/"+contactID.String(), nil, nil)
+	return FindContactContext(context.Background(), cl, contactID)
+}
+
+// FindContactContext is the same as FindContact but the request is bound to the given context
+func FindContactContext(ctx context.Context, cl *http.Client, contactID uuid.UUID) (*Contact, error) {
+	contactResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, contactsPath, contactID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -194,19 +215,102 @@ func FindContact(cl *http.Client, contactID uuid.UUID) (*Contact, error) {
 
 // Create will create contacts with the given information
 func (c *Contacts) Create(cl *http.Client) (*Contacts, error) {
+	return c.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (c *Contacts) CreateContext(ctx context.Context, cl *http.Client) (*Contacts, error) {
+	if err := validate(ctx, c); err != nil {
+		return nil, err
+	}
 	buf, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
-	contactResponseBytes, err := helpers.Create(cl, contactsURL, buf)
+	contactResponseBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, contactsPath), buf)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalContact(contactResponseBytes)
 }
 
+// ContactResult is the result of an element of Contacts.CreateAll or Contacts.UpdateAll,
+// either the contact returned by Xero or the error that stopped it
+type ContactResult struct {
+	Contact Contact
+	Err     error
+}
+
+// CreateAll will create the contacts in requests of BatchSize elements
+// with summarizeErrors=false, so the valid ones are created even when others
+// are rejected. The results are in the order of the contacts, the error is
+// the first one failing a whole request
+func (c *Contacts) CreateAll(cl *http.Client) ([]ContactResult, error) {
+	return c.CreateAllContext(context.Background(), cl)
+}
+
+// CreateAllContext is the same as CreateAll but the requests are bound to the given context
+func (c *Contacts) CreateAllContext(ctx context.Context, cl *http.Client) ([]ContactResult, error) {
+	return c.sendAll(ctx, cl, false)
+}
+
+// UpdateAll is the same as CreateAll but the contacts with an ID are
+// updated instead of created
+func (c *Contacts) UpdateAll(cl *http.Client) ([]ContactResult, error) {
+	return c.UpdateAllContext(context.Background(), cl)
+}
+
+// UpdateAllContext is the same as UpdateAll but the requests are bound to the given context
+func (c *Contacts) UpdateAllContext(ctx context.Context, cl *http.Client) ([]ContactResult, error) {
+	return c.sendAll(ctx, cl, true)
+}
+
+func (c *Contacts) sendAll(ctx context.Context, cl *http.Client, update bool) ([]ContactResult, error) {
+	results := make([]ContactResult, len(c.Contacts))
+	sender := batch{
+		n: len(c.Contacts),
+		element: func(n int) validator {
+			return &c.Contacts[n]
+		},
+		payload: func(indexes []int) interface{} {
+			chunk := Contacts{}
+			for _, n := range indexes {
+				chunk.Contacts = append(chunk.Contacts, c.Contacts[n])
+			}
+			return chunk
+		},
+		decode: func(buf []byte, indexes []int) error {
+			var saved Contacts
+			if err := json.Unmarshal(buf, &saved); err != nil {
+				return err
+			}
+			for k, n := range indexes {
+				if k >= len(saved.Contacts) {
+					results[n].Err = errMissingElement(n)
+					continue
+				}
+				results[n].Contact = saved.Contacts[k]
+				results[n].Err = elementError(n, saved.Contacts[k].StatusAttributeString, saved.Contacts[k].ValidationErrors)
+			}
+			return nil
+		},
+		fail: func(n int, err error) {
+			results[n] = ContactResult{Contact: c.Contacts[n], Err: err}
+		},
+	}
+	return results, sender.send(ctx, cl, helpers.AccountingURL(ctx, contactsPath), update)
+}
+
 // Update will update the contact with the given criteria
 func (c *Contact) Update(cl *http.Client) (*Contacts, error) {
+	return c.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (c *Contact) UpdateContext(ctx context.Context, cl *http.Client) (*Contacts, error) {
+	if err := validate(ctx, c); err != nil {
+		return nil, err
+	}
 	cn := Contacts{
 		Contacts: []Contact{*c},
 	}
@@ -214,9 +318,155 @@ func (c *Contact) Update(cl *http.Client) (*Contacts, error) {
 	if err != nil {
 		return nil, err
 	}
-	contactResponseBytes, err := helpers.Update(cl, contactsURL+"/"+c.ContactID, buf)
+	contactResponseBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, contactsPath, c.ContactID), buf)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalContact(contactResponseBytes)
 }
+
+// Patch will update only the given fields of the contact, which are sent even
+// when they are zero (false, 0, "" or null) so they can be cleared, e.g.
+// Patch(cl, "EmailAddress") after setting the EmailAddress to ""
+func (c *Contact) Patch(cl *http.Client, fields ...string) (*Contacts, error) {
+	return c.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (c *Contact) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (*Contacts, error) {
+	buf, err := patchBody("Contacts", "ContactID", c, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, contactsPath, c.ContactID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalContact(buf)
+}
+
+// ContactIterator walks all the pages of contacts, use Next to advance and Contact to get
+// the current one
+type ContactIterator struct {
+	pager
+	contacts []Contact
+	current  Contact
+}
+
+// NewContactIterator will build an iterator over all the contacts matching the
+// given queryParameters (where, order...), when modifiedSince is not zero only
+// the contacts modified after it are returned
+func NewContactIterator(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) *ContactIterator {
+	return &ContactIterator{
+		pager: newPager(ctx, cl, helpers.AccountingURL(ctx, contactsPath), modifiedSince, queryParameters),
+	}
+}
+
+// Next advances to the next contact, fetching the next page when needed. It
+// returns false when there are no more contacts or an error happened, see Err
+func (it *ContactIterator) Next() bool {
+	for len(it.contacts) == 0 {
+		buf, ok := it.fetch()
+		if !ok {
+			return false
+		}
+		page, err := unmarshalContact(buf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.Contacts) == 0 {
+			return it.finish()
+		}
+		it.contacts = page.Contacts
+	}
+	it.current = it.contacts[0]
+	it.contacts = it.contacts[1:]
+	return true
+}
+
+// Contact returns the current contact
+func (it *ContactIterator) Contact() *Contact {
+	return &it.current
+}
+
+// FindAllContacts will get the contacts of all the pages, see NewContactIterator
+func FindAllContacts(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Contacts, error) {
+	return FindAllContactsContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindAllContactsContext is the same as FindAllContacts but the requests are bound to the given context
+func FindAllContactsContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Contacts, error) {
+	all := &Contacts{Contacts: []Contact{}}
+	it := NewContactIterator(ctx, cl, modifiedSince, queryParameters)
+	for it.Next() {
+		all.Contacts = append(all.Contacts, *it.Contact())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ContactStatus is the status of a contact
+type ContactStatus string
+
+// The contact statuses
+const (
+	ContactStatusActive      ContactStatus = "ACTIVE"
+	ContactStatusArchived    ContactStatus = "ARCHIVED"
+	ContactStatusGDPRRequest ContactStatus = "GDPRREQUEST"
+)
+
+// IsValid reports whether the status is one of the documented values
+func (v ContactStatus) IsValid() bool {
+	switch v {
+	case ContactStatusActive,
+		ContactStatusArchived,
+		ContactStatusGDPRRequest:
+		return true
+	}
+	return false
+}
+
+// Validate checks the contact against the rules documented by Xero, the
+// required elements are only checked when creating it
+func (c *Contact) Validate() error {
+	r := rules{}
+	if c.ContactID == "" {
+		r.required("Name", c.Name != "")
+	}
+	r.maxLength("ContactNumber", c.ContactNumber, 50)
+	r.maxLength("AccountNumber", c.AccountNumber, 50)
+	r.maxLength("Name", c.Name, 255)
+	r.maxLength("FirstName", c.FirstName, 255)
+	r.maxLength("LastName", c.LastName, 255)
+	r.maxLength("EmailAddress", c.EmailAddress, 255)
+	r.maxLength("TaxNumber", c.TaxNumber, 50)
+	r.enum("ContactStatus", c.ContactStatus, c.ContactStatus != "")
+	if c.Addresses != nil {
+		for n := range *c.Addresses {
+			r.nested(fmt.Sprintf("Addresses[%d]", n), (*c.Addresses)[n].Validate())
+		}
+	}
+	if c.Phones != nil {
+		for n := range *c.Phones {
+			r.nested(fmt.Sprintf("Phones[%d]", n), (*c.Phones)[n].Validate())
+		}
+	}
+	if c.ContactPersons != nil && len(*c.ContactPersons) > 5 {
+		r.add("ContactPersons", "can't have more than 5 contact persons")
+	}
+	return r.err()
+}
+
+// Validate checks every contact of the collection
+func (c *Contacts) Validate() error {
+	return validateAll("Contacts", len(c.Contacts), func(n int) validator { return &c.Contacts[n] })
+}
+
+// isReference reports whether the contact identifies a contact for a
+// document, by its ContactID, ContactNumber or Name
+func (c *Contact) isReference() bool {
+	return c.ContactID != "" || c.ContactNumber != "" || c.Name != ""
+}
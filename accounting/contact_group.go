@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -9,7 +10,7 @@ import (
 )
 
 const (
-	contactGroupsURL = "https://api.xero.com/api.xro/2.0/ContactGroups"
+	contactGroupsPath = "ContactGroups"
 )
 
 //ContactGroup is a way of organising Contacts into groups
@@ -45,7 +46,12 @@ func unmarshalContactGroup(contactGroupResponseBytes []byte) (*ContactGroups, er
 
 // FindContactGroups will get all contactGroups
 func FindContactGroups(cl *http.Client) (*ContactGroups, error) {
-	contactGroupsBytes, err := helpers.Find(cl, contactGroupsURL, nil, nil)
+	return FindContactGroupsContext(context.Background(), cl)
+}
+
+// FindContactGroupsContext is the same as FindContactGroups but the request is bound to the given context
+func FindContactGroupsContext(ctx context.Context, cl *http.Client) (*ContactGroups, error) {
+	contactGroupsBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, contactGroupsPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +61,12 @@ func FindContactGroups(cl *http.Client) (*ContactGroups, error) {
 
 // FindContactGroup will get a single contactGroup - contactGroupID must be a GUID for an contactGroup
 func FindContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
-	contactGroupsBytes, err := helpers.Find(cl, contactGroupsURL+"/"+contactGroupID.String(), nil, nil)
+	return FindContactGroupContext(context.Background(), cl, contactGroupID)
+}
+
+// FindContactGroupContext is the same as FindContactGroup but the request is bound to the given context
+func FindContactGroupContext(ctx context.Context, cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
+	contactGroupsBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, contactGroupsPath, contactGroupID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +76,12 @@ func FindContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups
 
 // RemoveContactGroup will get a single contactGroup - contactGroupID must be a GUID for an contactGroup
 func RemoveContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
-	contactGroupsBytes, err := helpers.Remove(cl, contactGroupsURL+"/"+contactGroupID.String())
+	return RemoveContactGroupContext(context.Background(), cl, contactGroupID)
+}
+
+// RemoveContactGroupContext is the same as RemoveContactGroup but the request is bound to the given context
+func RemoveContactGroupContext(ctx context.Context, cl *http.Client, contactGroupID uuid.UUID) (*ContactGroups, error) {
+	contactGroupsBytes, err := helpers.RemoveContext(ctx, cl, helpers.AccountingURL(ctx, contactGroupsPath, contactGroupID.String()))
 	if err != nil {
 		return nil, err
 	}
@@ -75,11 +91,19 @@ func RemoveContactGroup(cl *http.Client, contactGroupID uuid.UUID) (*ContactGrou
 
 //Create will create contactGroups given an ContactGroups struct
 func (c *ContactGroups) Create(cl *http.Client) (*ContactGroups, error) {
+	return c.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (c *ContactGroups) CreateContext(ctx context.Context, cl *http.Client) (*ContactGroups, error) {
+	if err := validate(ctx, c); err != nil {
+		return nil, err
+	}
 	buf, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
-	contactGroupBytes, err := helpers.Create(cl, contactGroupsURL, buf)
+	contactGroupBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, contactGroupsPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -90,6 +114,14 @@ func (c *ContactGroups) Create(cl *http.Client) (*ContactGroups, error) {
 //Update will update an contactGroup given an ContactGroups struct
 //This will only handle single contactGroup - you cannot update multiple contactGroups in a single call
 func (c *ContactGroup) Update(cl *http.Client) (*ContactGroups, error) {
+	return c.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (c *ContactGroup) UpdateContext(ctx context.Context, cl *http.Client) (*ContactGroups, error) {
+	if err := validate(ctx, c); err != nil {
+		return nil, err
+	}
 	cg := ContactGroups{
 		ContactGroups: []ContactGroup{*c},
 	}
@@ -97,10 +129,43 @@ func (c *ContactGroup) Update(cl *http.Client) (*ContactGroups, error) {
 	if err != nil {
 		return nil, err
 	}
-	contactGroupBytes, err := helpers.Update(cl, contactGroupsURL+"/"+c.ContactGroupID, buf)
+	contactGroupBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, contactGroupsPath, c.ContactGroupID), buf)
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalContactGroup(contactGroupBytes)
 }
+
+// Patch will send the given fields of the contact group and nothing else, e.g.
+// Patch(cl, "Name") to rename it
+func (c *ContactGroup) Patch(cl *http.Client, fields ...string) (*ContactGroups, error) {
+	return c.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (c *ContactGroup) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (*ContactGroups, error) {
+	buf, err := patchBody("ContactGroups", "ContactGroupID", c, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, contactGroupsPath, c.ContactGroupID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalContactGroup(buf)
+}
+
+// Validate checks the contact group against the rules documented by Xero
+func (c *ContactGroup) Validate() error {
+	r := rules{}
+	if c.ContactGroupID == "" {
+		r.required("Name", c.Name != "")
+	}
+	return r.err()
+}
+
+// Validate checks every contact group of the collection
+func (c *ContactGroups) Validate() error {
+	return validateAll("ContactGroups", len(c.ContactGroups), func(n int) validator { return &c.ContactGroups[n] })
+}
@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -10,7 +11,7 @@ import (
 )
 
 const (
-	creditNotesURL = "https://api.xero.com/api.xro/2.0/CreditNotes"
+	creditNotesPath = "CreditNotes"
 )
 
 //CreditNote an be raised directly against a customer or supplier,
@@ -18,7 +19,7 @@ const (
 type CreditNote struct {
 
 	// See Credit Note Types
-	Type string `json:"Type,omitempty"`
+	Type CreditNoteType `json:"Type,omitempty"`
 
 	// See Contacts
 	Contact Contact `json:"Contact"`
@@ -26,34 +27,34 @@ type CreditNote struct {
 	// The date the credit note is issued YYYY-MM-DD.
 	// If the Date element is not specified then it will default
 	// to the current date based on the timezone setting of the organisation
-	Date string `json:"DateString,omitempty"`
+	Date *Date `json:"DateString,omitempty"`
 
 	// See Credit Note Status Codes
-	Status string `json:"Status,omitempty"`
+	Status InvoiceStatus `json:"Status,omitempty"`
 
 	// See Invoice Line Amount Types
-	LineAmountTypes string `json:"LineAmountTypes,omitempty"`
+	LineAmountTypes LineAmountType `json:"LineAmountTypes,omitempty"`
 
 	// See Invoice Line Items
 	LineItems []LineItem `json:"LineItems,omitempty"`
 
 	// The subtotal of the credit note excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal Decimal `json:"SubTotal,omitempty"`
 
 	// The total tax on the credit note
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax Decimal `json:"TotalTax,omitempty"`
 
 	// The total of the Credit Note(subtotal + total tax)
-	Total float64 `json:"Total,omitempty"`
+	Total Decimal `json:"Total,omitempty"`
 
 	// UTC timestamp of last update to the credit note
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Currency used for the Credit Note
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
 
 	// Date when credit note was fully paid(UTC format)
-	FullyPaidOnDate string `json:"FullyPaidOnDate,omitempty"`
+	FullyPaidOnDate *Date `json:"FullyPaidOnDate,omitempty"`
 
 	// Xero generated unique identifier
 	CreditNoteID string `json:"CreditNoteID,omitempty"`
@@ -68,10 +69,10 @@ type CreditNote struct {
 	SentToContact bool `json:"SentToContact,omitempty"`
 
 	// The currency rate for a multicurrency invoice. If no rate is specified, the XE.com day rate is used
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate Decimal `json:"CurrencyRate,omitempty"`
 
 	// The remaining credit balance on the Credit Note
-	RemainingCredit float64 `json:"RemainingCredit,omitempty"`
+	RemainingCredit Decimal `json:"RemainingCredit,omitempty"`
 
 	// See Allocations
 	Allocations *[]Allocation `json:"Allocations,omitempty"`
@@ -88,20 +89,6 @@ type CreditNotes struct {
 	CreditNotes []CreditNote `json:"CreditNotes"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (c *CreditNotes) convertDates() error {
-	var err error
-	for n := len(c.CreditNotes) - 1; n >= 0; n-- {
-		c.CreditNotes[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(c.CreditNotes[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func unmarshalCreditNote(creditNoteResponseBytes []byte) (*CreditNotes, error) {
 	var creditNoteResponse *CreditNotes
 	err := json.Unmarshal(creditNoteResponseBytes, &creditNoteResponse)
@@ -109,21 +96,24 @@ func unmarshalCreditNote(creditNoteResponseBytes []byte) (*CreditNotes, error) {
 		return nil, err
 	}
 
-	err = creditNoteResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
-
 	return creditNoteResponse, err
 }
 
 // Create will create accounts given an Accounts struct
 func (c *CreditNotes) Create(cl *http.Client) (*CreditNotes, error) {
+	return c.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (c *CreditNotes) CreateContext(ctx context.Context, cl *http.Client) (*CreditNotes, error) {
+	if err := validate(ctx, c); err != nil {
+		return nil, err
+	}
 	buf, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
-	creditNotesBytes, err := helpers.Create(cl, creditNotesURL, buf)
+	creditNotesBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, creditNotesPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -134,6 +124,14 @@ func (c *CreditNotes) Create(cl *http.Client) (*CreditNotes, error) {
 // Update will update an account given an Accounts struct
 // This will only handle single account - you cannot update multiple accounts in a single call
 func (c *CreditNote) Update(cl *http.Client) (*CreditNotes, error) {
+	return c.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (c *CreditNote) UpdateContext(ctx context.Context, cl *http.Client) (*CreditNotes, error) {
+	if err := validate(ctx, c); err != nil {
+		return nil, err
+	}
 	cn := CreditNotes{
 		CreditNotes: []CreditNote{*c},
 	}
@@ -141,7 +139,7 @@ func (c *CreditNote) Update(cl *http.Client) (*CreditNotes, error) {
 	if err != nil {
 		return nil, err
 	}
-	creditNotesBytes, err := helpers.Update(cl, creditNotesURL+"/"+c.CreditNoteID, buf)
+	creditNotesBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, creditNotesPath, c.CreditNoteID), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -149,11 +147,36 @@ func (c *CreditNote) Update(cl *http.Client) (*CreditNotes, error) {
 	return unmarshalCreditNote(creditNotesBytes)
 }
 
+// Patch will update the given CreditNote fields only, e.g. Patch(cl, "Reference").
+// A field left empty is sent empty, which clears it
+func (c *CreditNote) Patch(cl *http.Client, fields ...string) (*CreditNotes, error) {
+	return c.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (c *CreditNote) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (*CreditNotes, error) {
+	buf, err := patchBody("CreditNotes", "CreditNoteID", c, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, creditNotesPath, c.CreditNoteID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalCreditNote(buf)
+}
+
 // FindCreditNotes will get all CreditNotes. These Credit Notes will not have details like line items by default.
 // If you need details then then add a 'page' querystringParameter and get 100 Credit Notes at a time
 // additional querystringParameters such as where, page, order can be added as a map
+// Use NewCreditNoteIterator or FindAllCreditNotes for walking all the pages
 func FindCreditNotes(cl *http.Client, queryParameters map[string]string) (*CreditNotes, error) {
-	creditNotes, err := helpers.Find(cl, creditNotesURL, nil, queryParameters)
+	return FindCreditNotesContext(context.Background(), cl, queryParameters)
+}
+
+// FindCreditNotesContext is the same as FindCreditNotes but the request is bound to the given context
+func FindCreditNotesContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*CreditNotes, error) {
+	creditNotes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, creditNotesPath), nil, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -166,10 +189,14 @@ func FindCreditNotes(cl *http.Client, queryParameters map[string]string) (*Credi
 // If you need details then then add a 'page' querystringParameter and get 100 Credit Notes at a time
 // additional querystringParameters such as where, page, order can be added as a map
 func FindCreditNotesModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*CreditNotes, error) {
-	additionalHeaders := map[string]string{}
-	additionalHeaders["If-Modified-Since"] = modifiedSince.Format(time.RFC3339)
+	return FindCreditNotesModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindCreditNotesModifiedSinceContext is the same as FindCreditNotesModifiedSince but the request is bound to the given context
+func FindCreditNotesModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*CreditNotes, error) {
+	additionalHeaders := helpers.ModifiedSinceHeaders(modifiedSince)
 
-	creditNotes, err := helpers.Find(cl, creditNotesURL, additionalHeaders, queryParameters)
+	creditNotes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, creditNotesPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +206,12 @@ func FindCreditNotesModifiedSince(cl *http.Client, modifiedSince time.Time, quer
 
 // FindCreditNote will get a single creditNote - creditNoteID can be a GUID for a creditNote or a creditNote number
 func FindCreditNote(cl *http.Client, creditNoteID uuid.UUID) (*CreditNote, error) {
-	creditNotes, err := helpers.Find(cl, creditNotesURL+"/"+creditNoteID.String(), nil, nil)
+	return FindCreditNoteContext(context.Background(), cl, creditNoteID)
+}
+
+// FindCreditNoteContext is the same as FindCreditNote but the request is bound to the given context
+func FindCreditNoteContext(ctx context.Context, cl *http.Client, creditNoteID uuid.UUID) (*CreditNote, error) {
+	creditNotes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, creditNotesPath, creditNoteID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -190,3 +222,107 @@ func FindCreditNote(cl *http.Client, creditNoteID uuid.UUID) (*CreditNote, error
 	}
 	return &notes.CreditNotes[0], nil
 }
+
+// CreditNoteIterator walks all the pages of credit notes, use Next to advance and CreditNote to get
+// the current one
+type CreditNoteIterator struct {
+	pager
+	creditNotes []CreditNote
+	current     CreditNote
+}
+
+// NewCreditNoteIterator will build an iterator over all the credit notes matching the
+// given queryParameters (where, order...), when modifiedSince is not zero only
+// the credit notes modified after it are returned
+func NewCreditNoteIterator(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) *CreditNoteIterator {
+	return &CreditNoteIterator{
+		pager: newPager(ctx, cl, helpers.AccountingURL(ctx, creditNotesPath), modifiedSince, queryParameters),
+	}
+}
+
+// Next advances to the next credit note, fetching the next page when needed. It
+// returns false when there are no more credit notes or an error happened, see Err
+func (it *CreditNoteIterator) Next() bool {
+	for len(it.creditNotes) == 0 {
+		buf, ok := it.fetch()
+		if !ok {
+			return false
+		}
+		page, err := unmarshalCreditNote(buf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.CreditNotes) == 0 {
+			return it.finish()
+		}
+		it.creditNotes = page.CreditNotes
+	}
+	it.current = it.creditNotes[0]
+	it.creditNotes = it.creditNotes[1:]
+	return true
+}
+
+// CreditNote returns the current credit note
+func (it *CreditNoteIterator) CreditNote() *CreditNote {
+	return &it.current
+}
+
+// FindAllCreditNotes will get the credit notes of all the pages, see NewCreditNoteIterator
+func FindAllCreditNotes(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*CreditNotes, error) {
+	return FindAllCreditNotesContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindAllCreditNotesContext is the same as FindAllCreditNotes but the requests are bound to the given context
+func FindAllCreditNotesContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*CreditNotes, error) {
+	all := &CreditNotes{CreditNotes: []CreditNote{}}
+	it := NewCreditNoteIterator(ctx, cl, modifiedSince, queryParameters)
+	for it.Next() {
+		all.CreditNotes = append(all.CreditNotes, *it.CreditNote())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// CreditNoteType is the type of a credit note, ACCPAYCREDIT for the ones from suppliers and ACCRECCREDIT for the ones to customers
+type CreditNoteType string
+
+// The credit note types
+const (
+	CreditNoteTypeAccPayCredit CreditNoteType = "ACCPAYCREDIT"
+	CreditNoteTypeAccRecCredit CreditNoteType = "ACCRECCREDIT"
+)
+
+// IsValid reports whether the credit note type is one of the documented values
+func (v CreditNoteType) IsValid() bool {
+	switch v {
+	case CreditNoteTypeAccPayCredit,
+		CreditNoteTypeAccRecCredit:
+		return true
+	}
+	return false
+}
+
+// Validate checks the credit note against the rules documented by Xero, the
+// required elements are only checked when creating it
+func (c *CreditNote) Validate() error {
+	r := rules{}
+	if c.CreditNoteID == "" {
+		r.required("Type", c.Type != "")
+		r.required("Contact", c.Contact.isReference())
+	}
+	r.enum("Type", c.Type, c.Type != "")
+	r.enum("Status", c.Status, c.Status != "")
+	r.enum("LineAmountTypes", c.LineAmountTypes, c.LineAmountTypes != "")
+	r.maxLength("CreditNoteNumber", c.CreditNoteNumber, 255)
+	r.maxLength("Reference", c.Reference, 255)
+	r.lineItems(c.LineItems, false)
+	return r.err()
+}
+
+// Validate checks every credit note of the collection
+func (c *CreditNotes) Validate() error {
+	return validateAll("CreditNotes", len(c.CreditNotes), func(n int) validator { return &c.CreditNotes[n] })
+}
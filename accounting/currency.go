@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -8,7 +9,7 @@ import (
 )
 
 const (
-	currencyURL = "https://api.xero.com/api.xro/2.0/Currencies"
+	currencyPath = "Currencies"
 )
 
 //Currency is the local currency set up to be used in Xero
@@ -38,7 +39,12 @@ func unmarshalCurrencies(currencyResponseBytes []byte) (*Currencies, error) {
 
 // FindCurrencies will get all currencies
 func FindCurrencies(cl *http.Client) (*Currencies, error) {
-	currencyBytes, err := helpers.Find(cl, currencyURL, nil, nil)
+	return FindCurrenciesContext(context.Background(), cl)
+}
+
+// FindCurrenciesContext is the same as FindCurrencies but the request is bound to the given context
+func FindCurrenciesContext(ctx context.Context, cl *http.Client) (*Currencies, error) {
+	currencyBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, currencyPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -48,12 +54,17 @@ func FindCurrencies(cl *http.Client) (*Currencies, error) {
 
 // Create will create a new currency on Xero
 func (c *Currencies) Create(cl *http.Client) (*Currencies, error) {
+	return c.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (c *Currencies) CreateContext(ctx context.Context, cl *http.Client) (*Currencies, error) {
 	buf, err := json.Marshal(c)
 	if err != nil {
 		return nil, err
 	}
 
-	currencyBytes, err := helpers.Create(cl, currencyURL, buf)
+	currencyBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, currencyPath), buf)
 	if err != nil {
 		return nil, err
 	}
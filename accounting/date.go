@@ -0,0 +1,112 @@
+package accounting
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04:05"
+)
+
+// Date is a calendar date without time of day nor time zone, like the Date
+// or the DueDate of an invoice. It is decoded from both the .Net JSON format
+// and the ISO 8601 one, and encoded as 2006-01-02
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the Date of the given day
+func NewDate(year int, month time.Month, day int) *Date {
+	return &Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf returns the Date of the given time in its own location
+func DateOf(t time.Time) *Date {
+	return NewDate(t.Date())
+}
+
+// String returns the date formatted as 2006-01-02
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+// MarshalJSON will encode the date as 2006-01-02, or null when it is zero
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON will decode the date from the .Net JSON format or the ISO one,
+// only the day is kept
+func (d *Date) UnmarshalJSON(buf []byte) error {
+	t, err := unmarshalTime(buf)
+	if err != nil {
+		return err
+	}
+	if t.IsZero() {
+		d.Time = time.Time{}
+		return nil
+	}
+	d.Time = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return nil
+}
+
+// DateTime is a timestamp in UTC, like the UpdatedDateUTC of every element.
+// It is decoded from both the .Net JSON format and the ISO 8601 one, and
+// encoded as 2006-01-02T15:04:05 in UTC
+type DateTime struct {
+	time.Time
+}
+
+// NewDateTime returns the DateTime of the given time
+func NewDateTime(t time.Time) *DateTime {
+	return &DateTime{t.UTC()}
+}
+
+// String returns the time formatted as 2006-01-02T15:04:05 in UTC
+func (d DateTime) String() string {
+	return d.UTC().Format(dateTimeLayout)
+}
+
+// MarshalJSON will encode the time as 2006-01-02T15:04:05 in UTC, or null when
+// it is zero
+func (d DateTime) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON will decode the time from the .Net JSON format or the ISO one
+func (d *DateTime) UnmarshalJSON(buf []byte) error {
+	t, err := unmarshalTime(buf)
+	if err != nil {
+		return err
+	}
+	d.Time = t.UTC()
+	if t.IsZero() {
+		d.Time = time.Time{}
+	}
+	return nil
+}
+
+func unmarshalTime(buf []byte) (time.Time, error) {
+	if bytes.Equal(buf, []byte("null")) {
+		return time.Time{}, nil
+	}
+	var value string
+	if err := json.Unmarshal(buf, &value); err != nil {
+		return time.Time{}, err
+	}
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return helpers.ParseTime(value)
+}
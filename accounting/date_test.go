@@ -0,0 +1,110 @@
+package accounting
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDateJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    string
+		wantErr bool
+	}{
+		{name: ".Net", json: `"/Date(1585526400000+0000)/"`, want: "2020-03-30"},
+		{name: ".Net without offset", json: `"/Date(1585526400000)/"`, want: "2020-03-30"},
+		{name: ".Net in the local day", json: `"/Date(1585569600000+1300)/"`, want: "2020-03-31"},
+		{name: ".Net before 1970", json: `"/Date(-86400000+0000)/"`, want: "1969-12-31"},
+		{name: "ISO date", json: `"2020-03-30"`, want: "2020-03-30"},
+		{name: "ISO date-time", json: `"2020-03-30T23:59:59"`, want: "2020-03-30"},
+		{name: "null", json: `null`, want: ""},
+		{name: "empty", json: `""`, want: ""},
+		{name: "invalid", json: `"30/03/2020"`, wantErr: true},
+		{name: "number", json: `1585526400000`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Date
+			err := json.Unmarshal([]byte(tt.json), &d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := ""
+			if !d.IsZero() {
+				got = d.String()
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateTimeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want time.Time
+	}{
+		{name: ".Net", json: `"/Date(1585569600123+0000)/"`, want: time.Date(2020, time.March, 30, 12, 0, 0, 123000000, time.UTC)},
+		{name: ".Net with offset", json: `"/Date(1585569600000+1300)/"`, want: time.Date(2020, time.March, 30, 12, 0, 0, 0, time.UTC)},
+		{name: "ISO without offset", json: `"2020-03-30T12:00:00.5"`, want: time.Date(2020, time.March, 30, 12, 0, 0, 500000000, time.UTC)},
+		{name: "ISO with offset", json: `"2020-03-31T01:00:00+13:00"`, want: time.Date(2020, time.March, 30, 12, 0, 0, 0, time.UTC)},
+		{name: "null", json: `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d DateTime
+			if err := json.Unmarshal([]byte(tt.json), &d); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !d.Equal(tt.want) || (!tt.want.IsZero() && d.Location() != time.UTC) {
+				t.Errorf("Unmarshal() = %v, want %v", d.Time, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateMarshalJSON(t *testing.T) {
+	type payload struct {
+		Date    *Date     `json:"Date,omitempty"`
+		DueDate Date      `json:"DueDate"`
+		Updated *DateTime `json:"Updated,omitempty"`
+	}
+	local := time.Date(2020, time.March, 31, 1, 0, 0, 0, time.FixedZone("NZDT", 13*3600))
+	tests := []struct {
+		name    string
+		payload payload
+		want    string
+	}{
+		{
+			name:    "set",
+			payload: payload{Date: DateOf(local), DueDate: *NewDate(2020, time.April, 30), Updated: NewDateTime(local)},
+			want:    `{"Date":"2020-03-31","DueDate":"2020-04-30","Updated":"2020-03-30T12:00:00"}`,
+		},
+		{name: "zero", payload: payload{}, want: `{"DueDate":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := json.Marshal(tt.payload)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(buf) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", buf, tt.want)
+			}
+			var decoded payload
+			if err := json.Unmarshal(buf, &decoded); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if again, _ := json.Marshal(decoded); string(again) != tt.want {
+				t.Errorf("round trip = %s, want %s", again, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package accounting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// MoneyPlaces are the decimal places of the amounts calculated by Xero,
+	// like the LineAmount, the taxes and the totals
+	MoneyPlaces = 2
+	// UnitPlaces are the decimal places allowed for the UnitAmount and the
+	// Quantity of the line items when the unitdp=4 parameter is used
+	UnitPlaces = 4
+
+	// maxPlaces are the decimal places kept by the results without a finite
+	// decimal expansion
+	maxPlaces = 28
+	// maxExponent bounds the exponent of the decimals in scientific notation,
+	// a bigger one would make big.Rat allocate a huge number for a value that
+	// can't be an amount anyway
+	maxExponent = maxPlaces
+
+	unitDPParameter = "unitdp"
+)
+
+// RoundingMode tells how a Decimal is rounded when it has more decimal places
+// than the wanted ones
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest neighbour, away from zero when both
+	// are equally near. This is the rounding used by Xero for the line amounts
+	// and the taxes
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest neighbour, to the even one when both
+	// are equally near
+	RoundHalfEven
+	// RoundDown truncates towards zero
+	RoundDown
+	// RoundUp rounds away from zero
+	RoundUp
+)
+
+var (
+	errDivisionByZero = errors.New("accounting: decimal division by zero")
+
+	decimalPattern    = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+	jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9]\d*)(\.\d+)?$`)
+)
+
+// Decimal is an exact decimal number used for the amounts, quantities and
+// rates, so there is no rounding drift when summing them. It keeps the
+// literal sent by Xero and it is encoded as a JSON number.
+//
+// The empty Decimal is zero and it is omitted by omitempty, while "0" is
+// sent. The arithmetic methods return an error when a value is not a valid
+// number, use ParseDecimal for validating the input
+type Decimal string
+
+// NewDecimal returns the Decimal unscaled * 10^-places, e.g. NewDecimal(1999, 2)
+// is 19.99
+func NewDecimal(unscaled int64, places int) Decimal {
+	r := new(big.Rat).SetInt64(unscaled)
+	r.Quo(r, pow10(places))
+	return fromRat(r)
+}
+
+// NewDecimalFromFloat returns the Decimal with the shortest representation of
+// the given float
+func NewDecimalFromFloat(f float64) Decimal {
+	return Decimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// ParseDecimal returns the Decimal of the given string, or an error if it is
+// not a decimal number
+func ParseDecimal(s string) (Decimal, error) {
+	r, err := parseRat(s)
+	if err != nil {
+		return "", err
+	}
+	d := Decimal(s)
+	if bytes.ContainsAny([]byte(s), "eE") {
+		d = fromRat(r)
+	}
+	return d, nil
+}
+
+// Sum returns the exact sum of the given decimals
+func Sum(ds ...Decimal) (Decimal, error) {
+	r := new(big.Rat)
+	for _, d := range ds {
+		x, err := d.rat()
+		if err != nil {
+			return "", err
+		}
+		r.Add(r, x)
+	}
+	return fromRat(r), nil
+}
+
+// Add returns d + o
+func (d Decimal) Add(o Decimal) (Decimal, error) {
+	x, y, err := rats(d, o)
+	if err != nil {
+		return "", err
+	}
+	return fromRat(x.Add(x, y)), nil
+}
+
+// Sub returns d - o
+func (d Decimal) Sub(o Decimal) (Decimal, error) {
+	x, y, err := rats(d, o)
+	if err != nil {
+		return "", err
+	}
+	return fromRat(x.Sub(x, y)), nil
+}
+
+// Mul returns d * o without rounding, use Round for getting the places wanted
+func (d Decimal) Mul(o Decimal) (Decimal, error) {
+	x, y, err := rats(d, o)
+	if err != nil {
+		return "", err
+	}
+	return fromRat(x.Mul(x, y)), nil
+}
+
+// Div returns d / o rounded to the given places with the given mode, it
+// returns an error if o is zero
+func (d Decimal) Div(o Decimal, places int, mode RoundingMode) (Decimal, error) {
+	x, y, err := rats(d, o)
+	if err != nil {
+		return "", err
+	}
+	if y.Sign() == 0 {
+		return "", errDivisionByZero
+	}
+	return round(x.Quo(x, y), places, mode), nil
+}
+
+// Neg returns -d
+func (d Decimal) Neg() (Decimal, error) {
+	x, err := d.rat()
+	if err != nil {
+		return "", err
+	}
+	return fromRat(x.Neg(x)), nil
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() (Decimal, error) {
+	x, err := d.rat()
+	if err != nil {
+		return "", err
+	}
+	return fromRat(x.Abs(x)), nil
+}
+
+// Round returns d rounded to the given places with the given mode, the result
+// always has that number of places, e.g. 10 rounded to 2 places is 10.00
+func (d Decimal) Round(places int, mode RoundingMode) (Decimal, error) {
+	x, err := d.rat()
+	if err != nil {
+		return "", err
+	}
+	return round(x, places, mode), nil
+}
+
+// Cmp compares d and o and returns -1, 0 or +1
+func (d Decimal) Cmp(o Decimal) (int, error) {
+	x, y, err := rats(d, o)
+	if err != nil {
+		return 0, err
+	}
+	return x.Cmp(y), nil
+}
+
+// Equal reports whether d and o are the same valid number, e.g. 1.5 and 1.50
+func (d Decimal) Equal(o Decimal) bool {
+	cmp, err := d.Cmp(o)
+	return err == nil && cmp == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d, an invalid decimal
+// returns 0
+func (d Decimal) Sign() int {
+	x, err := d.rat()
+	if err != nil {
+		return 0
+	}
+	return x.Sign()
+}
+
+// IsZero reports whether d is a valid decimal equal to zero
+func (d Decimal) IsZero() bool {
+	x, err := d.rat()
+	return err == nil && x.Sign() == 0
+}
+
+// Float64 returns the nearest float64 to d, NaN when it is not a valid decimal
+func (d Decimal) Float64() float64 {
+	x, err := d.rat()
+	if err != nil {
+		return math.NaN()
+	}
+	f, _ := x.Float64()
+	return f
+}
+
+// String returns the decimal as it is, or 0 when it is empty
+func (d Decimal) String() string {
+	if d == "" {
+		return "0"
+	}
+	return string(d)
+}
+
+// WhereLiteral will format the decimal as a number in a where filter
+func (d Decimal) WhereLiteral() string {
+	return d.String()
+}
+
+// MarshalJSON will encode the decimal as a JSON number
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if jsonNumberPattern.MatchString(d.String()) {
+		return []byte(d.String()), nil
+	}
+	r, err := d.rat()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fromRat(r)), nil
+}
+
+// UnmarshalJSON will decode the decimal from a JSON number or a string,
+// keeping its literal
+func (d *Decimal) UnmarshalJSON(buf []byte) error {
+	if bytes.Equal(buf, []byte("null")) {
+		*d = ""
+		return nil
+	}
+	s := string(buf)
+	if len(buf) > 0 && buf[0] == '"' {
+		if err := json.Unmarshal(buf, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*d = ""
+			return nil
+		}
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) rat() (*big.Rat, error) {
+	if d == "" {
+		return new(big.Rat), nil
+	}
+	return parseRat(string(d))
+}
+
+// parseRat parses a decimal number, rejecting the exponents beyond
+// maxExponent before big.Rat expands them
+func parseRat(s string) (*big.Rat, error) {
+	m := decimalPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("accounting: invalid decimal %q", s)
+	}
+	if m[3] != "" {
+		if exp, err := strconv.Atoi(m[3][1:]); err != nil || exp > maxExponent || exp < -maxExponent {
+			return nil, fmt.Errorf("accounting: the exponent of the decimal %q is beyond %d", s, maxExponent)
+		}
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("accounting: invalid decimal %q", s)
+	}
+	return r, nil
+}
+
+func rats(d Decimal, o Decimal) (*big.Rat, *big.Rat, error) {
+	x, err := d.rat()
+	if err != nil {
+		return nil, nil, err
+	}
+	y, err := o.rat()
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, y, nil
+}
+
+// fromRat formats a rational using the fewest places needed, the ones without
+// a finite decimal expansion (e.g. 1/3) are rounded half up to maxPlaces
+func fromRat(r *big.Rat) Decimal {
+	places := 0
+	scaled := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	for !scaled.IsInt() {
+		if places == maxPlaces {
+			return round(r, maxPlaces, RoundHalfUp)
+		}
+		scaled.Mul(scaled, ten)
+		places++
+	}
+	return Decimal(r.FloatString(places))
+}
+
+func round(r *big.Rat, places int, mode RoundingMode) Decimal {
+	scaled := new(big.Rat).Mul(r, pow10(places))
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if remainder.Sign() != 0 {
+		// compare 2*|remainder| with the denominator for knowing if the
+		// discarded part is below, at or above the half
+		half := new(big.Int).Abs(remainder)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(scaled.Denom())
+		away := false
+		switch mode {
+		case RoundHalfUp:
+			away = cmp >= 0
+		case RoundHalfEven:
+			away = cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1)
+		case RoundUp:
+			away = true
+		}
+		if away {
+			quotient.Add(quotient, big.NewInt(int64(scaled.Sign())))
+		}
+	}
+	result := new(big.Rat).SetFrac(quotient, big.NewInt(1))
+	result.Quo(result, pow10(places))
+	if places < 0 {
+		return fromRat(result)
+	}
+	return Decimal(result.FloatString(places))
+}
+
+func pow10(places int) *big.Rat {
+	if places < 0 {
+		return new(big.Rat).Inv(pow10(-places))
+	}
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil))
+}
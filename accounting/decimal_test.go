@@ -0,0 +1,255 @@
+package accounting
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestDecimalArithmetic(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      func() (Decimal, error)
+		want    Decimal
+		wantErr bool
+	}{
+		{name: "add", op: func() (Decimal, error) { return Decimal("0.1").Add("0.2") }, want: "0.3"},
+		{name: "add empty", op: func() (Decimal, error) { return Decimal("").Add("19.99") }, want: "19.99"},
+		{name: "add keeps no trailing zeros", op: func() (Decimal, error) { return Decimal("1.50").Add("1.50") }, want: "3"},
+		{name: "sub", op: func() (Decimal, error) { return Decimal("10").Sub("10.01") }, want: "-0.01"},
+		{name: "mul", op: func() (Decimal, error) { return Decimal("1.25").Mul("0.15") }, want: "0.1875"},
+		{name: "mul exponent", op: func() (Decimal, error) { return Decimal("2e2").Mul("3") }, want: "600"},
+		{name: "div", op: func() (Decimal, error) { return Decimal("10").Div("4", 2, RoundHalfUp) }, want: "2.50"},
+		{name: "div non terminating", op: func() (Decimal, error) { return Decimal("1").Div("3", 4, RoundHalfUp) }, want: "0.3333"},
+		{name: "div by zero", op: func() (Decimal, error) { return Decimal("1").Div("0", 2, RoundHalfUp) }, wantErr: true},
+		{name: "div by empty", op: func() (Decimal, error) { return Decimal("1").Div("", 2, RoundHalfUp) }, wantErr: true},
+		{name: "neg", op: func() (Decimal, error) { return Decimal("19.99").Neg() }, want: "-19.99"},
+		{name: "abs", op: func() (Decimal, error) { return Decimal("-0.5").Abs() }, want: "0.5"},
+		{name: "sum", op: func() (Decimal, error) { return Sum("1.10", "2.205", "-3", "") }, want: "0.305"},
+		{name: "sum of nothing", op: func() (Decimal, error) { return Sum() }, want: "0"},
+		{name: "invalid left", op: func() (Decimal, error) { return Decimal("1,000").Add("1") }, wantErr: true},
+		{name: "invalid right", op: func() (Decimal, error) { return Decimal("1").Sub("abc") }, wantErr: true},
+		{name: "invalid mul", op: func() (Decimal, error) { return Decimal("NaN").Mul("1") }, wantErr: true},
+		{name: "invalid div", op: func() (Decimal, error) { return Decimal("1").Div("1/3", 2, RoundHalfUp) }, wantErr: true},
+		{name: "invalid neg", op: func() (Decimal, error) { return Decimal("$5").Neg() }, wantErr: true},
+		{name: "invalid abs", op: func() (Decimal, error) { return Decimal("Inf").Abs() }, wantErr: true},
+		{name: "invalid round", op: func() (Decimal, error) { return Decimal("1.2.3").Round(2, RoundHalfUp) }, wantErr: true},
+		{name: "invalid sum", op: func() (Decimal, error) { return Sum("1", "two") }, wantErr: true},
+		{name: "huge exponent", op: func() (Decimal, error) { return Decimal("1e999999999").Add("1") }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	tests := []struct {
+		value  Decimal
+		places int
+		mode   RoundingMode
+		want   Decimal
+	}{
+		{value: "2.345", places: 2, mode: RoundHalfUp, want: "2.35"},
+		{value: "-2.345", places: 2, mode: RoundHalfUp, want: "-2.35"},
+		{value: "2.344", places: 2, mode: RoundHalfUp, want: "2.34"},
+		{value: "2.345", places: 2, mode: RoundHalfEven, want: "2.34"},
+		{value: "2.355", places: 2, mode: RoundHalfEven, want: "2.36"},
+		{value: "2.3451", places: 2, mode: RoundHalfEven, want: "2.35"},
+		{value: "2.349", places: 2, mode: RoundDown, want: "2.34"},
+		{value: "-2.349", places: 2, mode: RoundDown, want: "-2.34"},
+		{value: "2.341", places: 2, mode: RoundUp, want: "2.35"},
+		{value: "-2.341", places: 2, mode: RoundUp, want: "-2.35"},
+		{value: "10", places: 2, mode: RoundHalfUp, want: "10.00"},
+		{value: "", places: 2, mode: RoundHalfUp, want: "0.00"},
+		{value: "1250", places: -2, mode: RoundHalfUp, want: "1300"},
+		{value: "1250", places: -2, mode: RoundHalfEven, want: "1200"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.value), func(t *testing.T) {
+			got, err := tt.value.Round(tt.places, tt.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Round(%d, %d) = %q, want %q", tt.places, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalCompare(t *testing.T) {
+	tests := []struct {
+		a, b    Decimal
+		cmp     int
+		wantErr bool
+		equal   bool
+	}{
+		{a: "1.5", b: "1.50", cmp: 0, equal: true},
+		{a: "", b: "0", cmp: 0, equal: true},
+		{a: "-1", b: "0.01", cmp: -1},
+		{a: "100", b: "99.999", cmp: 1},
+		{a: "1,5", b: "1.5", wantErr: true},
+		{a: "1.5", b: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.a)+" "+string(tt.b), func(t *testing.T) {
+			cmp, err := tt.a.Cmp(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Cmp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if cmp != tt.cmp {
+				t.Errorf("Cmp() = %d, want %d", cmp, tt.cmp)
+			}
+			if got := tt.a.Equal(tt.b); got != tt.equal {
+				t.Errorf("Equal() = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestDecimalInvalid(t *testing.T) {
+	d := Decimal("1,000")
+	if d.Sign() != 0 {
+		t.Errorf("Sign() = %d, want 0", d.Sign())
+	}
+	if d.IsZero() {
+		t.Error("IsZero() = true, want false")
+	}
+	if f := d.Float64(); f == f {
+		t.Errorf("Float64() = %v, want NaN", f)
+	}
+	if _, err := json.Marshal(d); err == nil {
+		t.Error("json.Marshal() error = nil, want an error")
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Decimal
+		wantErr bool
+	}{
+		{in: "19.99", want: "19.99"},
+		{in: "-0.5", want: "-0.5"},
+		{in: "+3", want: "+3"},
+		{in: ".5", want: ".5"},
+		{in: "1e3", want: "1000"},
+		{in: "1.5E-2", want: "0.015"},
+		{in: "", wantErr: true},
+		{in: "1,000", wantErr: true},
+		{in: "1/3", wantErr: true},
+		{in: "0x10", wantErr: true},
+		{in: "NaN", wantErr: true},
+		{in: " 1", wantErr: true},
+		{in: "1e28", want: Decimal("1" + strings.Repeat("0", maxExponent))},
+		{in: "-2e-28", want: Decimal("-0." + strings.Repeat("0", maxExponent-1) + "2")},
+		{in: "1e29", wantErr: true},
+		{in: "1e-29", wantErr: true},
+		{in: "1e999999999", wantErr: true},
+		{in: "1E-999999999", wantErr: true},
+		{in: "1e99999999999999999999", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDecimal(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDecimal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDecimal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDecimal(t *testing.T) {
+	tests := []struct {
+		got  Decimal
+		want Decimal
+	}{
+		{got: NewDecimal(1999, 2), want: "19.99"},
+		{got: NewDecimal(-5, 3), want: "-0.005"},
+		{got: NewDecimal(12, -2), want: "1200"},
+		{got: NewDecimal(1000, 2), want: "10"},
+		{got: NewDecimalFromFloat(0.1), want: "0.1"},
+		{got: NewDecimalFromFloat(-2.5e-3), want: "-0.0025"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.want), func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromRat(t *testing.T) {
+	tests := []struct {
+		name string
+		rat  *big.Rat
+		want Decimal
+	}{
+		{name: "integer", rat: big.NewRat(42, 1), want: "42"},
+		{name: "terminating", rat: big.NewRat(1, 8), want: "0.125"},
+		{name: "one third", rat: big.NewRat(1, 3), want: Decimal("0." + strings.Repeat("3", maxPlaces))},
+		{name: "two thirds", rat: big.NewRat(-2, 3), want: Decimal("-0." + strings.Repeat("6", maxPlaces-1) + "7")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fromRat(tt.rat); got != tt.want {
+				t.Errorf("fromRat(%s) = %q, want %q", tt.rat, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    Decimal
+		encoded string
+		wantErr bool
+	}{
+		{name: "number", json: `19.99`, want: "19.99", encoded: `19.99`},
+		{name: "string", json: `"19.99"`, want: "19.99", encoded: `19.99`},
+		{name: "exponent", json: `1.5e2`, want: "150", encoded: `150`},
+		{name: "null", json: `null`, want: "", encoded: `0`},
+		{name: "empty string", json: `""`, want: "", encoded: `0`},
+		{name: "plus sign", json: `"+1.50"`, want: "+1.50", encoded: `1.5`},
+		{name: "invalid", json: `"1,000"`, wantErr: true},
+		{name: "huge exponent", json: `1e999999999`, wantErr: true},
+		{name: "huge exponent string", json: `"-1E+999999999"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Decimal
+			err := json.Unmarshal([]byte(tt.json), &d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("json.Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if d != tt.want {
+				t.Errorf("decoded %q, want %q", d, tt.want)
+			}
+			buf, err := json.Marshal(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(buf) != tt.encoded {
+				t.Errorf("encoded %s, want %s", buf, tt.encoded)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -8,7 +9,7 @@ import (
 )
 
 const (
-	employeeURL = "https://api.xero.com/api.xro/2.0/Employees"
+	employeePath = "Employees"
 )
 
 //Employee is for the deprecated Pay run feature.
@@ -37,7 +38,12 @@ type Employees struct {
 
 // FindEmployees will find the info about employees
 func FindEmployees(cl *http.Client, queryParameters map[string]string) (em *Employees, err error) {
-	employeeResponseBytes, err := helpers.Find(cl, employeeURL, nil, queryParameters)
+	return FindEmployeesContext(context.Background(), cl, queryParameters)
+}
+
+// FindEmployeesContext is the same as FindEmployees but the request is bound to the given context
+func FindEmployeesContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (em *Employees, err error) {
+	employeeResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, employeePath), nil, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -50,11 +56,16 @@ func FindEmployees(cl *http.Client, queryParameters map[string]string) (em *Empl
 
 // Create will create employees with the given information
 func (e *Employees) Create(cl *http.Client) (em *Employees, err error) {
+	return e.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (e *Employees) CreateContext(ctx context.Context, cl *http.Client) (em *Employees, err error) {
 	buf, err := json.Marshal(e)
 	if err != nil {
 		return nil, err
 	}
-	employeeResponseBytes, err := helpers.Create(cl, employeeURL, buf)
+	employeeResponseBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, employeePath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +78,11 @@ func (e *Employees) Create(cl *http.Client) (em *Employees, err error) {
 
 // Update will update employees with the given criteria
 func (e *Employee) Update(cl *http.Client) (em *Employees, err error) {
+	return e.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (e *Employee) UpdateContext(ctx context.Context, cl *http.Client) (em *Employees, err error) {
 	es := Employees{
 		Employess: []Employee{*e},
 	}
@@ -74,7 +90,7 @@ func (e *Employee) Update(cl *http.Client) (em *Employees, err error) {
 	if err != nil {
 		return nil, err
 	}
-	employeeResponseBytes, err := helpers.Update(cl, employeeURL+"/"+e.EmployeeID, buf)
+	employeeResponseBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, employeePath, e.EmployeeID), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -84,3 +100,25 @@ func (e *Employee) Update(cl *http.Client) (em *Employees, err error) {
 	}
 	return em, nil
 }
+
+// Patch will update only the named fields of the employee, e.g.
+// Patch(cl, "FirstName", "LastName"). Empty values are sent as well
+func (e *Employee) Patch(cl *http.Client, fields ...string) (em *Employees, err error) {
+	return e.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (e *Employee) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (em *Employees, err error) {
+	buf, err := patchBody("Employees", "EmployeeID", e, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, employeePath, e.EmployeeID), buf)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(buf, &em); err != nil {
+		return nil, err
+	}
+	return em, nil
+}
@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -8,7 +9,7 @@ import (
 )
 
 const (
-	historyRecordURL = "https://api.xero.com/api.xro/2.0/"
+	historyPath = "history"
 )
 
 // HistoryRecord is a record of monies transferred from one bank account to another
@@ -18,7 +19,7 @@ type HistoryRecord struct {
 	Changes string `json:"Changes,omitempty"`
 
 	// UTC date that the history record was created
-	DateUTC string `json:"DateUTC,omitempty"`
+	DateUTC *DateTime `json:"DateUTC,omitempty"`
 
 	// The user responsible for the change ("System Generated" when the change happens via API)
 	User string `json:"User,omitempty"`
@@ -32,19 +33,6 @@ type HistoryRecords struct {
 	HistoryRecords []HistoryRecord `json:"HistoryRecords"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (h *HistoryRecords) convertDates() error {
-	var err error
-	for n := len(h.HistoryRecords) - 1; n >= 0; n-- {
-		h.HistoryRecords[n].DateUTC, err = helpers.DotNetJSONTimeToRFC3339(h.HistoryRecords[n].DateUTC, false)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func unmarshalHistoryRecord(HistoryRecordResponseBytes []byte) (*HistoryRecords, error) {
 	var historyRecordResponse *HistoryRecords
 	err := json.Unmarshal(HistoryRecordResponseBytes, &historyRecordResponse)
@@ -52,18 +40,18 @@ func unmarshalHistoryRecord(HistoryRecordResponseBytes []byte) (*HistoryRecords,
 		return nil, err
 	}
 
-	err = historyRecordResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
-
 	return historyRecordResponse, err
 }
 
 //FindHistoryAndNotes gets all history items and notes for a given type and ID.
 //it is not supported on all endpoints.  See https://developer.xero.com/documentation/api/history-and-notes#SupportedDocs
 func FindHistoryAndNotes(cl *http.Client, docType string, id string) (*HistoryRecords, error) {
-	historyAndNotesBytes, err := helpers.Find(cl, historyRecordURL+docType+"/"+id+"/history", nil, nil)
+	return FindHistoryAndNotesContext(context.Background(), cl, docType, id)
+}
+
+// FindHistoryAndNotesContext is the same as FindHistoryAndNotes but the request is bound to the given context
+func FindHistoryAndNotesContext(ctx context.Context, cl *http.Client, docType string, id string) (*HistoryRecords, error) {
+	historyAndNotesBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, docType, id, historyPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -73,11 +61,16 @@ func FindHistoryAndNotes(cl *http.Client, docType string, id string) (*HistoryRe
 
 // Create will create History Records given a HistoryRecords struct and a docType and id
 func (h *HistoryRecords) Create(cl *http.Client, docType string, id string) (*HistoryRecords, error) {
+	return h.CreateContext(context.Background(), cl, docType, id)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (h *HistoryRecords) CreateContext(ctx context.Context, cl *http.Client, docType string, id string) (*HistoryRecords, error) {
 	buf, err := json.Marshal(h)
 	if err != nil {
 		return nil, err
 	}
-	historyAndNotesBytes, err := helpers.Create(cl, historyRecordURL+docType+"/"+id+"/history", buf)
+	historyAndNotesBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, docType, id, historyPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -1,21 +1,23 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/quickaco/xerosdk/helpers"
 )
 
 const (
-	invoiceURL = "https://api.xero.com/api.xro/2.0/Invoices"
+	invoicePath = "Invoices"
 )
 
 //Invoice is an Accounts Payable or Accounts Recievable document in a Xero organisation
 type Invoice struct {
 	// See Invoice Types
-	Type string `json:"Type"`
+	Type InvoiceType `json:"Type"`
 
 	// See Contacts
 	Contact Contact `json:"Contact"`
@@ -24,13 +26,13 @@ type Invoice struct {
 	LineItems []LineItem `json:"LineItems"`
 
 	// Date invoice was issued – YYYY-MM-DD. If the Date element is not specified it will default to the current date based on the timezone setting of the organisation
-	Date string `json:"DateString,omitempty"`
+	Date *Date `json:"DateString,omitempty"`
 
 	// Date invoice is due – YYYY-MM-DD
-	DueDate string `json:"DueDateString,omitempty"`
+	DueDate *Date `json:"DueDateString,omitempty"`
 
 	// Line amounts are exclusive of tax by default if you don’t specify this element. See Line Amount Types
-	LineAmountTypes string `json:"LineAmountTypes,omitempty"`
+	LineAmountTypes LineAmountType `json:"LineAmountTypes,omitempty"`
 
 	// ACCREC – Unique alpha numeric code identifying invoice (when missing will auto-generate from your Organisation Invoice Settings) (max length = 255)
 	InvoiceNumber string `json:"InvoiceNumber,omitempty"`
@@ -48,31 +50,31 @@ type Invoice struct {
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
 
 	// The currency rate for a multicurrency invoice. If no rate is specified, the XE.com day rate is used. (max length = [18].[6])
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate Decimal `json:"CurrencyRate,omitempty"`
 
 	// See Invoice Status Codes
-	Status string `json:"Status,omitempty"`
+	Status InvoiceStatus `json:"Status,omitempty"`
 
 	// Boolean to set whether the invoice in the Xero app should be marked as “sent”. This can be set only on invoices that have been approved
 	SentToContact bool `json:"SentToContact,omitempty"`
 
 	// Shown on sales invoices (Accounts Receivable) when this has been set
-	ExpectedPaymentDate string `json:"ExpectedPaymentDate,omitempty"`
+	ExpectedPaymentDate *Date `json:"ExpectedPaymentDate,omitempty"`
 
 	// Shown on bills (Accounts Payable) when this has been set
-	PlannedPaymentDate string `json:"PlannedPaymentDate,omitempty"`
+	PlannedPaymentDate *Date `json:"PlannedPaymentDate,omitempty"`
 
 	// Total of invoice excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal Decimal `json:"SubTotal,omitempty"`
 
 	// Total tax on invoice
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax Decimal `json:"TotalTax,omitempty"`
 
 	// Total of Invoice tax inclusive (i.e. SubTotal + TotalTax). This will be ignored if it doesn’t equal the sum of the LineAmounts
-	Total float64 `json:"Total,omitempty"`
+	Total Decimal `json:"Total,omitempty"`
 
 	// Total of discounts applied on the invoice line items
-	TotalDiscount float64 `json:"TotalDiscount,omitempty"`
+	TotalDiscount Decimal `json:"TotalDiscount,omitempty"`
 
 	// Xero generated unique identifier for invoice
 	InvoiceID string `json:"InvoiceID,omitempty"`
@@ -90,22 +92,32 @@ type Invoice struct {
 	Overpayments *[]Overpayment `json:"Overpayments,omitempty"`
 
 	// Amount remaining to be paid on invoice
-	AmountDue float64 `json:"AmountDue,omitempty"`
+	AmountDue Decimal `json:"AmountDue,omitempty"`
 
 	// Sum of payments received for invoice
-	AmountPaid float64 `json:"AmountPaid,omitempty"`
+	AmountPaid Decimal `json:"AmountPaid,omitempty"`
 
 	// The date the invoice was fully paid. Only returned on fully paid invoices
-	FullyPaidOnDate string `json:"FullyPaidOnDate,omitempty"`
+	FullyPaidOnDate *Date `json:"FullyPaidOnDate,omitempty"`
 
 	// Sum of all credit notes, over-payments and pre-payments applied to invoice
-	AmountCredited float64 `json:"AmountCredited,omitempty"`
+	AmountCredited Decimal `json:"AmountCredited,omitempty"`
 
 	// Last modified date UTC format
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Details of credit notes that have been applied to an invoice
 	CreditNotes *[]CreditNote `json:"CreditNotes,omitempty"`
+
+	// OK, WARNING or ERROR when the request was sent with summarizeErrors=false,
+	// see CreateAll
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the invoice returned with summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+
+	// Warnings of the invoice returned with summarizeErrors=false
+	Warnings []helpers.Warning `json:"Warnings,omitempty"`
 }
 
 //Invoices contains a collection of Invoices
@@ -113,20 +125,6 @@ type Invoices struct {
 	Invoices []Invoice `json:"Invoices"`
 }
 
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (i *Invoices) convertDates() error {
-	var err error
-	for n := len(i.Invoices) - 1; n >= 0; n-- {
-		i.Invoices[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(i.Invoices[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func unmarshalInvoice(invoiceResponseBytes []byte) (*Invoices, error) {
 	var invoiceResponse *Invoices
 	err := json.Unmarshal(invoiceResponseBytes, &invoiceResponse)
@@ -134,18 +132,35 @@ func unmarshalInvoice(invoiceResponseBytes []byte) (*Invoices, error) {
 		return nil, err
 	}
 
-	err = invoiceResponse.convertDates()
+	return invoiceResponse, err
+}
+
+// FindInvoices function will return the list of all the invoices tied to this
+// tenantID. Only the first page is returned, use NewInvoiceIterator or
+// FindAllInvoices for getting all of them
+func FindInvoices(cl *http.Client) (*Invoices, error) {
+	return FindInvoicesContext(context.Background(), cl)
+}
+
+// FindInvoicesContext is the same as FindInvoices but the request is bound to the given context
+func FindInvoicesContext(ctx context.Context, cl *http.Client) (*Invoices, error) {
+	invoiceResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, invoicePath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
+	return unmarshalInvoice(invoiceResponseBytes)
+}
 
-	return invoiceResponse, err
+// FindInvoicesModifiedSince will get the invoices modified after the given date, a zero
+// modifiedSince will not filter by date. Additional querystringParameters such as
+// where, page and order can be added as a map
+func FindInvoicesModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Invoices, error) {
+	return FindInvoicesModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
 }
 
-// FindInvoices function will return the list of all the invoices tied to this
-// tenantID
-func FindInvoices(cl *http.Client) (*Invoices, error) {
-	invoiceResponseBytes, err := helpers.Find(cl, invoiceURL, nil, nil)
+// FindInvoicesModifiedSinceContext is the same as FindInvoicesModifiedSince but the request is bound to the given context
+func FindInvoicesModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Invoices, error) {
+	invoiceResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, invoicePath), helpers.ModifiedSinceHeaders(modifiedSince), queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +169,12 @@ func FindInvoices(cl *http.Client) (*Invoices, error) {
 
 // FindInvoice function will return the invoice with the given criteria
 func FindInvoice(cl *http.Client, invoiceID uuid.UUID) (*Invoice, error) {
-	invoiceResponseBytes, err := helpers.Find(cl, invoiceURL+"/"+invoiceID.String(), nil, nil)
+	return FindInvoiceContext(context.Background(), cl, invoiceID)
+}
+
+// FindInvoiceContext is the same as FindInvoice but the request is bound to the given context
+func FindInvoiceContext(ctx context.Context, cl *http.Client, invoiceID uuid.UUID) (*Invoice, error) {
+	invoiceResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, invoicePath, invoiceID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -170,26 +190,267 @@ func FindInvoice(cl *http.Client, invoiceID uuid.UUID) (*Invoice, error) {
 
 // Create method will create a new invoice with the information given
 func (i *Invoices) Create(cl *http.Client) (*Invoices, error) {
+	return i.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (i *Invoices) CreateContext(ctx context.Context, cl *http.Client) (*Invoices, error) {
+	if err := validate(ctx, i); err != nil {
+		return nil, err
+	}
 	buf, err := json.Marshal(i)
 	if err != nil {
 		return nil, err
 	}
-	invoiceResponseBytes, err := helpers.Create(cl, invoiceURL, buf)
+	invoiceResponseBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, invoicePath), buf)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalInvoice(invoiceResponseBytes)
 }
 
+// InvoiceResult is the result of an element of Invoices.CreateAll or Invoices.UpdateAll,
+// either the invoice returned by Xero or the error that stopped it
+type InvoiceResult struct {
+	Invoice Invoice
+	Err     error
+}
+
+// CreateAll will create the invoices in requests of BatchSize elements
+// with summarizeErrors=false, so the valid ones are created even when others
+// are rejected. The results are in the order of the invoices, the error is
+// the first one failing a whole request
+func (i *Invoices) CreateAll(cl *http.Client) ([]InvoiceResult, error) {
+	return i.CreateAllContext(context.Background(), cl)
+}
+
+// CreateAllContext is the same as CreateAll but the requests are bound to the given context
+func (i *Invoices) CreateAllContext(ctx context.Context, cl *http.Client) ([]InvoiceResult, error) {
+	return i.sendAll(ctx, cl, false)
+}
+
+// UpdateAll is the same as CreateAll but the invoices with an ID are
+// updated instead of created
+func (i *Invoices) UpdateAll(cl *http.Client) ([]InvoiceResult, error) {
+	return i.UpdateAllContext(context.Background(), cl)
+}
+
+// UpdateAllContext is the same as UpdateAll but the requests are bound to the given context
+func (i *Invoices) UpdateAllContext(ctx context.Context, cl *http.Client) ([]InvoiceResult, error) {
+	return i.sendAll(ctx, cl, true)
+}
+
+func (i *Invoices) sendAll(ctx context.Context, cl *http.Client, update bool) ([]InvoiceResult, error) {
+	results := make([]InvoiceResult, len(i.Invoices))
+	sender := batch{
+		n: len(i.Invoices),
+		element: func(n int) validator {
+			return &i.Invoices[n]
+		},
+		payload: func(indexes []int) interface{} {
+			chunk := Invoices{}
+			for _, n := range indexes {
+				chunk.Invoices = append(chunk.Invoices, i.Invoices[n])
+			}
+			return chunk
+		},
+		decode: func(buf []byte, indexes []int) error {
+			var saved Invoices
+			if err := json.Unmarshal(buf, &saved); err != nil {
+				return err
+			}
+			for k, n := range indexes {
+				if k >= len(saved.Invoices) {
+					results[n].Err = errMissingElement(n)
+					continue
+				}
+				results[n].Invoice = saved.Invoices[k]
+				results[n].Err = elementError(n, saved.Invoices[k].StatusAttributeString, saved.Invoices[k].ValidationErrors)
+			}
+			return nil
+		},
+		fail: func(n int, err error) {
+			results[n] = InvoiceResult{Invoice: i.Invoices[n], Err: err}
+		},
+	}
+	return results, sender.send(ctx, cl, helpers.AccountingURL(ctx, invoicePath), update)
+}
+
 // Update will update the information with the given invoice
 func (i *Invoice) Update(cl *http.Client) (*Invoices, error) {
+	return i.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (i *Invoice) UpdateContext(ctx context.Context, cl *http.Client) (*Invoices, error) {
+	if err := validate(ctx, i); err != nil {
+		return nil, err
+	}
 	buf, err := json.Marshal(i)
 	if err != nil {
 		return nil, err
 	}
-	invoiceResponseBytes, err := helpers.Update(cl, invoiceURL+"/"+i.InvoiceID, buf)
+	invoiceResponseBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, invoicePath, i.InvoiceID), buf)
 	if err != nil {
 		return nil, err
 	}
 	return unmarshalInvoice(invoiceResponseBytes)
 }
+
+// Patch will update only the given fields of the invoice, e.g. Patch(cl, "Reference")
+// or Patch(cl, "DueDate"). The fields are sent even when they are zero, a
+// nil DueDate is sent as null
+func (i *Invoice) Patch(cl *http.Client, fields ...string) (*Invoices, error) {
+	return i.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (i *Invoice) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (*Invoices, error) {
+	buf, err := patchBody("Invoices", "InvoiceID", i, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, invoicePath, i.InvoiceID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalInvoice(buf)
+}
+
+// InvoiceIterator walks all the pages of invoices, use Next to advance and Invoice to get
+// the current one
+type InvoiceIterator struct {
+	pager
+	invoices []Invoice
+	current  Invoice
+}
+
+// NewInvoiceIterator will build an iterator over all the invoices matching the
+// given queryParameters (where, order...), when modifiedSince is not zero only
+// the invoices modified after it are returned
+func NewInvoiceIterator(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) *InvoiceIterator {
+	return &InvoiceIterator{
+		pager: newPager(ctx, cl, helpers.AccountingURL(ctx, invoicePath), modifiedSince, queryParameters),
+	}
+}
+
+// Next advances to the next invoice, fetching the next page when needed. It
+// returns false when there are no more invoices or an error happened, see Err
+func (it *InvoiceIterator) Next() bool {
+	for len(it.invoices) == 0 {
+		buf, ok := it.fetch()
+		if !ok {
+			return false
+		}
+		page, err := unmarshalInvoice(buf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.Invoices) == 0 {
+			return it.finish()
+		}
+		it.invoices = page.Invoices
+	}
+	it.current = it.invoices[0]
+	it.invoices = it.invoices[1:]
+	return true
+}
+
+// Invoice returns the current invoice
+func (it *InvoiceIterator) Invoice() *Invoice {
+	return &it.current
+}
+
+// FindAllInvoices will get the invoices of all the pages, see NewInvoiceIterator
+func FindAllInvoices(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Invoices, error) {
+	return FindAllInvoicesContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindAllInvoicesContext is the same as FindAllInvoices but the requests are bound to the given context
+func FindAllInvoicesContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Invoices, error) {
+	all := &Invoices{Invoices: []Invoice{}}
+	it := NewInvoiceIterator(ctx, cl, modifiedSince, queryParameters)
+	for it.Next() {
+		all.Invoices = append(all.Invoices, *it.Invoice())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// InvoiceType is the type of an invoice, ACCREC for sales invoices and ACCPAY for bills
+type InvoiceType string
+
+// The invoice types
+const (
+	InvoiceTypeAccPay InvoiceType = "ACCPAY"
+	InvoiceTypeAccRec InvoiceType = "ACCREC"
+)
+
+// IsValid reports whether the invoice type is one of the documented values
+func (v InvoiceType) IsValid() bool {
+	switch v {
+	case InvoiceTypeAccPay,
+		InvoiceTypeAccRec:
+		return true
+	}
+	return false
+}
+
+// InvoiceStatus is the status of an invoice or a credit note
+type InvoiceStatus string
+
+// The invoice statuses
+const (
+	InvoiceStatusDraft      InvoiceStatus = "DRAFT"
+	InvoiceStatusSubmitted  InvoiceStatus = "SUBMITTED"
+	InvoiceStatusDeleted    InvoiceStatus = "DELETED"
+	InvoiceStatusAuthorised InvoiceStatus = "AUTHORISED"
+	InvoiceStatusPaid       InvoiceStatus = "PAID"
+	InvoiceStatusVoided     InvoiceStatus = "VOIDED"
+)
+
+// IsValid reports whether the status is one of the documented values
+func (v InvoiceStatus) IsValid() bool {
+	switch v {
+	case InvoiceStatusDraft,
+		InvoiceStatusSubmitted,
+		InvoiceStatusDeleted,
+		InvoiceStatusAuthorised,
+		InvoiceStatusPaid,
+		InvoiceStatusVoided:
+		return true
+	}
+	return false
+}
+
+// Validate checks the invoice against the rules documented by Xero, the
+// required elements are only checked when creating it
+func (i *Invoice) Validate() error {
+	r := rules{}
+	if i.InvoiceID == "" {
+		r.required("Type", i.Type != "")
+		r.required("Contact", i.Contact.isReference())
+		r.required("LineItems", len(i.LineItems) > 0 || i.Status == InvoiceStatusDraft)
+	}
+	r.enum("Type", i.Type, i.Type != "")
+	r.enum("Status", i.Status, i.Status != "")
+	r.enum("LineAmountTypes", i.LineAmountTypes, i.LineAmountTypes != "")
+	r.maxLength("InvoiceNumber", i.InvoiceNumber, 255)
+	r.maxLength("Reference", i.Reference, 255)
+	if i.SentToContact && (i.Status == InvoiceStatusDraft || i.Status == InvoiceStatusSubmitted) {
+		r.add("SentToContact", "can only be set on approved invoices")
+	}
+	if i.Date != nil && i.DueDate != nil && !i.Date.IsZero() && i.DueDate.Before(i.Date.Time) {
+		r.add("DueDate", "can't be before the Date")
+	}
+	r.lineItems(i.LineItems, i.Type != InvoiceTypeAccPay)
+	return r.err()
+}
+
+// Validate checks every invoice of the collection
+func (i *Invoices) Validate() error {
+	return validateAll("Invoices", len(i.Invoices), func(n int) validator { return &i.Invoices[n] })
+}
@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -8,7 +9,7 @@ import (
 )
 
 const (
-	invoiceRemindersURL = "https://api.xero.com/api.xro/2.0/InvoiceReminders/Settings"
+	invoiceRemindersPath = "InvoiceReminders/Settings"
 )
 
 // InvoiceReminder will keep information about invoicing settings
@@ -23,7 +24,12 @@ type InvoiceReminders struct {
 
 // FindInvoiceReminders will get all the invoice reminders from Xero
 func FindInvoiceReminders(cl *http.Client) (ir *InvoiceReminders, err error) {
-	invoiceRemindersBytes, err := helpers.Find(cl, invoiceRemindersURL, nil, nil)
+	return FindInvoiceRemindersContext(context.Background(), cl)
+}
+
+// FindInvoiceRemindersContext is the same as FindInvoiceReminders but the request is bound to the given context
+func FindInvoiceRemindersContext(ctx context.Context, cl *http.Client) (ir *InvoiceReminders, err error) {
+	invoiceRemindersBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, invoiceRemindersPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -9,7 +10,7 @@ import (
 )
 
 const (
-	itemURL = "https://api.xero.com/api.xro/2.0/Items"
+	itemPath = "Items"
 )
 
 //Item is something that is sold or purchased.  It can have inventory tracked or not tracked.
@@ -46,16 +47,26 @@ type Item struct {
 	IsTrackedAsInventory bool `json:"IsTrackedAsInventory,omitempty"`
 
 	// The value of the item on hand. Calculated using average cost accounting.
-	TotalCostPool float64 `json:"TotalCostPool,omitempty"`
+	TotalCostPool Decimal `json:"TotalCostPool,omitempty"`
 
 	// The quantity of the item on hand
-	QuantityOnHand float64 `json:"QuantityOnHand,omitempty"`
+	QuantityOnHand Decimal `json:"QuantityOnHand,omitempty"`
 
 	// Last modified date in UTC format
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// The Xero identifier for an Item
 	ItemID string `json:"ItemID,omitempty"`
+
+	// OK, WARNING or ERROR when the request was sent with summarizeErrors=false,
+	// see CreateAll
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the item returned with summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+
+	// Warnings of the item returned with summarizeErrors=false
+	Warnings []helpers.Warning `json:"Warnings,omitempty"`
 }
 
 //Items is a collection of Items
@@ -66,7 +77,7 @@ type Items struct {
 //PurchaseAndSaleDetails are Elements for Purchases and Sales
 type PurchaseAndSaleDetails struct {
 	//Unit Price of the item. By default UnitPrice is returned to two decimal places.  You can use 4 decimal places by adding the unitdp=4 querystring parameter to your request.
-	UnitPrice float64 `json:"UnitPrice,omitempty"`
+	UnitPrice Decimal `json:"UnitPrice,omitempty"`
 
 	//Default account code to be used for purchased/sale. Not applicable to the purchase details of tracked items
 	AccountCode string `json:"AccountCode,omitempty"`
@@ -75,21 +86,7 @@ type PurchaseAndSaleDetails struct {
 	COGSAccountCode string `json:"COGSAccountCode,omitempty"`
 
 	//Used as an override if the default Tax Code for the selected AccountCode is not correct - see TaxTypes.
-	TaxType string `json:"TaxType,omitempty"`
-}
-
-//The Xero API returns Dates based on the .Net JSON date format available at the time of development
-//We need to convert these to a more usable format - RFC3339 for consistency with what the API expects to recieve
-func (i *Items) convertDates() error {
-	var err error
-	for n := len(i.Items) - 1; n >= 0; n-- {
-		i.Items[n].UpdatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(i.Items[n].UpdatedDateUTC, true)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	TaxType TaxType `json:"TaxType,omitempty"`
 }
 
 func unmarshalItem(itemResponseBytes []byte) (*Items, error) {
@@ -99,21 +96,24 @@ func unmarshalItem(itemResponseBytes []byte) (*Items, error) {
 		return nil, err
 	}
 
-	err = itemResponse.convertDates()
-	if err != nil {
-		return nil, err
-	}
-
 	return itemResponse, err
 }
 
 // Create will create items given an Items struct
 func (i *Items) Create(cl *http.Client) (*Items, error) {
+	return i.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (i *Items) CreateContext(ctx context.Context, cl *http.Client) (*Items, error) {
+	if err := validate(ctx, i); err != nil {
+		return nil, err
+	}
 	buf, err := json.Marshal(i)
 	if err != nil {
 		return nil, err
 	}
-	itemsResponseBytes, err := helpers.Create(cl, itemURL, buf)
+	itemsResponseBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, itemPath), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -121,9 +121,84 @@ func (i *Items) Create(cl *http.Client) (*Items, error) {
 	return unmarshalItem(itemsResponseBytes)
 }
 
+// ItemResult is the result of an element of Items.CreateAll or Items.UpdateAll,
+// either the item returned by Xero or the error that stopped it
+type ItemResult struct {
+	Item Item
+	Err  error
+}
+
+// CreateAll will create the items in requests of BatchSize elements
+// with summarizeErrors=false, so the valid ones are created even when others
+// are rejected. The results are in the order of the items, the error is
+// the first one failing a whole request
+func (i *Items) CreateAll(cl *http.Client) ([]ItemResult, error) {
+	return i.CreateAllContext(context.Background(), cl)
+}
+
+// CreateAllContext is the same as CreateAll but the requests are bound to the given context
+func (i *Items) CreateAllContext(ctx context.Context, cl *http.Client) ([]ItemResult, error) {
+	return i.sendAll(ctx, cl, false)
+}
+
+// UpdateAll is the same as CreateAll but the items with an ID are
+// updated instead of created
+func (i *Items) UpdateAll(cl *http.Client) ([]ItemResult, error) {
+	return i.UpdateAllContext(context.Background(), cl)
+}
+
+// UpdateAllContext is the same as UpdateAll but the requests are bound to the given context
+func (i *Items) UpdateAllContext(ctx context.Context, cl *http.Client) ([]ItemResult, error) {
+	return i.sendAll(ctx, cl, true)
+}
+
+func (i *Items) sendAll(ctx context.Context, cl *http.Client, update bool) ([]ItemResult, error) {
+	results := make([]ItemResult, len(i.Items))
+	sender := batch{
+		n: len(i.Items),
+		element: func(n int) validator {
+			return &i.Items[n]
+		},
+		payload: func(indexes []int) interface{} {
+			chunk := Items{}
+			for _, n := range indexes {
+				chunk.Items = append(chunk.Items, i.Items[n])
+			}
+			return chunk
+		},
+		decode: func(buf []byte, indexes []int) error {
+			var saved Items
+			if err := json.Unmarshal(buf, &saved); err != nil {
+				return err
+			}
+			for k, n := range indexes {
+				if k >= len(saved.Items) {
+					results[n].Err = errMissingElement(n)
+					continue
+				}
+				results[n].Item = saved.Items[k]
+				results[n].Err = elementError(n, saved.Items[k].StatusAttributeString, saved.Items[k].ValidationErrors)
+			}
+			return nil
+		},
+		fail: func(n int, err error) {
+			results[n] = ItemResult{Item: i.Items[n], Err: err}
+		},
+	}
+	return results, sender.send(ctx, cl, helpers.AccountingURL(ctx, itemPath), update)
+}
+
 // Update will update an item given an Items struct
 // This will only handle single item - you cannot update multiple items in a single call
 func (i *Item) Update(cl *http.Client) (*Items, error) {
+	return i.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (i *Item) UpdateContext(ctx context.Context, cl *http.Client) (*Items, error) {
+	if err := validate(ctx, i); err != nil {
+		return nil, err
+	}
 	its := Items{
 		Items: []Item{*i},
 	}
@@ -131,7 +206,7 @@ func (i *Item) Update(cl *http.Client) (*Items, error) {
 	if err != nil {
 		return nil, err
 	}
-	itemsResponseBytes, err := helpers.Update(cl, itemURL+"/"+i.ItemID, buf)
+	itemsResponseBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, itemPath, i.ItemID), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -139,9 +214,33 @@ func (i *Item) Update(cl *http.Client) (*Items, error) {
 	return unmarshalItem(itemsResponseBytes)
 }
 
+// Patch will update only the given fields of the item. Unlike Update the zero
+// values are sent, e.g. Patch(cl, "IsSold") with IsSold false stops selling it
+func (i *Item) Patch(cl *http.Client, fields ...string) (*Items, error) {
+	return i.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (i *Item) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (*Items, error) {
+	buf, err := patchBody("Items", "ItemID", i, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, itemPath, i.ItemID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalItem(buf)
+}
+
 // FindItems will get all items.
 func FindItems(cl *http.Client, additionalHeaders map[string]string, queryParameters map[string]string) (*Items, error) {
-	itemsResponseBytes, err := helpers.Find(cl, itemURL, additionalHeaders, queryParameters)
+	return FindItemsContext(context.Background(), cl, additionalHeaders, queryParameters)
+}
+
+// FindItemsContext is the same as FindItems but the request is bound to the given context
+func FindItemsContext(ctx context.Context, cl *http.Client, additionalHeaders map[string]string, queryParameters map[string]string) (*Items, error) {
+	itemsResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, itemPath), additionalHeaders, queryParameters)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +250,12 @@ func FindItems(cl *http.Client, additionalHeaders map[string]string, queryParame
 
 //FindItem will get a single item - itemID must be a GUID for an item
 func FindItem(cl *http.Client, itemID uuid.UUID) (*Item, error) {
-	itemsResponseBytes, err := helpers.Find(cl, itemURL+"/"+itemID.String(), nil, nil)
+	return FindItemContext(context.Background(), cl, itemID)
+}
+
+// FindItemContext is the same as FindItem but the request is bound to the given context
+func FindItemContext(ctx context.Context, cl *http.Client, itemID uuid.UUID) (*Item, error) {
+	itemsResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, itemPath, itemID.String()), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -165,10 +269,41 @@ func FindItem(cl *http.Client, itemID uuid.UUID) (*Item, error) {
 
 //RemoveItem will get a single item - itemID must be a GUID for an item
 func RemoveItem(cl *http.Client, itemID uuid.UUID) (*Items, error) {
-	itemsResponseBytes, err := helpers.Remove(cl, itemURL+"/"+itemID.String())
+	return RemoveItemContext(context.Background(), cl, itemID)
+}
+
+// RemoveItemContext is the same as RemoveItem but the request is bound to the given context
+func RemoveItemContext(ctx context.Context, cl *http.Client, itemID uuid.UUID) (*Items, error) {
+	itemsResponseBytes, err := helpers.RemoveContext(ctx, cl, helpers.AccountingURL(ctx, itemPath, itemID.String()))
 	if err != nil {
 		return nil, err
 	}
 
 	return unmarshalItem(itemsResponseBytes)
 }
+
+// Validate checks the item against the rules documented by Xero
+func (i *Item) Validate() error {
+	r := rules{}
+	if i.ItemID == "" {
+		r.required("Code", i.Code != "")
+	}
+	r.maxLength("Code", i.Code, 30)
+	r.maxLength("Name", i.Name, 50)
+	r.maxLength("Description", i.Description, 4000)
+	r.maxLength("PurchaseDescription", i.PurchaseDescription, 4000)
+	if i.InventoryAssetAccountCode != "" {
+		r.required("PurchaseDetails.COGSAccountCode", i.PurchaseDetails.COGSAccountCode != "")
+		if i.PurchaseDetails.AccountCode != "" {
+			r.add("PurchaseDetails.AccountCode", "is not applicable to tracked items")
+		}
+	} else if i.PurchaseDetails.COGSAccountCode != "" {
+		r.add("PurchaseDetails.COGSAccountCode", "is only applicable to tracked items")
+	}
+	return r.err()
+}
+
+// Validate checks every item of the collection
+func (i *Items) Validate() error {
+	return validateAll("Items", len(i.Items), func(n int) validator { return &i.Items[n] })
+}
@@ -0,0 +1,325 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	journalsPath    = "Journals"
+	offsetParameter = "offset"
+)
+
+// Journal is an entry of the general ledger, created by Xero for every
+// transaction posted. The journals are read only and numbered in the order
+// they were created
+type Journal struct {
+
+	// Xero identifier
+	JournalID string `json:"JournalID,omitempty"`
+
+	// Date the journal was posted
+	JournalDate *Date `json:"JournalDate,omitempty"`
+
+	// Xero generated journal number
+	JournalNumber int `json:"JournalNumber,omitempty"`
+
+	// Created date UTC format
+	CreatedDateUTC *DateTime `json:"CreatedDateUTC,omitempty"`
+
+	// reference field for additional indetifying information
+	Reference string `json:"Reference,omitempty"`
+
+	// The identifier for the source transaction (e.g. InvoiceID)
+	SourceID string `json:"SourceID,omitempty"`
+
+	// The journal source type. The type of transaction that created the journal
+	SourceType JournalSourceType `json:"SourceType,omitempty"`
+
+	// See JournalLines
+	JournalLines []JournalLine `json:"JournalLines,omitempty"`
+}
+
+// JournalLine is the amount posted to an account by a Journal
+type JournalLine struct {
+
+	// Xero identifier
+	JournalLineID string `json:"JournalLineID,omitempty"`
+
+	// See Accounts
+	AccountID string `json:"AccountID,omitempty"`
+
+	// See Accounts
+	AccountCode string `json:"AccountCode,omitempty"`
+
+	// See Account Types
+	AccountType AccountType `json:"AccountType,omitempty"`
+
+	// See AccountCodes
+	AccountName string `json:"AccountName,omitempty"`
+
+	// The description from the source transaction line item. Only returned if populated.
+	Description string `json:"Description,omitempty"`
+
+	// Net amount of journal line. This will be a positive value for a debit and negative for a credit
+	NetAmount Decimal `json:"NetAmount,omitempty"`
+
+	// Gross amount of journal line (NetAmount + TaxAmount).
+	GrossAmount Decimal `json:"GrossAmount,omitempty"`
+
+	// Total tax on a journal line
+	TaxAmount Decimal `json:"TaxAmount,omitempty"`
+
+	// see TaxTypes
+	TaxType TaxType `json:"TaxType,omitempty"`
+
+	// see TaxRates
+	TaxName string `json:"TaxName,omitempty"`
+
+	// Optional Tracking Category – see Tracking. Any JournalLine can have a maximum of 2 <TrackingCategory> elements.
+	TrackingCategories []TrackingCategory `json:"TrackingCategories,omitempty"`
+}
+
+// Journals is a collection of Journals
+type Journals struct {
+	Journals []Journal `json:"Journals"`
+}
+
+func unmarshalJournal(journalResponseBytes []byte) (*Journals, error) {
+	var journalResponse *Journals
+	err := json.Unmarshal(journalResponseBytes, &journalResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return journalResponse, err
+}
+
+// FindJournals will get up to 100 journals with a JournalNumber greater than
+// the given offset, 0 starts with the first journal. Additional
+// querystringParameters such as paymentsOnly (see Query.PaymentsOnly) can be
+// added as a map
+func FindJournals(cl *http.Client, offset int, queryParameters map[string]string) (*Journals, error) {
+	return FindJournalsContext(context.Background(), cl, offset, queryParameters)
+}
+
+// FindJournalsContext is the same as FindJournals but the request is bound to the given context
+func FindJournalsContext(ctx context.Context, cl *http.Client, offset int, queryParameters map[string]string) (*Journals, error) {
+	return FindJournalsModifiedSinceContext(ctx, cl, time.Time{}, offset, queryParameters)
+}
+
+// FindJournalsModifiedSince will get up to 100 journals created after the given date with
+// a JournalNumber greater than the given offset, a zero modifiedSince will not
+// filter by date
+func FindJournalsModifiedSince(cl *http.Client, modifiedSince time.Time, offset int, queryParameters map[string]string) (*Journals, error) {
+	return FindJournalsModifiedSinceContext(context.Background(), cl, modifiedSince, offset, queryParameters)
+}
+
+// FindJournalsModifiedSinceContext is the same as FindJournalsModifiedSince but the request is bound to the given context
+func FindJournalsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, offset int, queryParameters map[string]string) (*Journals, error) {
+	journalResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, journalsPath), helpers.ModifiedSinceHeaders(modifiedSince), offsetParameters(offset, queryParameters))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalJournal(journalResponseBytes)
+}
+
+// FindJournal will get a single journal - journalID must be a GUID for a journal
+func FindJournal(cl *http.Client, journalID uuid.UUID) (*Journal, error) {
+	return FindJournalContext(context.Background(), cl, journalID)
+}
+
+// FindJournalContext is the same as FindJournal but the request is bound to the given context
+func FindJournalContext(ctx context.Context, cl *http.Client, journalID uuid.UUID) (*Journal, error) {
+	journalResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, journalsPath, journalID.String()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	j, err := unmarshalJournal(journalResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(j.Journals) > 0 {
+		return &j.Journals[0], nil
+	}
+	return nil, nil
+}
+
+// offsetParameters copies the queryParameters adding the offset
+func offsetParameters(offset int, queryParameters map[string]string) map[string]string {
+	parameters := map[string]string{}
+	for key, value := range queryParameters {
+		parameters[key] = value
+	}
+	if offset > 0 {
+		parameters[offsetParameter] = strconv.Itoa(offset)
+	}
+	return parameters
+}
+
+// JournalIterator walks the journals in the order of their JournalNumber,
+// fetching them 100 at a time with the offset of the last one. Use Next to
+// advance, Journal to get the current one and Offset for resuming later
+type JournalIterator struct {
+	ctx             context.Context
+	cl              *http.Client
+	headers         map[string]string
+	queryParameters map[string]string
+	offset          int
+	journals        []Journal
+	current         Journal
+	done            bool
+	err             error
+}
+
+// NewJournalIterator will build an iterator over the journals with a
+// JournalNumber greater than the given offset, 0 starts with the first
+// journal. When modifiedSince is not zero only the journals created after it
+// are returned, queryParameters can hold paymentsOnly (see Query.PaymentsOnly)
+func NewJournalIterator(ctx context.Context, cl *http.Client, modifiedSince time.Time, offset int, queryParameters map[string]string) *JournalIterator {
+	return &JournalIterator{
+		ctx:             ctx,
+		cl:              cl,
+		headers:         helpers.ModifiedSinceHeaders(modifiedSince),
+		queryParameters: queryParameters,
+		offset:          offset,
+	}
+}
+
+// Next advances to the next journal, fetching the next ones when needed. It
+// returns false when there are no more journals or an error happened, see Err
+func (it *JournalIterator) Next() bool {
+	for len(it.journals) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		buf, err := helpers.FindContext(it.ctx, it.cl, helpers.AccountingURL(it.ctx, journalsPath), it.headers, offsetParameters(it.offset, it.queryParameters))
+		if err != nil {
+			it.err = err
+			return false
+		}
+		page, err := unmarshalJournal(buf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		// The last journal not moving forward would fetch the same ones forever
+		if len(page.Journals) == 0 || page.Journals[len(page.Journals)-1].JournalNumber <= it.offset {
+			it.done = true
+			return false
+		}
+		it.journals = page.Journals
+	}
+	it.current = it.journals[0]
+	it.journals = it.journals[1:]
+	it.offset = it.current.JournalNumber
+	return true
+}
+
+// Journal returns the current journal
+func (it *JournalIterator) Journal() *Journal {
+	return &it.current
+}
+
+// Offset returns the JournalNumber of the last journal returned by Next, or
+// the offset given to NewJournalIterator before the first one. Store it for
+// resuming the export exactly after that journal
+func (it *JournalIterator) Offset() int {
+	return it.offset
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *JournalIterator) Err() error {
+	return it.err
+}
+
+// FindAllJournals will get all the journals after the given offset, see
+// NewJournalIterator. The offset of the next export is the JournalNumber of
+// the last one
+func FindAllJournals(cl *http.Client, modifiedSince time.Time, offset int, queryParameters map[string]string) (*Journals, error) {
+	return FindAllJournalsContext(context.Background(), cl, modifiedSince, offset, queryParameters)
+}
+
+// FindAllJournalsContext is the same as FindAllJournals but the requests are bound to the given context
+func FindAllJournalsContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, offset int, queryParameters map[string]string) (*Journals, error) {
+	all := &Journals{Journals: []Journal{}}
+	it := NewJournalIterator(ctx, cl, modifiedSince, offset, queryParameters)
+	for it.Next() {
+		all.Journals = append(all.Journals, *it.Journal())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// JournalSourceType is the type of the transaction that created a journal
+type JournalSourceType string
+
+// The journal source types
+const (
+	JournalSourceTypeAccRec                 JournalSourceType = "ACCREC"
+	JournalSourceTypeAccPay                 JournalSourceType = "ACCPAY"
+	JournalSourceTypeAccRecCredit           JournalSourceType = "ACCRECCREDIT"
+	JournalSourceTypeAccPayCredit           JournalSourceType = "ACCPAYCREDIT"
+	JournalSourceTypeAccRecPayment          JournalSourceType = "ACCRECPAYMENT"
+	JournalSourceTypeAccPayPayment          JournalSourceType = "ACCPAYPAYMENT"
+	JournalSourceTypeARCreditPayment        JournalSourceType = "ARCREDITPAYMENT"
+	JournalSourceTypeAPCreditPayment        JournalSourceType = "APCREDITPAYMENT"
+	JournalSourceTypeCashRec                JournalSourceType = "CASHREC"
+	JournalSourceTypeCashPaid               JournalSourceType = "CASHPAID"
+	JournalSourceTypeTransfer               JournalSourceType = "TRANSFER"
+	JournalSourceTypeARPrepayment           JournalSourceType = "ARPREPAYMENT"
+	JournalSourceTypeAPPrepayment           JournalSourceType = "APPREPAYMENT"
+	JournalSourceTypeAROverpayment          JournalSourceType = "AROVERPAYMENT"
+	JournalSourceTypeAPOverpayment          JournalSourceType = "APOVERPAYMENT"
+	JournalSourceTypeExpClaim               JournalSourceType = "EXPCLAIM"
+	JournalSourceTypeExpPayment             JournalSourceType = "EXPPAYMENT"
+	JournalSourceTypeManJournal             JournalSourceType = "MANJOURNAL"
+	JournalSourceTypePayslip                JournalSourceType = "PAYSLIP"
+	JournalSourceTypeWagePayable            JournalSourceType = "WAGEPAYABLE"
+	JournalSourceTypeIntegratedPayrollPE    JournalSourceType = "INTEGRATEDPAYROLLPE"
+	JournalSourceTypeIntegratedPayrollPT    JournalSourceType = "INTEGRATEDPAYROLLPT"
+	JournalSourceTypeExternalSpendMoney     JournalSourceType = "EXTERNALSPENDMONEY"
+	JournalSourceTypeIntegratedPayrollPTPay JournalSourceType = "INTEGRATEDPAYROLLPTPAYMENT"
+	JournalSourceTypeIntegratedPayrollCN    JournalSourceType = "INTEGRATEDPAYROLLCN"
+)
+
+// IsValid reports whether the source type is one of the documented values
+func (v JournalSourceType) IsValid() bool {
+	switch v {
+	case JournalSourceTypeAccRec,
+		JournalSourceTypeAccPay,
+		JournalSourceTypeAccRecCredit,
+		JournalSourceTypeAccPayCredit,
+		JournalSourceTypeAccRecPayment,
+		JournalSourceTypeAccPayPayment,
+		JournalSourceTypeARCreditPayment,
+		JournalSourceTypeAPCreditPayment,
+		JournalSourceTypeCashRec,
+		JournalSourceTypeCashPaid,
+		JournalSourceTypeTransfer,
+		JournalSourceTypeARPrepayment,
+		JournalSourceTypeAPPrepayment,
+		JournalSourceTypeAROverpayment,
+		JournalSourceTypeAPOverpayment,
+		JournalSourceTypeExpClaim,
+		JournalSourceTypeExpPayment,
+		JournalSourceTypeManJournal,
+		JournalSourceTypePayslip,
+		JournalSourceTypeWagePayable,
+		JournalSourceTypeIntegratedPayrollPE,
+		JournalSourceTypeIntegratedPayrollPT,
+		JournalSourceTypeExternalSpendMoney,
+		JournalSourceTypeIntegratedPayrollPTPay,
+		JournalSourceTypeIntegratedPayrollCN:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package accounting
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+)
+
+func TestJournalIterator(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	// every fifth journal is the one of a payment
+	for n := 0; n < 250; n++ {
+		sourceType := JournalSourceTypeAccRec
+		if n%5 == 4 {
+			sourceType = JournalSourceTypeAccRecPayment
+		}
+		if err := s.Seed(tenantID, "Journals", Journal{SourceType: sourceType, Reference: "Journal"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cl := s.Client(tenantID)
+	ctx := helpers.WithEndpoints(context.Background(), s.Endpoints())
+
+	tests := []struct {
+		name            string
+		offset          int
+		queryParameters map[string]string
+		want            []int
+		wantRequests    int
+	}{
+		{name: "from the start", offset: 0, want: []int{1, 250}, wantRequests: 4},
+		{name: "resumed", offset: 120, want: []int{121, 250}, wantRequests: 3},
+		{name: "after the last one", offset: 250, want: nil, wantRequests: 1},
+		{name: "payments only", offset: 200, queryParameters: NewQuery().PaymentsOnly().Params(), want: []int{205, 250}, wantRequests: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := len(s.Requests())
+			it := NewJournalIterator(ctx, cl, time.Time{}, tt.offset, tt.queryParameters)
+			var numbers []int
+			for it.Next() {
+				if len(numbers) > 0 && it.Journal().JournalNumber <= numbers[len(numbers)-1] {
+					t.Fatalf("journal %d after %d", it.Journal().JournalNumber, numbers[len(numbers)-1])
+				}
+				numbers = append(numbers, it.Journal().JournalNumber)
+				if it.Offset() != it.Journal().JournalNumber {
+					t.Fatalf("Offset() = %d, want %d", it.Offset(), it.Journal().JournalNumber)
+				}
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("Err() = %v", err)
+			}
+			var got []int
+			if len(numbers) > 0 {
+				got = []int{numbers[0], numbers[len(numbers)-1]}
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && (got[0] != tt.want[0] || got[1] != tt.want[1])) {
+				t.Errorf("journals %v, want %v", got, tt.want)
+			}
+			if len(numbers) == 0 && it.Offset() != tt.offset {
+				t.Errorf("Offset() = %d, want %d", it.Offset(), tt.offset)
+			}
+			if got := len(s.Requests()) - sent; got != tt.wantRequests {
+				t.Errorf("%d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestJournalIteratorError(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	for n := 0; n < 150; n++ {
+		if err := s.Seed(tenantID, "Journals", Journal{SourceType: JournalSourceTypeAccRec}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cl := s.Client(tenantID)
+	ctx := helpers.WithEndpoints(context.Background(), s.Endpoints())
+
+	// the second page fails, the offset is the one to resume the export with
+	it := NewJournalIterator(ctx, cl, time.Time{}, 0, nil)
+	for n := 0; n < 100; n++ {
+		if !it.Next() {
+			t.Fatalf("Next() = false after %d journals, err %v", n, it.Err())
+		}
+	}
+	s.Fail(xerotest.Failure{Path: "Journals", StatusCode: http.StatusBadRequest})
+	if it.Next() {
+		t.Fatalf("Next() = true, want the failure")
+	}
+	if it.Err() == nil || it.Offset() != 100 {
+		t.Errorf("Err() = %v, Offset() = %d, want an error at 100", it.Err(), it.Offset())
+	}
+	all, err := FindAllJournalsContext(ctx, cl, time.Time{}, it.Offset(), nil)
+	if err != nil {
+		t.Fatalf("FindAllJournalsContext() error = %v", err)
+	}
+	if len(all.Journals) != 50 || all.Journals[0].JournalNumber != 101 {
+		t.Errorf("FindAllJournalsContext() = %d journals, want the 50 after 100", len(all.Journals))
+	}
+}
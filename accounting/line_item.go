@@ -1,5 +1,7 @@
 package accounting
 
+import "fmt"
+
 //LineItem is a line containing detail on an Invoice
 type LineItem struct {
 	//The Xero generated identifier for a LineItem. It is recommended that you include LineItemIDs on update requests. If LineItemIDs are not included with line items in an update request then the line items are deleted and recreated.
@@ -9,10 +11,10 @@ type LineItem struct {
 	Description string `json:"Description,omitempty"`
 
 	// LineItem Quantity
-	Quantity float64 `json:"Quantity,omitempty"`
+	Quantity Decimal `json:"Quantity,omitempty"`
 
 	// LineItem Unit Amount
-	UnitAmount float64 `json:"UnitAmount,omitempty"`
+	UnitAmount Decimal `json:"UnitAmount,omitempty"`
 
 	// See Items
 	ItemCode string `json:"ItemCode,omitempty"`
@@ -21,23 +23,98 @@ type LineItem struct {
 	AccountCode string `json:"AccountCode,omitempty"`
 
 	// Used as an override if the default Tax Code for the selected <AccountCode> is not correct – see TaxTypes.
-	TaxType string `json:"TaxType,omitempty"`
+	TaxType TaxType `json:"TaxType,omitempty"`
 
 	// The tax amount is auto calculated as a percentage of the line amount (see below) based on the tax rate. This value can be overriden if the calculated <TaxAmount> is not correct.
-	TaxAmount float64 `json:"TaxAmount,omitempty"`
+	TaxAmount Decimal `json:"TaxAmount,omitempty"`
 
 	// If you wish to omit either of the <Quantity> or <UnitAmount> you can provide a LineAmount and Xero will calculate the missing amount for you. The line amount reflects the discounted price if a DiscountRate has been used . i.e LineAmount = Quantity * Unit Amount * ((100 – DiscountRate)/100)
-	LineAmount float64 `json:"LineAmount,omitempty"`
+	LineAmount Decimal `json:"LineAmount,omitempty"`
 
 	// Optional Tracking Category – see Tracking.  Any LineItem can have a maximum of 2 <TrackingCategory> elements.
 	Tracking []TrackingCategory `json:"Tracking,omitempty"`
 
 	// Percentage discount being applied to a line item (only supported on ACCREC invoices – ACC PAY invoices and credit notes in Xero do not support discounts
-	DiscountRate float64 `json:"DiscountRate,omitempty"`
+	DiscountRate Decimal `json:"DiscountRate,omitempty"`
 
 	// The discount amount being applied to a line item (only supported on ACCREC invoices – ACC PAY invoices and credit notes in Xero do not support discounts
-	DiscountAmount float64 `json:"DiscountAmount,omitempty"`
+	DiscountAmount Decimal `json:"DiscountAmount,omitempty"`
 
 	// The Xero identifier for a Repeating Invoicee.g. 297c2dc5-cc47-4afd-8ec8-74990b8761e9
 	RepeatingInvoiceID string `json:"RepeatingInvoiceID,omitempty"`
 }
+
+// CalculatedLineAmount returns the LineAmount Xero calculates for the line,
+// Quantity * UnitAmount * ((100 - DiscountRate) / 100) less the
+// DiscountAmount, rounded to MoneyPlaces
+func (l *LineItem) CalculatedLineAmount() (Decimal, error) {
+	amount, err := l.Quantity.Mul(l.UnitAmount)
+	if err != nil {
+		return "", err
+	}
+	if !l.DiscountRate.IsZero() {
+		rate, err := Decimal("100").Sub(l.DiscountRate)
+		if err != nil {
+			return "", err
+		}
+		if amount, err = amount.Mul(rate); err != nil {
+			return "", err
+		}
+		if amount, err = amount.Div("100", UnitPlaces+MoneyPlaces, RoundHalfUp); err != nil {
+			return "", err
+		}
+	}
+	if amount, err = amount.Sub(l.DiscountAmount); err != nil {
+		return "", err
+	}
+	return amount.Round(MoneyPlaces, RoundHalfUp)
+}
+
+// LineAmountType tells if the line amounts of a document include the taxes
+type LineAmountType string
+
+// The line amount types
+const (
+	LineAmountTypeExclusive LineAmountType = "Exclusive"
+	LineAmountTypeInclusive LineAmountType = "Inclusive"
+	LineAmountTypeNoTax     LineAmountType = "NoTax"
+)
+
+// IsValid reports whether the line amount type is one of the documented values
+func (v LineAmountType) IsValid() bool {
+	switch v {
+	case LineAmountTypeExclusive,
+		LineAmountTypeInclusive,
+		LineAmountTypeNoTax:
+		return true
+	}
+	return false
+}
+
+// Validate checks the line item against the rules documented by Xero
+func (l *LineItem) Validate() error {
+	r := rules{}
+	r.required("Description", l.Description != "" || l.ItemCode != "")
+	r.maxLength("Description", l.Description, 4000)
+	if len(l.Tracking) > 2 {
+		r.add("Tracking", "can't have more than 2 tracking categories")
+	}
+	if cmp, err := l.DiscountRate.Cmp("100"); err != nil {
+		r.add("DiscountRate", "is not a number")
+	} else if l.DiscountRate.Sign() < 0 || cmp > 0 {
+		r.add("DiscountRate", "must be between 0 and 100")
+	}
+	return r.err()
+}
+
+// lineItems validates the lines of a document, the discounts are only
+// allowed when discounts is true
+func (r *rules) lineItems(lineItems []LineItem, discounts bool) {
+	for n := range lineItems {
+		field := fmt.Sprintf("LineItems[%d]", n)
+		r.nested(field, lineItems[n].Validate())
+		if !discounts && (!lineItems[n].DiscountRate.IsZero() || !lineItems[n].DiscountAmount.IsZero()) {
+			r.add(field+".DiscountRate", "discounts are only supported on ACCREC invoices")
+		}
+	}
+}
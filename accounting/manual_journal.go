@@ -0,0 +1,347 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	manualJournalsPath = "ManualJournals"
+)
+
+// ManualJournal is a journal posted by hand to the general ledger, e.g. an
+// accrual at the end of the month
+type ManualJournal struct {
+
+	// Description of journal being posted (required)
+	Narration string `json:"Narration,omitempty"`
+
+	// See JournalLines, at least two lines balancing to zero
+	JournalLines []ManualJournalLine `json:"JournalLines,omitempty"`
+
+	// Date journal was posted – YYYY-MM-DD
+	Date *Date `json:"Date,omitempty"`
+
+	// Line amounts are exclusive of tax by default if you don’t specify this element. See Line Amount Types
+	LineAmountTypes LineAmountType `json:"LineAmountTypes,omitempty"`
+
+	// See Manual Journal Status Codes
+	Status ManualJournalStatus `json:"Status,omitempty"`
+
+	// Url link to a source document – shown as “Go to [appName]” in the Xero app
+	URL string `json:"Url,omitempty"`
+
+	// Boolean – default is true if not specified, set it to false for hiding
+	// the journal on the cash basis reports
+	ShowOnCashBasisReports *bool `json:"ShowOnCashBasisReports,omitempty"`
+
+	// Boolean to indicate if a manual journal has an attachment
+	HasAttachments bool `json:"HasAttachments,omitempty"`
+
+	// Last modified date UTC format
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
+
+	// The Xero identifier for a Manual Journal
+	ManualJournalID string `json:"ManualJournalID,omitempty"`
+}
+
+// ManualJournalLine is a debit (positive amount) or a credit (negative
+// amount) of a ManualJournal
+type ManualJournalLine struct {
+
+	// total for line. Debits are positive, credits are negative value
+	LineAmount Decimal `json:"LineAmount"`
+
+	// See Accounts
+	AccountCode string `json:"AccountCode,omitempty"`
+
+	// The Xero identifier of the account, it can be used instead of the AccountCode
+	AccountID string `json:"AccountID,omitempty"`
+
+	// Description for journal line
+	Description string `json:"Description,omitempty"`
+
+	// Used as an override if the default Tax Code for the selected <AccountCode> is not correct – see TaxTypes.
+	TaxType TaxType `json:"TaxType,omitempty"`
+
+	// Optional Tracking Category – see Tracking. Any JournalLine can have a maximum of 2 <TrackingCategory> elements.
+	Tracking []TrackingCategory `json:"Tracking,omitempty"`
+
+	// The calculated tax amount based on the TaxType and LineAmount
+	TaxAmount Decimal `json:"TaxAmount,omitempty"`
+
+	// Is the line blank (read only)
+	IsBlank bool `json:"IsBlank,omitempty"`
+}
+
+// ManualJournals is a collection of ManualJournals
+type ManualJournals struct {
+	ManualJournals []ManualJournal `json:"ManualJournals"`
+}
+
+func unmarshalManualJournal(manualJournalResponseBytes []byte) (*ManualJournals, error) {
+	var manualJournalResponse *ManualJournals
+	err := json.Unmarshal(manualJournalResponseBytes, &manualJournalResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return manualJournalResponse, err
+}
+
+// FindManualJournals will get a page of manual journals. Additional
+// querystringParameters such as where, page and order can be added as a map
+func FindManualJournals(cl *http.Client, queryParameters map[string]string) (*ManualJournals, error) {
+	return FindManualJournalsContext(context.Background(), cl, queryParameters)
+}
+
+// FindManualJournalsContext is the same as FindManualJournals but the request is bound to the given context
+func FindManualJournalsContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*ManualJournals, error) {
+	return FindManualJournalsModifiedSinceContext(ctx, cl, time.Time{}, queryParameters)
+}
+
+// FindManualJournalsModifiedSince will get the manual journals modified after the given date, a zero
+// modifiedSince will not filter by date. Additional querystringParameters such as
+// where, page and order can be added as a map
+func FindManualJournalsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*ManualJournals, error) {
+	return FindManualJournalsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindManualJournalsModifiedSinceContext is the same as FindManualJournalsModifiedSince but the request is bound to the given context
+func FindManualJournalsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*ManualJournals, error) {
+	manualJournalResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, manualJournalsPath), helpers.ModifiedSinceHeaders(modifiedSince), queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalManualJournal(manualJournalResponseBytes)
+}
+
+// FindManualJournal will get a single manual journal - manualJournalID must be a GUID for a manual journal
+func FindManualJournal(cl *http.Client, manualJournalID uuid.UUID) (*ManualJournal, error) {
+	return FindManualJournalContext(context.Background(), cl, manualJournalID)
+}
+
+// FindManualJournalContext is the same as FindManualJournal but the request is bound to the given context
+func FindManualJournalContext(ctx context.Context, cl *http.Client, manualJournalID uuid.UUID) (*ManualJournal, error) {
+	manualJournalResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, manualJournalsPath, manualJournalID.String()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	m, err := unmarshalManualJournal(manualJournalResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.ManualJournals) > 0 {
+		return &m.ManualJournals[0], nil
+	}
+	return nil, nil
+}
+
+// Create will create the given manual journals. Their balance is only
+// checked with WithValidation, otherwise Xero rejects an unbalanced journal
+// with a generic validation error, so callers not enabling it should check
+// that Balance is zero before
+func (m *ManualJournals) Create(cl *http.Client) (*ManualJournals, error) {
+	return m.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (m *ManualJournals) CreateContext(ctx context.Context, cl *http.Client) (*ManualJournals, error) {
+	if err := validate(ctx, m); err != nil {
+		return nil, err
+	}
+	buf, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	manualJournalResponseBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, manualJournalsPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalManualJournal(manualJournalResponseBytes)
+}
+
+// Update will update the manual journal, whose lines must balance like the
+// ones of Create
+func (m *ManualJournal) Update(cl *http.Client) (*ManualJournals, error) {
+	return m.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (m *ManualJournal) UpdateContext(ctx context.Context, cl *http.Client) (*ManualJournals, error) {
+	if err := validate(ctx, m); err != nil {
+		return nil, err
+	}
+	mj := ManualJournals{
+		ManualJournals: []ManualJournal{*m},
+	}
+	buf, err := json.Marshal(mj)
+	if err != nil {
+		return nil, err
+	}
+	manualJournalResponseBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, manualJournalsPath, m.ManualJournalID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalManualJournal(manualJournalResponseBytes)
+}
+
+// Patch will update only the given fields of the manual journal, e.g.
+// Patch(cl, "Narration"). A nil ShowOnCashBasisReports is sent as null
+func (m *ManualJournal) Patch(cl *http.Client, fields ...string) (*ManualJournals, error) {
+	return m.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (m *ManualJournal) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (*ManualJournals, error) {
+	buf, err := patchBody("ManualJournals", "ManualJournalID", m, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, manualJournalsPath, m.ManualJournalID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalManualJournal(buf)
+}
+
+// Balance returns the sum of the line amounts of the journal lines, zero when
+// the debits and the credits balance
+func (m *ManualJournal) Balance() (Decimal, error) {
+	amounts := make([]Decimal, 0, len(m.JournalLines))
+	for _, line := range m.JournalLines {
+		amounts = append(amounts, line.LineAmount)
+	}
+	return Sum(amounts...)
+}
+
+// ManualJournalIterator walks all the pages of manual journals, use Next to advance and ManualJournal to get
+// the current one
+type ManualJournalIterator struct {
+	pager
+	manualJournals []ManualJournal
+	current        ManualJournal
+}
+
+// NewManualJournalIterator will build an iterator over all the manual journals matching the
+// given queryParameters (where, order...), when modifiedSince is not zero only
+// the manual journals modified after it are returned
+func NewManualJournalIterator(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) *ManualJournalIterator {
+	return &ManualJournalIterator{
+		pager: newPager(ctx, cl, helpers.AccountingURL(ctx, manualJournalsPath), modifiedSince, queryParameters),
+	}
+}
+
+// Next advances to the next manual journal, fetching the next page when needed. It
+// returns false when there are no more manual journals or an error happened, see Err
+func (it *ManualJournalIterator) Next() bool {
+	for len(it.manualJournals) == 0 {
+		buf, ok := it.fetch()
+		if !ok {
+			return false
+		}
+		page, err := unmarshalManualJournal(buf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.ManualJournals) == 0 {
+			return it.finish()
+		}
+		it.manualJournals = page.ManualJournals
+	}
+	it.current = it.manualJournals[0]
+	it.manualJournals = it.manualJournals[1:]
+	return true
+}
+
+// ManualJournal returns the current manual journal
+func (it *ManualJournalIterator) ManualJournal() *ManualJournal {
+	return &it.current
+}
+
+// FindAllManualJournals will get the manual journals of all the pages, see NewManualJournalIterator
+func FindAllManualJournals(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*ManualJournals, error) {
+	return FindAllManualJournalsContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindAllManualJournalsContext is the same as FindAllManualJournals but the requests are bound to the given context
+func FindAllManualJournalsContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*ManualJournals, error) {
+	all := &ManualJournals{ManualJournals: []ManualJournal{}}
+	it := NewManualJournalIterator(ctx, cl, modifiedSince, queryParameters)
+	for it.Next() {
+		all.ManualJournals = append(all.ManualJournals, *it.ManualJournal())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// ManualJournalStatus is the status of a manual journal
+type ManualJournalStatus string
+
+// The manual journal statuses
+const (
+	ManualJournalStatusDraft    ManualJournalStatus = "DRAFT"
+	ManualJournalStatusPosted   ManualJournalStatus = "POSTED"
+	ManualJournalStatusDeleted  ManualJournalStatus = "DELETED"
+	ManualJournalStatusVoided   ManualJournalStatus = "VOIDED"
+	ManualJournalStatusArchived ManualJournalStatus = "ARCHIVED"
+)
+
+// IsValid reports whether the status is one of the documented values
+func (v ManualJournalStatus) IsValid() bool {
+	switch v {
+	case ManualJournalStatusDraft,
+		ManualJournalStatusPosted,
+		ManualJournalStatusDeleted,
+		ManualJournalStatusVoided,
+		ManualJournalStatusArchived:
+		return true
+	}
+	return false
+}
+
+// Validate checks the manual journal against the rules documented by Xero
+func (m *ManualJournal) Validate() error {
+	r := rules{}
+	if m.ManualJournalID == "" {
+		r.required("Narration", m.Narration != "")
+		if len(m.JournalLines) < 2 {
+			r.add("JournalLines", "must have at least 2 lines")
+		}
+	}
+	r.enum("Status", m.Status, m.Status != "")
+	r.enum("LineAmountTypes", m.LineAmountTypes, m.LineAmountTypes != "")
+	for n := range m.JournalLines {
+		r.nested(fmt.Sprintf("JournalLines[%d]", n), m.JournalLines[n].Validate())
+	}
+	if total, err := m.Balance(); err != nil {
+		r.add("JournalLines", "must have valid line amounts")
+	} else if len(m.JournalLines) > 0 && !total.IsZero() {
+		r.add("JournalLines", "must balance to zero, they are off by %s", total)
+	}
+	return r.err()
+}
+
+// Validate checks the manual journal line against the rules documented by Xero
+func (l *ManualJournalLine) Validate() error {
+	r := rules{}
+	r.required("AccountCode", l.AccountCode != "" || l.AccountID != "")
+	if len(l.Tracking) > 2 {
+		r.add("Tracking", "can't have more than 2 tracking categories")
+	}
+	return r.err()
+}
+
+// Validate checks all the manual journals of the collection
+func (m *ManualJournals) Validate() error {
+	return validateAll("ManualJournals", len(m.ManualJournals), func(n int) validator { return &m.ManualJournals[n] })
+}
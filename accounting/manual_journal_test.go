@@ -0,0 +1,77 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+)
+
+func journalLines(amounts ...Decimal) []ManualJournalLine {
+	lines := make([]ManualJournalLine, len(amounts))
+	for n, amount := range amounts {
+		lines[n] = ManualJournalLine{AccountCode: "200", LineAmount: amount}
+	}
+	return lines
+}
+
+func TestManualJournalBalance(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []ManualJournalLine
+		want    Decimal
+		wantErr bool
+	}{
+		{name: "no lines", want: "0"},
+		{name: "balanced", lines: journalLines("100.10", "-60", "-40.10"), want: "0"},
+		{name: "debits over", lines: journalLines("100", "-99.99"), want: "0.01"},
+		{name: "credits over", lines: journalLines("0.1", "0.2", "-0.4"), want: "-0.1"},
+		{name: "exact", lines: journalLines("0.1", "0.2", "-0.3"), want: "0"},
+		{name: "invalid amount", lines: journalLines("100", "ten"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := ManualJournal{JournalLines: tt.lines}
+			got, err := m.Balance()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Balance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("Balance() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManualJournalCreate(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	cl := s.Client(tenantID)
+	ctx := WithValidation(helpers.WithEndpoints(context.Background(), s.Endpoints()))
+
+	unbalanced := ManualJournals{ManualJournals: []ManualJournal{
+		{Narration: "Accrual", JournalLines: journalLines("100", "-90")},
+	}}
+	_, err := unbalanced.CreateContext(ctx, cl)
+	if !errors.Is(err, helpers.ErrValidation) || !strings.Contains(err.Error(), "off by 10") {
+		t.Errorf("CreateContext() error = %v, want the journal off by 10", err)
+	}
+	if got := len(s.Records(tenantID, "ManualJournals")); got != 0 {
+		t.Errorf("%d manual journals saved, want none", got)
+	}
+
+	balanced := ManualJournals{ManualJournals: []ManualJournal{
+		{Narration: "Accrual", JournalLines: journalLines("100", "-90", "-10")},
+	}}
+	created, err := balanced.CreateContext(ctx, cl)
+	if err != nil {
+		t.Fatalf("CreateContext() error = %v", err)
+	}
+	if len(created.ManualJournals) != 1 || created.ManualJournals[0].ManualJournalID == "" || len(created.ManualJournals[0].JournalLines) != 3 {
+		t.Errorf("CreateContext() = %+v, want the journal with its 3 lines", created)
+	}
+}
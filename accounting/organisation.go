@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -8,7 +9,7 @@ import (
 )
 
 const (
-	organisationURL = "https://api.xero.com/api.xro/2.0/Organisations"
+	organisationPath = "Organisations"
 )
 
 //Organisation is information about a Xero organisation
@@ -69,13 +70,13 @@ type Organisation struct {
 	DefaultPurchasesTax string `json:"DefaultPurchasesTax,omitempty"`
 
 	// Shown if set. See lock dates
-	PeriodLockDate string `json:"PeriodLockDate,omitempty"`
+	PeriodLockDate *Date `json:"PeriodLockDate,omitempty"`
 
 	// Shown if set. See lock dates
-	EndOfYearLockDate string `json:"EndOfYearLockDate,omitempty"`
+	EndOfYearLockDate *Date `json:"EndOfYearLockDate,omitempty"`
 
 	// Timestamp when the organisation was created in Xero
-	CreatedDateUTC string `json:"CreatedDateUTC,omitempty"`
+	CreatedDateUTC *DateTime `json:"CreatedDateUTC,omitempty"`
 
 	// Timezone specifications
 	Timezone string `json:"Timezone,omitempty"`
@@ -109,7 +110,12 @@ type OrganisationCollection struct {
 
 // FindOrganisations will get all the organisation linked to the given tenantID
 func FindOrganisations(cl *http.Client) (org *OrganisationCollection, err error) {
-	organisationBytes, err := helpers.Find(cl, organisationURL, nil, nil)
+	return FindOrganisationsContext(context.Background(), cl)
+}
+
+// FindOrganisationsContext is the same as FindOrganisations but the request is bound to the given context
+func FindOrganisationsContext(ctx context.Context, cl *http.Client) (org *OrganisationCollection, err error) {
+	organisationBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, organisationPath), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -117,15 +123,5 @@ func FindOrganisations(cl *http.Client) (org *OrganisationCollection, err error)
 	if err != nil {
 		return nil, err
 	}
-	for index := range org.Organisations {
-		org.Organisations[index].PeriodLockDate, err = helpers.DotNetJSONTimeToRFC3339(org.Organisations[index].PeriodLockDate, false)
-		if err != nil {
-			return nil, err
-		}
-		org.Organisations[index].CreatedDateUTC, err = helpers.DotNetJSONTimeToRFC3339(org.Organisations[index].CreatedDateUTC, true)
-		if err != nil {
-			return nil, err
-		}
-	}
 	return org, nil
 }
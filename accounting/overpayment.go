@@ -1,13 +1,27 @@
 package accounting
 
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	overpaymentsPath = "Overpayments"
+)
+
 //Overpayment is used when a debtor overpays an invoice
 type Overpayment struct {
 
 	// See Overpayment Types
-	Type string `json:"Type,omitempty"`
+	Type BankTransactionType `json:"Type,omitempty"`
 
 	// The date the overpayment is created YYYY-MM-DD
-	Date string `json:"DateString,omitempty"`
+	Date *Date `json:"DateString,omitempty"`
 
 	// See Contacts
 	Contact Contact `json:"Contact"`
@@ -16,22 +30,22 @@ type Overpayment struct {
 	Status string `json:"Status,omitempty"`
 
 	// See Overpayment Line Amount Types
-	LineAmountTypes string `json:"LineAmountTypes,omitempty"`
+	LineAmountTypes LineAmountType `json:"LineAmountTypes,omitempty"`
 
 	// See Overpayment Line Items
 	LineItems []LineItem `json:"LineItems,omitempty"`
 
 	// The subtotal of the overpayment excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal Decimal `json:"SubTotal,omitempty"`
 
 	// The total tax on the overpayment
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax Decimal `json:"TotalTax,omitempty"`
 
 	// The total of the overpayment (subtotal + total tax)
-	Total float64 `json:"Total,omitempty"`
+	Total Decimal `json:"Total,omitempty"`
 
 	// UTC timestamp of last update to the overpayment
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Currency used for the overpayment
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
@@ -40,10 +54,10 @@ type Overpayment struct {
 	OverpaymentID string `json:"OverpaymentID,omitempty"`
 
 	// The currency rate for a multicurrency overpayment. If no rate is specified, the XE.com day rate is used
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate Decimal `json:"CurrencyRate,omitempty"`
 
 	// The remaining credit balance on the overpayment
-	RemainingCredit float64 `json:"RemainingCredit,omitempty"`
+	RemainingCredit Decimal `json:"RemainingCredit,omitempty"`
 
 	// See Allocations
 	Allocations []Allocation `json:"Allocations,omitempty"`
@@ -59,3 +73,147 @@ type Overpayment struct {
 type Overpayments struct {
 	Overpayments []Overpayment `json:"Overpayments"`
 }
+
+func unmarshalOverpayment(overpaymentResponseBytes []byte) (*Overpayments, error) {
+	var overpaymentResponse *Overpayments
+	err := json.Unmarshal(overpaymentResponseBytes, &overpaymentResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return overpaymentResponse, err
+}
+
+// FindOverpayments will get a page of overpayments. Additional querystringParameters
+// such as where, page and order can be added as a map
+func FindOverpayments(cl *http.Client, queryParameters map[string]string) (*Overpayments, error) {
+	return FindOverpaymentsContext(context.Background(), cl, queryParameters)
+}
+
+// FindOverpaymentsContext is the same as FindOverpayments but the request is bound to the given context
+func FindOverpaymentsContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*Overpayments, error) {
+	return FindOverpaymentsModifiedSinceContext(ctx, cl, time.Time{}, queryParameters)
+}
+
+// FindOverpaymentsModifiedSince will get the overpayments modified after the given date, a zero
+// modifiedSince will not filter by date. Additional querystringParameters such as
+// where, page and order can be added as a map
+func FindOverpaymentsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Overpayments, error) {
+	return FindOverpaymentsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindOverpaymentsModifiedSinceContext is the same as FindOverpaymentsModifiedSince but the request is bound to the given context
+func FindOverpaymentsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Overpayments, error) {
+	overpaymentResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, overpaymentsPath), helpers.ModifiedSinceHeaders(modifiedSince), queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalOverpayment(overpaymentResponseBytes)
+}
+
+// FindOverpayment will get a single overpayment - overpaymentID must be a GUID for an overpayment
+func FindOverpayment(cl *http.Client, overpaymentID uuid.UUID) (*Overpayment, error) {
+	return FindOverpaymentContext(context.Background(), cl, overpaymentID)
+}
+
+// FindOverpaymentContext is the same as FindOverpayment but the request is bound to the given context
+func FindOverpaymentContext(ctx context.Context, cl *http.Client, overpaymentID uuid.UUID) (*Overpayment, error) {
+	overpaymentResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, overpaymentsPath, overpaymentID.String()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	p, err := unmarshalOverpayment(overpaymentResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Overpayments) > 0 {
+		return &p.Overpayments[0], nil
+	}
+	return nil, nil
+}
+
+// Allocate will apply the given amounts of the overpayment to the invoices of
+// the allocations, each one with an Invoice and an AppliedAmount
+func (o *Overpayment) Allocate(cl *http.Client, allocations ...Allocation) (*Allocations, error) {
+	return o.AllocateContext(context.Background(), cl, allocations...)
+}
+
+// AllocateContext is the same as Allocate but the request is bound to the given context
+func (o *Overpayment) AllocateContext(ctx context.Context, cl *http.Client, allocations ...Allocation) (*Allocations, error) {
+	return allocate(ctx, cl, helpers.AccountingURL(ctx, overpaymentsPath, o.OverpaymentID, allocationsPath), allocations)
+}
+
+// Refund will refund the remaining credit of the overpayment with the given
+// payment, which needs the Account, the Date and the Amount
+func (o *Overpayment) Refund(cl *http.Client, payment Payment) (*Payments, error) {
+	return o.RefundContext(context.Background(), cl, payment)
+}
+
+// RefundContext is the same as Refund but the request is bound to the given context
+func (o *Overpayment) RefundContext(ctx context.Context, cl *http.Client, payment Payment) (*Payments, error) {
+	payment.Overpayment = o
+	return payment.CreateContext(ctx, cl)
+}
+
+// OverpaymentIterator walks all the pages of overpayments, use Next to advance and Overpayment to get
+// the current one
+type OverpaymentIterator struct {
+	pager
+	overpayments []Overpayment
+	current      Overpayment
+}
+
+// NewOverpaymentIterator will build an iterator over all the overpayments matching the
+// given queryParameters (where, order...), when modifiedSince is not zero only
+// the overpayments modified after it are returned
+func NewOverpaymentIterator(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) *OverpaymentIterator {
+	return &OverpaymentIterator{
+		pager: newPager(ctx, cl, helpers.AccountingURL(ctx, overpaymentsPath), modifiedSince, queryParameters),
+	}
+}
+
+// Next advances to the next overpayment, fetching the next page when needed. It
+// returns false when there are no more overpayments or an error happened, see Err
+func (it *OverpaymentIterator) Next() bool {
+	for len(it.overpayments) == 0 {
+		buf, ok := it.fetch()
+		if !ok {
+			return false
+		}
+		page, err := unmarshalOverpayment(buf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.Overpayments) == 0 {
+			return it.finish()
+		}
+		it.overpayments = page.Overpayments
+	}
+	it.current = it.overpayments[0]
+	it.overpayments = it.overpayments[1:]
+	return true
+}
+
+// Overpayment returns the current overpayment
+func (it *OverpaymentIterator) Overpayment() *Overpayment {
+	return &it.current
+}
+
+// FindAllOverpayments will get the overpayments of all the pages, see NewOverpaymentIterator
+func FindAllOverpayments(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Overpayments, error) {
+	return FindAllOverpaymentsContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindAllOverpaymentsContext is the same as FindAllOverpayments but the requests are bound to the given context
+func FindAllOverpaymentsContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Overpayments, error) {
+	all := &Overpayments{Overpayments: []Overpayment{}}
+	it := NewOverpaymentIterator(ctx, cl, modifiedSince, queryParameters)
+	for it.Next() {
+		all.Overpayments = append(all.Overpayments, *it.Overpayment())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
@@ -0,0 +1,84 @@
+package accounting
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	pageParameter     = "page"
+	pageSizeParameter = "pageSize"
+)
+
+// pager keeps the state shared by the iterators of the paged endpoints, it
+// walks page=N until Xero returns an empty page
+type pager struct {
+	ctx             context.Context
+	cl              *http.Client
+	endpoint        string
+	headers         map[string]string
+	queryParameters map[string]string
+	page            int
+	done            bool
+	err             error
+}
+
+func newPager(ctx context.Context, cl *http.Client, endpoint string, modifiedSince time.Time, queryParameters map[string]string) pager {
+	p := pager{
+		ctx:             ctx,
+		cl:              cl,
+		endpoint:        endpoint,
+		headers:         helpers.ModifiedSinceHeaders(modifiedSince),
+		queryParameters: map[string]string{},
+		page:            1,
+	}
+	for key, value := range queryParameters {
+		p.queryParameters[key] = value
+	}
+	// A page given in the parameters is used as the first page
+	if page, err := strconv.Atoi(p.queryParameters[pageParameter]); err == nil && page > 0 {
+		p.page = page
+	}
+	return p
+}
+
+// SetPageSize sets the number of elements per page, only supported by some
+// endpoints. It must be called before the first call to Next
+func (p *pager) SetPageSize(size int) {
+	p.queryParameters[pageSizeParameter] = strconv.Itoa(size)
+}
+
+// Page returns the number of the last page fetched
+func (p *pager) Page() int {
+	return p.page - 1
+}
+
+// Err returns the error that stopped the iteration, if any
+func (p *pager) Err() error {
+	return p.err
+}
+
+// fetch gets the next page, returns false when the iteration is finished
+func (p *pager) fetch() ([]byte, bool) {
+	if p.done || p.err != nil {
+		return nil, false
+	}
+	p.queryParameters[pageParameter] = strconv.Itoa(p.page)
+	buf, err := helpers.FindContext(p.ctx, p.cl, p.endpoint, p.headers, p.queryParameters)
+	if err != nil {
+		p.err = err
+		return nil, false
+	}
+	p.page++
+	return buf, true
+}
+
+// finish is called by the iterators when an empty page is found
+func (p *pager) finish() bool {
+	p.done = true
+	return false
+}
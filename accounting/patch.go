@@ -0,0 +1,52 @@
+package accounting
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// patchBody builds the payload of a partial update: the collection with a
+// single element holding the identifier and only the given fields, which are
+// sent even when they are zero (false, 0, "" or null). The fields can be
+// given by their Go or JSON name, only the top level fields are supported
+func patchBody(collection string, idField string, v interface{}, fields []string) ([]byte, error) {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	element := map[string]json.RawMessage{}
+	wanted := map[string]string{}
+	for _, field := range fields {
+		wanted[strings.ToLower(field)] = field
+	}
+	wanted[strings.ToLower(idField)] = idField
+
+	t := value.Type()
+	for n := 0; n < t.NumField(); n++ {
+		f := t.Field(n)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		_, byName := wanted[strings.ToLower(f.Name)]
+		_, byTag := wanted[strings.ToLower(name)]
+		if !byName && !byTag {
+			continue
+		}
+		delete(wanted, strings.ToLower(f.Name))
+		delete(wanted, strings.ToLower(name))
+		buf, err := json.Marshal(value.Field(n).Interface())
+		if err != nil {
+			return nil, err
+		}
+		element[name] = buf
+	}
+	for _, field := range wanted {
+		return nil, fmt.Errorf("accounting: unknown field %q in %s", field, t.Name())
+	}
+	return json.Marshal(map[string][]map[string]json.RawMessage{
+		collection: {element},
+	})
+}
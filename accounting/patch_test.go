@@ -0,0 +1,185 @@
+package accounting
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/auth"
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+	"golang.org/x/oauth2"
+)
+
+func TestPatchBody(t *testing.T) {
+	contact := &Contact{
+		ContactID:    "0f6a0ab5-5c8c-4b29-9d46-f0c6e4b9b0a1",
+		Name:         "ACME",
+		EmailAddress: "",
+		IsSupplier:   false,
+	}
+	journal := &ManualJournal{
+		ManualJournalID: "3b1d4a7e-4c5f-4f39-9b0a-d1a9a4a2d6c2",
+		URL:             "https://example.com",
+	}
+	tests := []struct {
+		name       string
+		collection string
+		idField    string
+		v          interface{}
+		fields     []string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "only the id",
+			collection: "Contacts",
+			idField:    "ContactID",
+			v:          contact,
+			want:       `{"Contacts":[{"ContactID":"0f6a0ab5-5c8c-4b29-9d46-f0c6e4b9b0a1"}]}`,
+		},
+		{
+			name:       "go name",
+			collection: "Contacts",
+			idField:    "ContactID",
+			v:          contact,
+			fields:     []string{"Name"},
+			want:       `{"Contacts":[{"ContactID":"0f6a0ab5-5c8c-4b29-9d46-f0c6e4b9b0a1","Name":"ACME"}]}`,
+		},
+		{
+			name:       "zero values are sent",
+			collection: "Contacts",
+			idField:    "ContactID",
+			v:          contact,
+			fields:     []string{"EmailAddress", "IsSupplier"},
+			want:       `{"Contacts":[{"ContactID":"0f6a0ab5-5c8c-4b29-9d46-f0c6e4b9b0a1","EmailAddress":"","IsSupplier":false}]}`,
+		},
+		{
+			name:       "json name ignoring the case",
+			collection: "ManualJournals",
+			idField:    "ManualJournalID",
+			v:          journal,
+			fields:     []string{"url"},
+			want:       `{"ManualJournals":[{"ManualJournalID":"3b1d4a7e-4c5f-4f39-9b0a-d1a9a4a2d6c2","Url":"https://example.com"}]}`,
+		},
+		{
+			name:       "nil pointer is null",
+			collection: "ManualJournals",
+			idField:    "ManualJournalID",
+			v:          journal,
+			fields:     []string{"ShowOnCashBasisReports"},
+			want:       `{"ManualJournals":[{"ManualJournalID":"3b1d4a7e-4c5f-4f39-9b0a-d1a9a4a2d6c2","ShowOnCashBasisReports":null}]}`,
+		},
+		{
+			name:       "value instead of pointer",
+			collection: "Contacts",
+			idField:    "ContactID",
+			v:          *contact,
+			fields:     []string{"Name"},
+			want:       `{"Contacts":[{"ContactID":"0f6a0ab5-5c8c-4b29-9d46-f0c6e4b9b0a1","Name":"ACME"}]}`,
+		},
+		{
+			name:       "unknown field",
+			collection: "Contacts",
+			idField:    "ContactID",
+			v:          contact,
+			fields:     []string{"Nmae"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patchBody(tt.collection, tt.idField, tt.v, tt.fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("patchBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("patchBody() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPatchCassette records a Patch against the fake server and replays it
+// once the server is gone
+func TestPatchCassette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xerosdk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "patch.json")
+
+	s := xerotest.NewServer()
+	tenantID := s.AddTenant("Demo Company")
+	ctx := helpers.WithEndpoints(context.Background(), s.Endpoints())
+	token := s.Token()
+
+	recorder, err := xerotest.NewRecorder(path, xerotest.Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := recorderClient(recorder, tenantID, token)
+	created, err := (&Contacts{Contacts: []Contact{{Name: "ACME", EmailAddress: "info@acme.test"}}}).CreateContext(ctx, cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contact := created.Contacts[0]
+	contact.EmailAddress = ""
+	recorded, err := contact.PatchContext(ctx, cl, "EmailAddress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded.Contacts[0].EmailAddress != "" {
+		t.Errorf("EmailAddress = %q after the Patch, want it cleared", recorded.Contacts[0].EmailAddress)
+	}
+	s.Close()
+
+	interactions := recorder.Interactions()
+	if len(interactions) != 2 {
+		t.Fatalf("%d interactions recorded, want 2", len(interactions))
+	}
+	patch := interactions[1].Request
+	if patch.Method != http.MethodPost || !strings.Contains(patch.Body, `"EmailAddress":`) || strings.Contains(patch.Body, `"Name"`) {
+		t.Errorf("Patch sent %s %s, want a POST with only the ContactID and the EmailAddress", patch.Method, patch.Body)
+	}
+	if strings.Contains(interactions[0].Request.Body, "info@acme.test") {
+		t.Errorf("the EmailAddress is saved in the cassette: %s", interactions[0].Request.Body)
+	}
+	if tenant := patch.TenantID; tenant == "" || tenant == tenantID.String() {
+		t.Errorf("the tenant %q is saved in the cassette, want the one replacing it", tenant)
+	}
+
+	replayer, err := xerotest.NewRecorder(path, xerotest.Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl = recorderClient(replayer, tenantID, token)
+	if _, err := (&Contacts{Contacts: []Contact{{Name: "ACME"}}}).CreateContext(ctx, cl); err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := contact.PatchContext(ctx, cl, "EmailAddress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Contacts[0].ContactID != contact.ContactID {
+		t.Errorf("replayed ContactID = %s, want %s", replayed.Contacts[0].ContactID, contact.ContactID)
+	}
+	if _, err := contact.PatchContext(ctx, cl, "EmailAddress"); err == nil {
+		t.Error("a Patch never recorded was replayed, want an error since the cassette has no interaction left")
+	}
+}
+
+func recorderClient(recorder *xerotest.Recorder, tenantID uuid.UUID, token *oauth2.Token) *http.Client {
+	return &http.Client{
+		Transport: &auth.Transport{
+			Base:   &auth.XeroTransport{T: recorder, TenantID: tenantID},
+			Source: oauth2.StaticTokenSource(token),
+		},
+	}
+}
@@ -1,5 +1,19 @@
 package accounting
 
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	paymentsPath = "Payments"
+)
+
 //Payment details payments against invoices and CreditNotes
 type Payment struct {
 
@@ -9,17 +23,26 @@ type Payment struct {
 	// Number of invoice or credit note you are applying payment to e.g. INV-4003
 	CreditNote *CreditNote `json:"CreditNote,omitempty"`
 
+	// Prepayment the payment is refunding
+	Prepayment *Prepayment `json:"Prepayment,omitempty"`
+
+	// Overpayment the payment is refunding
+	Overpayment *Overpayment `json:"Overpayment,omitempty"`
+
 	//Account of payment
 	Account *Account `json:"Account,omitempty"`
 
 	// Date the payment is being made (YYYY-MM-DD) e.g. 2009-09-06
-	Date string `json:"Date,omitempty"`
+	Date *Date `json:"Date,omitempty"`
 
 	// Exchange rate when payment is received. Only used for non base currency invoices and credit notes e.g. 0.7500
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate Decimal `json:"CurrencyRate,omitempty"`
 
 	// The amount of the payment. Must be less than or equal to the outstanding amount owing on the invoice e.g. 200.00
-	Amount float64 `json:"Amount,omitempty"`
+	Amount Decimal `json:"Amount,omitempty"`
+
+	// The amount of the payment in the currency of the bank account
+	BankAmount Decimal `json:"BankAmount,omitempty"`
 
 	// An optional description for the payment e.g. Direct Debit
 	Reference string `json:"Reference,omitempty"`
@@ -28,19 +51,418 @@ type Payment struct {
 	IsReconciled bool `json:"IsReconciled,omitempty"`
 
 	// The status of the payment.
-	Status string `json:"Status,omitempty"`
+	Status PaymentStatus `json:"Status,omitempty"`
 
 	// See Payment Types.
-	PaymentType string `json:"PaymentType,omitempty"`
+	PaymentType PaymentType `json:"PaymentType,omitempty"`
 
 	// UTC timestamp of last update to the payment
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// The Xero identifier for an Payment e.g. 297c2dc5-cc47-4afd-8ec8-74990b8761e9
 	PaymentID string `json:"PaymentID,omitempty"`
+
+	// The Xero identifier of the batch payment the payment is part of
+	BatchPaymentID string `json:"BatchPaymentID,omitempty"`
+
+	// A boolean to indicate if the payment has an account
+	HasAccount bool `json:"HasAccount,omitempty"`
+
+	// OK, WARNING or ERROR when the request was sent with summarizeErrors=false,
+	// see CreateAll
+	StatusAttributeString string `json:"StatusAttributeString,omitempty"`
+
+	// Validation errors of the payment returned with summarizeErrors=false
+	ValidationErrors []helpers.ValidationError `json:"ValidationErrors,omitempty"`
+
+	// Warnings of the payment returned with summarizeErrors=false
+	Warnings []helpers.Warning `json:"Warnings,omitempty"`
 }
 
 //Payments is a collection of Payments
 type Payments struct {
 	Payments []Payment `json:"Payments"`
 }
+
+// paymentReference is how the documents and the account of a payment are
+// sent, only with the fields identifying them
+type paymentReference struct {
+	InvoiceID        string `json:"InvoiceID,omitempty"`
+	InvoiceNumber    string `json:"InvoiceNumber,omitempty"`
+	CreditNoteID     string `json:"CreditNoteID,omitempty"`
+	CreditNoteNumber string `json:"CreditNoteNumber,omitempty"`
+	PrepaymentID     string `json:"PrepaymentID,omitempty"`
+	OverpaymentID    string `json:"OverpaymentID,omitempty"`
+	AccountID        string `json:"AccountID,omitempty"`
+	Code             string `json:"Code,omitempty"`
+}
+
+// paymentFields are the fields of a payment without its methods, so they can
+// be embedded in its payload
+type paymentFields Payment
+
+// paymentPayload is how a payment is sent when it is created: the invoice,
+// credit note, prepayment, overpayment and account are references holding
+// only their identifiers, as the whole documents would be rejected by Xero
+type paymentPayload struct {
+	paymentFields
+	Invoice     *paymentReference `json:"Invoice,omitempty"`
+	CreditNote  *paymentReference `json:"CreditNote,omitempty"`
+	Prepayment  *paymentReference `json:"Prepayment,omitempty"`
+	Overpayment *paymentReference `json:"Overpayment,omitempty"`
+	Account     *paymentReference `json:"Account,omitempty"`
+}
+
+// paymentsPayload is how a collection of payments is sent when it is created
+type paymentsPayload struct {
+	Payments []paymentPayload `json:"Payments"`
+}
+
+func newPaymentPayload(p Payment) paymentPayload {
+	out := paymentPayload{paymentFields: paymentFields(p)}
+	if p.Invoice != nil {
+		out.Invoice = &paymentReference{InvoiceID: p.Invoice.InvoiceID, InvoiceNumber: p.Invoice.InvoiceNumber}
+	}
+	if p.CreditNote != nil {
+		out.CreditNote = &paymentReference{CreditNoteID: p.CreditNote.CreditNoteID, CreditNoteNumber: p.CreditNote.CreditNoteNumber}
+	}
+	if p.Prepayment != nil {
+		out.Prepayment = &paymentReference{PrepaymentID: p.Prepayment.PrepaymentID}
+	}
+	if p.Overpayment != nil {
+		out.Overpayment = &paymentReference{OverpaymentID: p.Overpayment.OverpaymentID}
+	}
+	if p.Account != nil {
+		out.Account = &paymentReference{AccountID: p.Account.AccountID, Code: p.Account.Code}
+	}
+	return out
+}
+
+func newPaymentsPayload(payments []Payment) paymentsPayload {
+	out := paymentsPayload{}
+	for _, p := range payments {
+		out.Payments = append(out.Payments, newPaymentPayload(p))
+	}
+	return out
+}
+
+func unmarshalPayment(paymentResponseBytes []byte) (*Payments, error) {
+	var paymentResponse *Payments
+	err := json.Unmarshal(paymentResponseBytes, &paymentResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return paymentResponse, err
+}
+
+// FindPayments will get a page of payments. Additional querystringParameters
+// such as where, page and order can be added as a map
+func FindPayments(cl *http.Client, queryParameters map[string]string) (*Payments, error) {
+	return FindPaymentsContext(context.Background(), cl, queryParameters)
+}
+
+// FindPaymentsContext is the same as FindPayments but the request is bound to the given context
+func FindPaymentsContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*Payments, error) {
+	return FindPaymentsModifiedSinceContext(ctx, cl, time.Time{}, queryParameters)
+}
+
+// FindPaymentsModifiedSince will get the payments modified after the given date, a zero
+// modifiedSince will not filter by date. Additional querystringParameters such as
+// where, page and order can be added as a map
+func FindPaymentsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Payments, error) {
+	return FindPaymentsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindPaymentsModifiedSinceContext is the same as FindPaymentsModifiedSince but the request is bound to the given context
+func FindPaymentsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Payments, error) {
+	paymentResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, paymentsPath), helpers.ModifiedSinceHeaders(modifiedSince), queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPayment(paymentResponseBytes)
+}
+
+// FindPayment will get a single payment - paymentID must be a GUID for a payment
+func FindPayment(cl *http.Client, paymentID uuid.UUID) (*Payment, error) {
+	return FindPaymentContext(context.Background(), cl, paymentID)
+}
+
+// FindPaymentContext is the same as FindPayment but the request is bound to the given context
+func FindPaymentContext(ctx context.Context, cl *http.Client, paymentID uuid.UUID) (*Payment, error) {
+	paymentResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, paymentsPath, paymentID.String()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	p, err := unmarshalPayment(paymentResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Payments) > 0 {
+		return &p.Payments[0], nil
+	}
+	return nil, nil
+}
+
+// Create will create the given payments, each one applied to an invoice, a
+// credit note, a prepayment or an overpayment and paid from or to an account
+func (p *Payments) Create(cl *http.Client) (*Payments, error) {
+	return p.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (p *Payments) CreateContext(ctx context.Context, cl *http.Client) (*Payments, error) {
+	if err := validate(ctx, p); err != nil {
+		return nil, err
+	}
+	buf, err := json.Marshal(newPaymentsPayload(p.Payments))
+	if err != nil {
+		return nil, err
+	}
+	paymentResponseBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, paymentsPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPayment(paymentResponseBytes)
+}
+
+// Create will create the payment, see Payments.Create
+func (p *Payment) Create(cl *http.Client) (*Payments, error) {
+	return p.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (p *Payment) CreateContext(ctx context.Context, cl *http.Client) (*Payments, error) {
+	ps := Payments{
+		Payments: []Payment{*p},
+	}
+	return ps.CreateContext(ctx, cl)
+}
+
+// PaymentResult is the result of an element of Payments.CreateAll, either the
+// payment returned by Xero or the error that stopped it
+type PaymentResult struct {
+	Payment Payment
+	Err     error
+}
+
+// CreateAll will create the payments in requests of BatchSize elements
+// with summarizeErrors=false, so the valid ones are created even when others
+// are rejected. The results are in the order of the payments, the error is
+// the first one failing a whole request
+func (p *Payments) CreateAll(cl *http.Client) ([]PaymentResult, error) {
+	return p.CreateAllContext(context.Background(), cl)
+}
+
+// CreateAllContext is the same as CreateAll but the requests are bound to the given context
+func (p *Payments) CreateAllContext(ctx context.Context, cl *http.Client) ([]PaymentResult, error) {
+	results := make([]PaymentResult, len(p.Payments))
+	sender := batch{
+		n: len(p.Payments),
+		element: func(n int) validator {
+			return &p.Payments[n]
+		},
+		payload: func(indexes []int) interface{} {
+			chunk := []Payment{}
+			for _, n := range indexes {
+				chunk = append(chunk, p.Payments[n])
+			}
+			return newPaymentsPayload(chunk)
+		},
+		decode: func(buf []byte, indexes []int) error {
+			var saved Payments
+			if err := json.Unmarshal(buf, &saved); err != nil {
+				return err
+			}
+			for k, n := range indexes {
+				if k >= len(saved.Payments) {
+					results[n].Err = errMissingElement(n)
+					continue
+				}
+				results[n].Payment = saved.Payments[k]
+				results[n].Err = elementError(n, saved.Payments[k].StatusAttributeString, saved.Payments[k].ValidationErrors)
+			}
+			return nil
+		},
+		fail: func(n int, err error) {
+			results[n] = PaymentResult{Payment: p.Payments[n], Err: err}
+		},
+	}
+	return results, sender.send(ctx, cl, helpers.AccountingURL(ctx, paymentsPath), false)
+}
+
+// RemovePayment will delete the payment with the given ID. Xero doesn't remove
+// the payments, they are reversed by setting their status to DELETED
+func RemovePayment(cl *http.Client, paymentID uuid.UUID) (*Payments, error) {
+	return RemovePaymentContext(context.Background(), cl, paymentID)
+}
+
+// RemovePaymentContext is the same as RemovePayment but the request is bound to the given context
+func RemovePaymentContext(ctx context.Context, cl *http.Client, paymentID uuid.UUID) (*Payments, error) {
+	buf, err := json.Marshal(map[string]PaymentStatus{"Status": PaymentStatusDeleted})
+	if err != nil {
+		return nil, err
+	}
+	paymentResponseBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, paymentsPath, paymentID.String()), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPayment(paymentResponseBytes)
+}
+
+// PaymentIterator walks all the pages of payments, use Next to advance and Payment to get
+// the current one
+type PaymentIterator struct {
+	pager
+	payments []Payment
+	current  Payment
+}
+
+// NewPaymentIterator will build an iterator over all the payments matching the
+// given queryParameters (where, order...), when modifiedSince is not zero only
+// the payments modified after it are returned
+func NewPaymentIterator(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) *PaymentIterator {
+	return &PaymentIterator{
+		pager: newPager(ctx, cl, helpers.AccountingURL(ctx, paymentsPath), modifiedSince, queryParameters),
+	}
+}
+
+// Next advances to the next payment, fetching the next page when needed. It
+// returns false when there are no more payments or an error happened, see Err
+func (it *PaymentIterator) Next() bool {
+	for len(it.payments) == 0 {
+		buf, ok := it.fetch()
+		if !ok {
+			return false
+		}
+		page, err := unmarshalPayment(buf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.Payments) == 0 {
+			return it.finish()
+		}
+		it.payments = page.Payments
+	}
+	it.current = it.payments[0]
+	it.payments = it.payments[1:]
+	return true
+}
+
+// Payment returns the current payment
+func (it *PaymentIterator) Payment() *Payment {
+	return &it.current
+}
+
+// FindAllPayments will get the payments of all the pages, see NewPaymentIterator
+func FindAllPayments(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Payments, error) {
+	return FindAllPaymentsContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindAllPaymentsContext is the same as FindAllPayments but the requests are bound to the given context
+func FindAllPaymentsContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Payments, error) {
+	all := &Payments{Payments: []Payment{}}
+	it := NewPaymentIterator(ctx, cl, modifiedSince, queryParameters)
+	for it.Next() {
+		all.Payments = append(all.Payments, *it.Payment())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// PaymentStatus is the status of a payment
+type PaymentStatus string
+
+// The payment statuses
+const (
+	PaymentStatusAuthorised PaymentStatus = "AUTHORISED"
+	PaymentStatusDeleted    PaymentStatus = "DELETED"
+)
+
+// IsValid reports whether the status is one of the documented values
+func (v PaymentStatus) IsValid() bool {
+	switch v {
+	case PaymentStatusAuthorised,
+		PaymentStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// PaymentType is the kind of document a payment is applied to
+type PaymentType string
+
+// The payment types
+const (
+	PaymentTypeAccRecPayment        PaymentType = "ACCRECPAYMENT"
+	PaymentTypeAccPayPayment        PaymentType = "ACCPAYPAYMENT"
+	PaymentTypeARCreditPayment      PaymentType = "ARCREDITPAYMENT"
+	PaymentTypeAPCreditPayment      PaymentType = "APCREDITPAYMENT"
+	PaymentTypeAROverpaymentPayment PaymentType = "AROVERPAYMENTPAYMENT"
+	PaymentTypeARPrepaymentPayment  PaymentType = "ARPREPAYMENTPAYMENT"
+	PaymentTypeAPPrepaymentPayment  PaymentType = "APPREPAYMENTPAYMENT"
+	PaymentTypeAPOverpaymentPayment PaymentType = "APOVERPAYMENTPAYMENT"
+)
+
+// IsValid reports whether the payment type is one of the documented values
+func (v PaymentType) IsValid() bool {
+	switch v {
+	case PaymentTypeAccRecPayment,
+		PaymentTypeAccPayPayment,
+		PaymentTypeARCreditPayment,
+		PaymentTypeAPCreditPayment,
+		PaymentTypeAROverpaymentPayment,
+		PaymentTypeARPrepaymentPayment,
+		PaymentTypeAPPrepaymentPayment,
+		PaymentTypeAPOverpaymentPayment:
+		return true
+	}
+	return false
+}
+
+// Validate checks the payment against the rules documented by Xero
+func (p *Payment) Validate() error {
+	r := rules{}
+	documents := 0
+	for _, set := range []bool{p.Invoice != nil, p.CreditNote != nil, p.Prepayment != nil, p.Overpayment != nil} {
+		if set {
+			documents++
+		}
+	}
+	if p.PaymentID == "" {
+		r.required("Invoice", documents > 0)
+		r.required("Account", p.Account != nil && (p.Account.AccountID != "" || p.Account.Code != ""))
+		r.required("Amount", !p.Amount.IsZero())
+	}
+	if documents > 1 {
+		r.add("Invoice", "only one of Invoice, CreditNote, Prepayment and Overpayment can be set")
+	}
+	// only the identifiers of the documents are sent, see newPaymentPayload
+	if p.Invoice != nil && p.Invoice.InvoiceID == "" && p.Invoice.InvoiceNumber == "" {
+		r.add("Invoice", "needs its InvoiceID or InvoiceNumber")
+	}
+	if p.CreditNote != nil && p.CreditNote.CreditNoteID == "" && p.CreditNote.CreditNoteNumber == "" {
+		r.add("CreditNote", "needs its CreditNoteID or CreditNoteNumber")
+	}
+	if p.Prepayment != nil {
+		r.required("Prepayment.PrepaymentID", p.Prepayment.PrepaymentID != "")
+	}
+	if p.Overpayment != nil {
+		r.required("Overpayment.OverpaymentID", p.Overpayment.OverpaymentID != "")
+	}
+	if p.Amount.Sign() < 0 {
+		r.add("Amount", "can't be negative")
+	}
+	r.enum("Status", p.Status, p.Status != "")
+	r.enum("PaymentType", p.PaymentType, p.PaymentType != "")
+	r.maxLength("Reference", p.Reference, 255)
+	return r.err()
+}
+
+// Validate checks all the payments of the collection
+func (p *Payments) Validate() error {
+	return validateAll("Payments", len(p.Payments), func(n int) validator { return &p.Payments[n] })
+}
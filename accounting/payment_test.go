@@ -0,0 +1,127 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+)
+
+func TestPaymentValidate(t *testing.T) {
+	account := &Account{Code: "090"}
+	tests := []struct {
+		name    string
+		payment Payment
+		want    []string
+	}{
+		{name: "invoice by ID", payment: Payment{Invoice: &Invoice{InvoiceID: "6a539484-9a5f-41e3-a2c1-a6be5d4f8af0"}, Account: account, Amount: "10"}},
+		{name: "invoice by number", payment: Payment{Invoice: &Invoice{InvoiceNumber: "INV-1"}, Account: account, Amount: "10"}},
+		{name: "credit note by number", payment: Payment{CreditNote: &CreditNote{CreditNoteNumber: "CN-1"}, Account: account, Amount: "10"}},
+		{name: "prepayment", payment: Payment{Prepayment: &Prepayment{PrepaymentID: "c3d4e8a5-0b4d-4a3c-9e2f-5c0c8a6f9d71"}, Account: account, Amount: "10"}},
+		{name: "empty invoice", payment: Payment{Invoice: &Invoice{Reference: "INV-1"}, Account: account, Amount: "10"}, want: []string{"Invoice"}},
+		{name: "empty credit note", payment: Payment{CreditNote: &CreditNote{}, Account: account, Amount: "10"}, want: []string{"CreditNote"}},
+		{name: "empty prepayment", payment: Payment{Prepayment: &Prepayment{}, Account: account, Amount: "10"}, want: []string{"Prepayment.PrepaymentID"}},
+		{name: "empty overpayment", payment: Payment{Overpayment: &Overpayment{}, Account: account, Amount: "10"}, want: []string{"Overpayment.OverpaymentID"}},
+		{name: "new payment", payment: Payment{}, want: []string{"Invoice", "Account", "Amount"}},
+		{name: "existing payment", payment: Payment{PaymentID: "1c5a4e42-0c2f-4d1f-8cb4-1f5c4cbe8f6e", Status: PaymentStatusDeleted}},
+		{
+			name:    "two documents",
+			payment: Payment{Invoice: &Invoice{InvoiceNumber: "INV-1"}, CreditNote: &CreditNote{CreditNoteNumber: "CN-1"}, Account: account, Amount: "10"},
+			want:    []string{"Invoice"},
+		},
+		{name: "negative amount", payment: Payment{Invoice: &Invoice{InvoiceNumber: "INV-1"}, Account: account, Amount: "-1"}, want: []string{"Amount"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.payment.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			if !errors.Is(err, helpers.ErrValidation) {
+				t.Fatalf("Validate() = %v, want a validation error", err)
+			}
+			var fields []string
+			for _, e := range err.(ValidationErrors) {
+				fields = append(fields, e.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("Validate() fields = %v, want %v (%v)", fields, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPaymentCreateAndRemove(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	invoiceID := "6a539484-9a5f-41e3-a2c1-a6be5d4f8af0"
+	if err := s.Seed(tenantID, "Invoices", Invoice{InvoiceID: invoiceID, InvoiceNumber: "INV-1", Reference: "Order 1"}); err != nil {
+		t.Fatal(err)
+	}
+	cl := s.Client(tenantID)
+	ctx := WithValidation(helpers.WithEndpoints(context.Background(), s.Endpoints()))
+
+	payment := Payment{
+		Invoice: &Invoice{InvoiceID: invoiceID, Reference: "Order 1"},
+		Account: &Account{Code: "090"},
+		Date:    NewDate(2020, time.March, 2),
+		Amount:  "100.50",
+	}
+	created, err := payment.CreateContext(ctx, cl)
+	if err != nil {
+		t.Fatalf("CreateContext() error = %v", err)
+	}
+	if len(created.Payments) != 1 || created.Payments[0].PaymentID == "" {
+		t.Fatalf("CreateContext() = %+v, want the created payment", created)
+	}
+	paymentID := uuid.FromStringOrNil(created.Payments[0].PaymentID)
+
+	// only the identifiers of the invoice and the account are sent
+	records := s.Records(tenantID, "Payments")
+	if len(records) != 1 {
+		t.Fatalf("%d payments saved, want 1", len(records))
+	}
+	var saved map[string]json.RawMessage
+	if err := json.Unmarshal(records[0], &saved); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(saved["Invoice"]), `{"InvoiceID":"`+invoiceID+`"}`; got != want {
+		t.Errorf("saved Invoice = %s, want %s", got, want)
+	}
+	if got, want := string(saved["Account"]), `{"Code":"090"}`; got != want {
+		t.Errorf("saved Account = %s, want %s", got, want)
+	}
+
+	found, err := FindPaymentContext(ctx, cl, paymentID)
+	if err != nil {
+		t.Fatalf("FindPaymentContext() error = %v", err)
+	}
+	if !found.Amount.Equal("100.50") || found.Date == nil || found.Date.String() != "2020-03-02" {
+		t.Errorf("FindPaymentContext() = %s on %v, want 100.50 on 2020-03-02", found.Amount, found.Date)
+	}
+
+	removed, err := RemovePaymentContext(ctx, cl, paymentID)
+	if err != nil {
+		t.Fatalf("RemovePaymentContext() error = %v", err)
+	}
+	if len(removed.Payments) != 1 || removed.Payments[0].Status != PaymentStatusDeleted {
+		t.Errorf("RemovePaymentContext() = %+v, want the payment DELETED", removed)
+	}
+	found, err = FindPaymentContext(ctx, cl, paymentID)
+	if err != nil {
+		t.Fatalf("FindPaymentContext() error = %v", err)
+	}
+	if found.Status != PaymentStatusDeleted || !found.Amount.Equal("100.50") {
+		t.Errorf("FindPaymentContext() = %s %s, want the reversed payment of 100.50", found.Status, found.Amount)
+	}
+}
@@ -2,7 +2,7 @@ package accounting
 
 // Phone type will keep the information for the Phone model
 type Phone struct {
-	PhoneType string `json:"PhoneType,omitempty"`
+	PhoneType PhoneType `json:"PhoneType,omitempty"`
 
 	// max length = 50
 	PhoneNumber string `json:"PhoneNumber,omitempty"`
@@ -13,3 +13,36 @@ type Phone struct {
 	// max length = 20
 	PhoneCountryCode string `json:"PhoneCountryCode,omitempty"`
 }
+
+// PhoneType is the type of a phone of a contact
+type PhoneType string
+
+// The phone types
+const (
+	PhoneTypeDefault PhoneType = "DEFAULT"
+	PhoneTypeDDI     PhoneType = "DDI"
+	PhoneTypeMobile  PhoneType = "MOBILE"
+	PhoneTypeFax     PhoneType = "FAX"
+)
+
+// IsValid reports whether the phone type is one of the documented values
+func (v PhoneType) IsValid() bool {
+	switch v {
+	case PhoneTypeDefault,
+		PhoneTypeDDI,
+		PhoneTypeMobile,
+		PhoneTypeFax:
+		return true
+	}
+	return false
+}
+
+// Validate checks the phone against the rules documented by Xero
+func (p *Phone) Validate() error {
+	r := rules{}
+	r.enum("PhoneType", p.PhoneType, p.PhoneType != "")
+	r.maxLength("PhoneNumber", p.PhoneNumber, 50)
+	r.maxLength("PhoneAreaCode", p.PhoneAreaCode, 10)
+	r.maxLength("PhoneCountryCode", p.PhoneCountryCode, 20)
+	return r.err()
+}
@@ -1,13 +1,27 @@
 package accounting
 
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	prepaymentsPath = "Prepayments"
+)
+
 //Prepayment are payments made before the associated document has been created
 type Prepayment struct {
 
 	// See Prepayment Types
-	Type string `json:"Type,omitempty"`
+	Type BankTransactionType `json:"Type,omitempty"`
 
 	// The date the prepayment is created YYYY-MM-DD
-	Date string `json:"DateString,omitempty"`
+	Date *Date `json:"DateString,omitempty"`
 
 	// See Contacts
 	Contact Contact `json:"Contact"`
@@ -16,22 +30,22 @@ type Prepayment struct {
 	Status string `json:"Status,omitempty"`
 
 	// See Prepayment Line Amount Types
-	LineAmountTypes string `json:"LineAmountTypes,omitempty"`
+	LineAmountTypes LineAmountType `json:"LineAmountTypes,omitempty"`
 
 	// See Prepayment Line Items
 	LineItems []LineItem `json:"LineItems,omitempty"`
 
 	// The subtotal of the prepayment excluding taxes
-	SubTotal float64 `json:"SubTotal,omitempty"`
+	SubTotal Decimal `json:"SubTotal,omitempty"`
 
 	// The total tax on the prepayment
-	TotalTax float64 `json:"TotalTax,omitempty"`
+	TotalTax Decimal `json:"TotalTax,omitempty"`
 
 	// The total of the prepayment(subtotal + total tax)
-	Total float64 `json:"Total,omitempty"`
+	Total Decimal `json:"Total,omitempty"`
 
 	// UTC timestamp of last update to the prepayment
-	UpdatedDateUTC string `json:"UpdatedDateUTC,omitempty"`
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
 
 	// Currency used for the prepayment
 	CurrencyCode string `json:"CurrencyCode,omitempty"`
@@ -40,14 +54,17 @@ type Prepayment struct {
 	PrepaymentID string `json:"PrepaymentID,omitempty"`
 
 	// The currency rate for a multicurrency prepayment. If no rate is specified, the XE.com day rate is used
-	CurrencyRate float64 `json:"CurrencyRate,omitempty"`
+	CurrencyRate Decimal `json:"CurrencyRate,omitempty"`
 
 	// The remaining credit balance on the prepayment
-	RemainingCredit float64 `json:"RemainingCredit,omitempty"`
+	RemainingCredit Decimal `json:"RemainingCredit,omitempty"`
 
 	// See Allocations
 	Allocations []Allocation `json:"Allocations,omitempty"`
 
+	// See Payments
+	Payments []Payment `json:"Payments,omitempty"`
+
 	// boolean to indicate if a prepayment has an attachment
 	HasAttachments bool `json:"HasAttachments,omitempty"`
 }
@@ -56,3 +73,147 @@ type Prepayment struct {
 type Prepayments struct {
 	Prepayments []Prepayment `json:"Prepayments"`
 }
+
+func unmarshalPrepayment(prepaymentResponseBytes []byte) (*Prepayments, error) {
+	var prepaymentResponse *Prepayments
+	err := json.Unmarshal(prepaymentResponseBytes, &prepaymentResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return prepaymentResponse, err
+}
+
+// FindPrepayments will get a page of prepayments. Additional querystringParameters
+// such as where, page and order can be added as a map
+func FindPrepayments(cl *http.Client, queryParameters map[string]string) (*Prepayments, error) {
+	return FindPrepaymentsContext(context.Background(), cl, queryParameters)
+}
+
+// FindPrepaymentsContext is the same as FindPrepayments but the request is bound to the given context
+func FindPrepaymentsContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*Prepayments, error) {
+	return FindPrepaymentsModifiedSinceContext(ctx, cl, time.Time{}, queryParameters)
+}
+
+// FindPrepaymentsModifiedSince will get the prepayments modified after the given date, a zero
+// modifiedSince will not filter by date. Additional querystringParameters such as
+// where, page and order can be added as a map
+func FindPrepaymentsModifiedSince(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Prepayments, error) {
+	return FindPrepaymentsModifiedSinceContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindPrepaymentsModifiedSinceContext is the same as FindPrepaymentsModifiedSince but the request is bound to the given context
+func FindPrepaymentsModifiedSinceContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Prepayments, error) {
+	prepaymentResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, prepaymentsPath), helpers.ModifiedSinceHeaders(modifiedSince), queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalPrepayment(prepaymentResponseBytes)
+}
+
+// FindPrepayment will get a single prepayment - prepaymentID must be a GUID for a prepayment
+func FindPrepayment(cl *http.Client, prepaymentID uuid.UUID) (*Prepayment, error) {
+	return FindPrepaymentContext(context.Background(), cl, prepaymentID)
+}
+
+// FindPrepaymentContext is the same as FindPrepayment but the request is bound to the given context
+func FindPrepaymentContext(ctx context.Context, cl *http.Client, prepaymentID uuid.UUID) (*Prepayment, error) {
+	prepaymentResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, prepaymentsPath, prepaymentID.String()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	p, err := unmarshalPrepayment(prepaymentResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Prepayments) > 0 {
+		return &p.Prepayments[0], nil
+	}
+	return nil, nil
+}
+
+// Allocate will apply the given amounts of the prepayment to the invoices of
+// the allocations, each one with an Invoice and an AppliedAmount
+func (p *Prepayment) Allocate(cl *http.Client, allocations ...Allocation) (*Allocations, error) {
+	return p.AllocateContext(context.Background(), cl, allocations...)
+}
+
+// AllocateContext is the same as Allocate but the request is bound to the given context
+func (p *Prepayment) AllocateContext(ctx context.Context, cl *http.Client, allocations ...Allocation) (*Allocations, error) {
+	return allocate(ctx, cl, helpers.AccountingURL(ctx, prepaymentsPath, p.PrepaymentID, allocationsPath), allocations)
+}
+
+// Refund will refund the remaining credit of the prepayment with the given
+// payment, which needs the Account, the Date and the Amount
+func (p *Prepayment) Refund(cl *http.Client, payment Payment) (*Payments, error) {
+	return p.RefundContext(context.Background(), cl, payment)
+}
+
+// RefundContext is the same as Refund but the request is bound to the given context
+func (p *Prepayment) RefundContext(ctx context.Context, cl *http.Client, payment Payment) (*Payments, error) {
+	payment.Prepayment = p
+	return payment.CreateContext(ctx, cl)
+}
+
+// PrepaymentIterator walks all the pages of prepayments, use Next to advance and Prepayment to get
+// the current one
+type PrepaymentIterator struct {
+	pager
+	prepayments []Prepayment
+	current     Prepayment
+}
+
+// NewPrepaymentIterator will build an iterator over all the prepayments matching the
+// given queryParameters (where, order...), when modifiedSince is not zero only
+// the prepayments modified after it are returned
+func NewPrepaymentIterator(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) *PrepaymentIterator {
+	return &PrepaymentIterator{
+		pager: newPager(ctx, cl, helpers.AccountingURL(ctx, prepaymentsPath), modifiedSince, queryParameters),
+	}
+}
+
+// Next advances to the next prepayment, fetching the next page when needed. It
+// returns false when there are no more prepayments or an error happened, see Err
+func (it *PrepaymentIterator) Next() bool {
+	for len(it.prepayments) == 0 {
+		buf, ok := it.fetch()
+		if !ok {
+			return false
+		}
+		page, err := unmarshalPrepayment(buf)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if len(page.Prepayments) == 0 {
+			return it.finish()
+		}
+		it.prepayments = page.Prepayments
+	}
+	it.current = it.prepayments[0]
+	it.prepayments = it.prepayments[1:]
+	return true
+}
+
+// Prepayment returns the current prepayment
+func (it *PrepaymentIterator) Prepayment() *Prepayment {
+	return &it.current
+}
+
+// FindAllPrepayments will get the prepayments of all the pages, see NewPrepaymentIterator
+func FindAllPrepayments(cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Prepayments, error) {
+	return FindAllPrepaymentsContext(context.Background(), cl, modifiedSince, queryParameters)
+}
+
+// FindAllPrepaymentsContext is the same as FindAllPrepayments but the requests are bound to the given context
+func FindAllPrepaymentsContext(ctx context.Context, cl *http.Client, modifiedSince time.Time, queryParameters map[string]string) (*Prepayments, error) {
+	all := &Prepayments{Prepayments: []Prepayment{}}
+	it := NewPrepaymentIterator(ctx, cl, modifiedSince, queryParameters)
+	for it.Next() {
+		all.Prepayments = append(all.Prepayments, *it.Prepayment())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
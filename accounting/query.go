@@ -0,0 +1,304 @@
+package accounting
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	whereParameter           = "where"
+	orderParameter           = "order"
+	idsParameter             = "IDs"
+	invoiceNumbersParameter  = "InvoiceNumbers"
+	contactIDsParameter      = "ContactIDs"
+	statusesParameter        = "Statuses"
+	includeArchivedParameter = "includeArchived"
+	paymentsOnlyParameter    = "paymentsOnly"
+)
+
+// Literal is implemented by the types that know how they must be written in a
+// where expression
+type Literal interface {
+	WhereLiteral() string
+}
+
+// Guid is a literal written as Guid("...") in a where expression, it is the
+// way for comparing the ID fields
+type Guid uuid.UUID
+
+// WhereLiteral returns the Guid literal
+func (g Guid) WhereLiteral() string {
+	return `Guid("` + uuid.UUID(g).String() + `")`
+}
+
+// Raw is a literal written as it is in a where expression, use it when none
+// of the other types fit
+type Raw string
+
+// WhereLiteral returns the raw value
+func (r Raw) WhereLiteral() string {
+	return string(r)
+}
+
+// Filter is an expression for the where querystringParameter, build it with
+// Field and combine them using And and Or
+type Filter struct {
+	expr     string
+	compound bool
+}
+
+// String returns the expression of the filter
+func (f Filter) String() string {
+	return f.expr
+}
+
+// Field is the name of a field used in a Filter e.g. Field("Contact.ContactID")
+type Field string
+
+// Eq builds the filter Field==value
+func (f Field) Eq(value interface{}) Filter {
+	return f.compare("==", value)
+}
+
+// Ne builds the filter Field!=value
+func (f Field) Ne(value interface{}) Filter {
+	return f.compare("!=", value)
+}
+
+// Gt builds the filter Field>value
+func (f Field) Gt(value interface{}) Filter {
+	return f.compare(">", value)
+}
+
+// Ge builds the filter Field>=value
+func (f Field) Ge(value interface{}) Filter {
+	return f.compare(">=", value)
+}
+
+// Lt builds the filter Field<value
+func (f Field) Lt(value interface{}) Filter {
+	return f.compare("<", value)
+}
+
+// Le builds the filter Field<=value
+func (f Field) Le(value interface{}) Filter {
+	return f.compare("<=", value)
+}
+
+// Contains builds the filter Field.Contains("value")
+func (f Field) Contains(value string) Filter {
+	return f.call("Contains", value)
+}
+
+// StartsWith builds the filter Field.StartsWith("value")
+func (f Field) StartsWith(value string) Filter {
+	return f.call("StartsWith", value)
+}
+
+// EndsWith builds the filter Field.EndsWith("value")
+func (f Field) EndsWith(value string) Filter {
+	return f.call("EndsWith", value)
+}
+
+func (f Field) compare(operator string, value interface{}) Filter {
+	return Filter{expr: string(f) + operator + FormatLiteral(value)}
+}
+
+func (f Field) call(method string, value string) Filter {
+	return Filter{expr: string(f) + "." + method + "(" + quote(value) + ")"}
+}
+
+// And joins the given filters with AND, grouping them in brackets when needed
+func And(filters ...Filter) Filter {
+	return join(" AND ", filters)
+}
+
+// Or joins the given filters with OR, grouping them in brackets when needed
+func Or(filters ...Filter) Filter {
+	return join(" OR ", filters)
+}
+
+func join(operator string, filters []Filter) Filter {
+	switch len(filters) {
+	case 0:
+		return Filter{}
+	case 1:
+		return filters[0]
+	}
+	parts := make([]string, 0, len(filters))
+	for _, f := range filters {
+		if f.expr == "" {
+			continue
+		}
+		if f.compound {
+			parts = append(parts, "("+f.expr+")")
+		} else {
+			parts = append(parts, f.expr)
+		}
+	}
+	return Filter{expr: strings.Join(parts, operator), compound: len(parts) > 1}
+}
+
+// FormatLiteral writes the given value as a literal of a where expression:
+// strings are quoted and escaped, numbers and booleans of any kind are written
+// as they are, time.Time are written as DateTime(...), uuid.UUID as
+// Guid("...") and the types implementing Literal as they say
+func FormatLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case Literal:
+		return v.WhereLiteral()
+	case string:
+		return quote(v)
+	case uuid.UUID:
+		return Guid(v).WhereLiteral()
+	case time.Time:
+		return dateTimeLiteral(v)
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return quote(fmt.Sprint(value))
+}
+
+func quote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return `"` + s + `"`
+}
+
+func dateTimeLiteral(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return fmt.Sprintf("DateTime(%d,%02d,%02d)", t.Year(), t.Month(), t.Day())
+	}
+	return fmt.Sprintf("DateTime(%d,%02d,%02d,%02d,%02d,%02d)", t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second())
+}
+
+// Query builds the querystringParameters accepted by the Find functions: the
+// where and order expressions and the optimised filters (IDs, InvoiceNumbers,
+// ContactIDs and Statuses) supported by some endpoints
+type Query struct {
+	where      []Filter
+	order      []string
+	parameters map[string]string
+	lists      map[string][]string
+}
+
+// NewQuery will build an empty Query
+func NewQuery() *Query {
+	return &Query{
+		parameters: map[string]string{},
+		lists:      map[string][]string{},
+	}
+}
+
+// Where adds the given filters, all the filters of the query are joined with
+// AND
+func (q *Query) Where(filters ...Filter) *Query {
+	q.where = append(q.where, filters...)
+	return q
+}
+
+// OrderBy adds an ascending order by the given field
+func (q *Query) OrderBy(field string) *Query {
+	q.order = append(q.order, field)
+	return q
+}
+
+// OrderByDesc adds a descending order by the given field
+func (q *Query) OrderByDesc(field string) *Query {
+	q.order = append(q.order, field+" DESC")
+	return q
+}
+
+// IDs filters by the Xero identifiers of the documents
+func (q *Query) IDs(ids ...uuid.UUID) *Query {
+	for _, id := range ids {
+		q.lists[idsParameter] = append(q.lists[idsParameter], id.String())
+	}
+	return q
+}
+
+// InvoiceNumbers filters the invoices by their numbers
+func (q *Query) InvoiceNumbers(numbers ...string) *Query {
+	q.lists[invoiceNumbersParameter] = append(q.lists[invoiceNumbersParameter], numbers...)
+	return q
+}
+
+// ContactIDs filters the documents by the identifiers of their contacts
+func (q *Query) ContactIDs(ids ...uuid.UUID) *Query {
+	for _, id := range ids {
+		q.lists[contactIDsParameter] = append(q.lists[contactIDsParameter], id.String())
+	}
+	return q
+}
+
+// Statuses filters the invoices by their statuses
+func (q *Query) Statuses(statuses ...InvoiceStatus) *Query {
+	for _, status := range statuses {
+		q.lists[statusesParameter] = append(q.lists[statusesParameter], string(status))
+	}
+	return q
+}
+
+// UnitDP asks for the unit amounts with the given decimal places, Xero allows
+// 2 (the default) or UnitPlaces
+func (q *Query) UnitDP(places int) *Query {
+	q.parameters[unitDPParameter] = strconv.Itoa(places)
+	return q
+}
+
+// IncludeArchived asks for the archived elements too, supported by the
+// contacts and the tracking categories
+func (q *Query) IncludeArchived() *Query {
+	q.parameters[includeArchivedParameter] = "true"
+	return q
+}
+
+// PaymentsOnly asks only for the journals of payments and cash transactions,
+// supported by the journals
+func (q *Query) PaymentsOnly() *Query {
+	q.parameters[paymentsOnlyParameter] = "true"
+	return q
+}
+
+// Set adds any other querystringParameter e.g. includeArchived or unitdp
+func (q *Query) Set(key string, value string) *Query {
+	q.parameters[key] = value
+	return q
+}
+
+// Params returns the querystringParameters of the query, ready to be used in
+// the Find functions
+func (q *Query) Params() map[string]string {
+	params := map[string]string{}
+	for key, value := range q.parameters {
+		params[key] = value
+	}
+	if where := And(q.where...); where.expr != "" {
+		params[whereParameter] = where.expr
+	}
+	if len(q.order) > 0 {
+		params[orderParameter] = strings.Join(q.order, ",")
+	}
+	for key, values := range q.lists {
+		if len(values) > 0 {
+			params[key] = strings.Join(values, ",")
+		}
+	}
+	return params
+}
@@ -0,0 +1,141 @@
+package accounting
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+type month int
+
+func (m month) String() string {
+	return time.Month(m).String()
+}
+
+func TestFormatLiteral(t *testing.T) {
+	id := uuid.Must(uuid.FromString("297c2dc5-cc47-4afd-8ec8-74990b8761e9"))
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "string", value: "ACME", want: `"ACME"`},
+		{name: "escaped string", value: `say "hi" \o/`, want: `"say \"hi\" \\o/"`},
+		{name: "typed string", value: InvoiceStatusPaid, want: `"PAID"`},
+		{name: "bool", value: true, want: "true"},
+		{name: "int", value: 42, want: "42"},
+		{name: "int8", value: int8(-8), want: "-8"},
+		{name: "int32", value: int32(32), want: "32"},
+		{name: "int64", value: int64(-64), want: "-64"},
+		{name: "uint", value: uint(7), want: "7"},
+		{name: "uint64", value: uint64(18446744073709551615), want: "18446744073709551615"},
+		{name: "float32", value: float32(1.5), want: "1.5"},
+		{name: "float64", value: 19.99, want: "19.99"},
+		{name: "typed int with String", value: month(3), want: "3"},
+		{name: "decimal", value: Decimal("100.50"), want: "100.50"},
+		{name: "uuid", value: id, want: `Guid("297c2dc5-cc47-4afd-8ec8-74990b8761e9")`},
+		{name: "guid", value: Guid(id), want: `Guid("297c2dc5-cc47-4afd-8ec8-74990b8761e9")`},
+		{name: "date", value: time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC), want: "DateTime(2020,03,01)"},
+		{name: "date time", value: time.Date(2020, time.March, 1, 13, 4, 5, 0, time.UTC), want: "DateTime(2020,03,01,13,04,05)"},
+		{name: "raw", value: Raw("null"), want: "null"},
+		{name: "duration", value: time.Second, want: "1000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatLiteral(tt.value); got != tt.want {
+				t.Errorf("FormatLiteral(%#v) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{name: "eq", filter: Field("Status").Eq("AUTHORISED"), want: `Status=="AUTHORISED"`},
+		{name: "ne", filter: Field("Type").Ne(InvoiceTypeAccPay), want: `Type!="ACCPAY"`},
+		{name: "gt", filter: Field("Total").Gt(100), want: "Total>100"},
+		{name: "ge", filter: Field("Total").Ge(Decimal("9.99")), want: "Total>=9.99"},
+		{name: "lt", filter: Field("AmountDue").Lt(float32(0.5)), want: "AmountDue<0.5"},
+		{name: "le", filter: Field("Date").Le(time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)), want: "Date<=DateTime(2020,01,31)"},
+		{name: "contains", filter: Field("Name").Contains(`"Co"`), want: `Name.Contains("\"Co\"")`},
+		{name: "starts with", filter: Field("Name").StartsWith("A"), want: `Name.StartsWith("A")`},
+		{name: "ends with", filter: Field("Name").EndsWith("Ltd"), want: `Name.EndsWith("Ltd")`},
+		{name: "empty and", filter: And(), want: ""},
+		{name: "single and", filter: And(Field("A").Eq(1)), want: "A==1"},
+		{name: "and", filter: And(Field("A").Eq(1), Field("B").Eq(2)), want: "A==1 AND B==2"},
+		{
+			name:   "nested or",
+			filter: And(Field("A").Eq(1), Or(Field("B").Eq(2), Field("C").Eq(3))),
+			want:   "A==1 AND (B==2 OR C==3)",
+		},
+		{name: "empty filters skipped", filter: Or(Filter{}, Field("A").Eq(true), Filter{}), want: "A==true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.String(); got != tt.want {
+				t.Errorf("Filter = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	id1 := uuid.Must(uuid.FromString("297c2dc5-cc47-4afd-8ec8-74990b8761e9"))
+	id2 := uuid.Must(uuid.FromString("ae777a87-5ef3-4fa0-a4f0-d10e1f13073a"))
+	tests := []struct {
+		name  string
+		query *Query
+		want  map[string]string
+	}{
+		{name: "empty", query: NewQuery(), want: map[string]string{}},
+		{
+			name:  "where",
+			query: NewQuery().Where(Field("Status").Eq(InvoiceStatusDraft)).Where(Field("Total").Gt(10)),
+			want:  map[string]string{"where": `Status=="DRAFT" AND Total>10`},
+		},
+		{
+			name:  "order",
+			query: NewQuery().OrderBy("Date").OrderByDesc("Total"),
+			want:  map[string]string{"order": "Date,Total DESC"},
+		},
+		{
+			name:  "IDs and ContactIDs",
+			query: NewQuery().IDs(id1, id2).ContactIDs(id2),
+			want: map[string]string{
+				"IDs":        id1.String() + "," + id2.String(),
+				"ContactIDs": id2.String(),
+			},
+		},
+		{
+			name:  "invoice numbers and statuses",
+			query: NewQuery().InvoiceNumbers("INV-1", "INV-2").Statuses(InvoiceStatusDraft, InvoiceStatusSubmitted),
+			want: map[string]string{
+				"InvoiceNumbers": "INV-1,INV-2",
+				"Statuses":       "DRAFT,SUBMITTED",
+			},
+		},
+		{
+			name:  "parameters",
+			query: NewQuery().UnitDP(UnitPlaces).IncludeArchived().PaymentsOnly().Set("page", "2"),
+			want: map[string]string{
+				"unitdp":          "4",
+				"includeArchived": "true",
+				"paymentsOnly":    "true",
+				"page":            "2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Params(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Params() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,615 @@
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	reportsPath = "Reports"
+
+	accountAttribute = "account"
+)
+
+// Report is a financial report of the organisation. Every report has the same
+// generic structure: a header row with the titles of the columns followed by
+// sections holding the rows, use Table for flattening them
+type Report struct {
+
+	// The identifier of the report e.g. BalanceSheet
+	ReportID string `json:"ReportID,omitempty"`
+
+	// The name of the report e.g. Balance Sheet
+	ReportName string `json:"ReportName,omitempty"`
+
+	// The type of the report e.g. BalanceSheet
+	ReportType string `json:"ReportType,omitempty"`
+
+	// The titles of the report e.g. the name of the organisation and the date
+	ReportTitles []string `json:"ReportTitles,omitempty"`
+
+	// The date of the report as shown by Xero e.g. 30 June 2020
+	ReportDate string `json:"ReportDate,omitempty"`
+
+	// Last modified date UTC format
+	UpdatedDateUTC *DateTime `json:"UpdatedDateUTC,omitempty"`
+
+	// The additional fields of some reports
+	Fields []ReportField `json:"Fields,omitempty"`
+
+	// The header, section and summary rows of the report
+	Rows []ReportRow `json:"Rows,omitempty"`
+}
+
+// ReportField is an additional field of a report
+type ReportField struct {
+	FieldID     string `json:"FieldID,omitempty"`
+	Description string `json:"Description,omitempty"`
+	Value       string `json:"Value,omitempty"`
+}
+
+// ReportRow is a row of a report, a section holds its own rows
+type ReportRow struct {
+
+	// See Report Row Types
+	RowType ReportRowType `json:"RowType,omitempty"`
+
+	// The title of a section
+	Title string `json:"Title,omitempty"`
+
+	// The cells of a header, row or summary row
+	Cells []ReportCell `json:"Cells,omitempty"`
+
+	// The rows of a section
+	Rows []ReportRow `json:"Rows,omitempty"`
+}
+
+// ReportCell is a value of a report row, its attributes identify where it
+// comes from e.g. the account
+type ReportCell struct {
+	Value      string            `json:"Value"`
+	Attributes []ReportAttribute `json:"Attributes,omitempty"`
+}
+
+// ReportAttribute is an attribute of a report cell e.g. {"Id": "account",
+// "Value": "<AccountID>"}
+type ReportAttribute struct {
+	ID    string `json:"Id"`
+	Value string `json:"Value"`
+}
+
+// Attribute returns the value of the attribute of the cell with the given
+// ID, or an empty string
+func (c ReportCell) Attribute(id string) string {
+	for _, a := range c.Attributes {
+		if strings.EqualFold(a.ID, id) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Decimal returns the value of the cell as a Decimal, an empty cell is zero
+func (c ReportCell) Decimal() (Decimal, error) {
+	value := strings.ReplaceAll(strings.TrimSpace(c.Value), ",", "")
+	if value == "" {
+		return Decimal("0"), nil
+	}
+	return ParseDecimal(value)
+}
+
+// Reports is a collection of Reports
+type Reports struct {
+	Reports []Report `json:"Reports"`
+}
+
+func unmarshalReport(reportResponseBytes []byte) (*Reports, error) {
+	var reportResponse *Reports
+	err := json.Unmarshal(reportResponseBytes, &reportResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return reportResponse, err
+}
+
+// FindReport will get the report with the given name e.g. BalanceSheet or
+// TenNinetyNine. The typed functions like FindBalanceSheet should be preferred,
+// this one is for the reports without them and takes the querystringParameters
+// as a map
+func FindReport(cl *http.Client, name string, queryParameters map[string]string) (*Report, error) {
+	return FindReportContext(context.Background(), cl, name, queryParameters)
+}
+
+// FindReportContext is the same as FindReport but the request is bound to the given context
+func FindReportContext(ctx context.Context, cl *http.Client, name string, queryParameters map[string]string) (*Report, error) {
+	reportResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, reportsPath, name), nil, queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	r, err := unmarshalReport(reportResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Reports) > 0 {
+		return &r.Reports[0], nil
+	}
+	return nil, nil
+}
+
+// reportParameters builds the querystringParameters of a report skipping
+// the ones not set
+type reportParameters map[string]string
+
+func (p reportParameters) setDate(key string, d *Date) {
+	if d != nil && !d.IsZero() {
+		p[key] = d.String()
+	}
+}
+
+func (p reportParameters) setInt(key string, n int) {
+	if n != 0 {
+		p[key] = strconv.Itoa(n)
+	}
+}
+
+func (p reportParameters) setString(key string, s string) {
+	if s != "" {
+		p[key] = s
+	}
+}
+
+func (p reportParameters) setBool(key string, b bool) {
+	if b {
+		p[key] = "true"
+	}
+}
+
+// BalanceSheetParameters are the parameters of the balance sheet, all of
+// them are optional
+type BalanceSheetParameters struct {
+	// The date of the Balance Sheet report
+	Date *Date
+	// The number of periods for the Balance Sheet report, up to 11
+	Periods int
+	// The period size to compare to
+	Timeframe ReportTimeframe
+	// The tracking options for filtering the report
+	TrackingOptionID1 string
+	TrackingOptionID2 string
+	// If you set this parameter to true then no custom report layouts will be applied to response
+	StandardLayout bool
+	// Return cash only basis for the Balance Sheet report
+	PaymentsOnly bool
+}
+
+func (p BalanceSheetParameters) params() map[string]string {
+	params := reportParameters{}
+	params.setDate("date", p.Date)
+	params.setInt("periods", p.Periods)
+	params.setString("timeframe", string(p.Timeframe))
+	params.setString("trackingOptionID1", p.TrackingOptionID1)
+	params.setString("trackingOptionID2", p.TrackingOptionID2)
+	params.setBool("standardLayout", p.StandardLayout)
+	params.setBool("paymentsOnly", p.PaymentsOnly)
+	return params
+}
+
+// Validate checks the balance sheet parameters against the rules documented by Xero
+func (p BalanceSheetParameters) Validate() error {
+	r := rules{}
+	r.periods(p.Periods, 11)
+	r.enum("Timeframe", p.Timeframe, p.Timeframe != "")
+	return r.err()
+}
+
+// FindBalanceSheet will get the balance sheet at the end of the month of the
+// given date, or of the current month
+func FindBalanceSheet(cl *http.Client, p BalanceSheetParameters) (*Report, error) {
+	return FindBalanceSheetContext(context.Background(), cl, p)
+}
+
+// FindBalanceSheetContext is the same as FindBalanceSheet but the request is bound to the given context
+func FindBalanceSheetContext(ctx context.Context, cl *http.Client, p BalanceSheetParameters) (*Report, error) {
+	if err := validate(ctx, p); err != nil {
+		return nil, err
+	}
+	return FindReportContext(ctx, cl, "BalanceSheet", p.params())
+}
+
+// ProfitAndLossParameters are the parameters of the profit and loss, all of
+// them are optional
+type ProfitAndLossParameters struct {
+	// The from and to dates of the report, the current month by default
+	FromDate *Date
+	ToDate   *Date
+	// The number of periods to compare, up to 11
+	Periods int
+	// The period size to compare to
+	Timeframe ReportTimeframe
+	// The tracking categories for showing a column per option
+	TrackingCategoryID  string
+	TrackingCategoryID2 string
+	// The tracking options for filtering the report
+	TrackingOptionID  string
+	TrackingOptionID2 string
+	// If you set this parameter to true then no custom report layouts will be applied to response
+	StandardLayout bool
+	// Return cash only basis for the ProfitAndLoss report
+	PaymentsOnly bool
+}
+
+func (p ProfitAndLossParameters) params() map[string]string {
+	params := reportParameters{}
+	params.setDate("fromDate", p.FromDate)
+	params.setDate("toDate", p.ToDate)
+	params.setInt("periods", p.Periods)
+	params.setString("timeframe", string(p.Timeframe))
+	params.setString("trackingCategoryID", p.TrackingCategoryID)
+	params.setString("trackingCategoryID2", p.TrackingCategoryID2)
+	params.setString("trackingOptionID", p.TrackingOptionID)
+	params.setString("trackingOptionID2", p.TrackingOptionID2)
+	params.setBool("standardLayout", p.StandardLayout)
+	params.setBool("paymentsOnly", p.PaymentsOnly)
+	return params
+}
+
+// Validate checks the profit and loss parameters against the rules documented by Xero
+func (p ProfitAndLossParameters) Validate() error {
+	r := rules{}
+	r.periods(p.Periods, 11)
+	r.enum("Timeframe", p.Timeframe, p.Timeframe != "")
+	return r.err()
+}
+
+// FindProfitAndLoss will get the profit and loss of the given period, or of
+// the current month
+func FindProfitAndLoss(cl *http.Client, p ProfitAndLossParameters) (*Report, error) {
+	return FindProfitAndLossContext(context.Background(), cl, p)
+}
+
+// FindProfitAndLossContext is the same as FindProfitAndLoss but the request is bound to the given context
+func FindProfitAndLossContext(ctx context.Context, cl *http.Client, p ProfitAndLossParameters) (*Report, error) {
+	if err := validate(ctx, p); err != nil {
+		return nil, err
+	}
+	return FindReportContext(ctx, cl, "ProfitAndLoss", p.params())
+}
+
+// TrialBalanceParameters are the parameters of the trial balance, all of them
+// are optional
+type TrialBalanceParameters struct {
+	// The date for the Trial Balance report, today by default
+	Date *Date
+	// Return cash only basis for the Trial Balance report
+	PaymentsOnly bool
+}
+
+func (p TrialBalanceParameters) params() map[string]string {
+	params := reportParameters{}
+	params.setDate("date", p.Date)
+	params.setBool("paymentsOnly", p.PaymentsOnly)
+	return params
+}
+
+// FindTrialBalance will get the trial balance at the given date, or today
+func FindTrialBalance(cl *http.Client, p TrialBalanceParameters) (*Report, error) {
+	return FindTrialBalanceContext(context.Background(), cl, p)
+}
+
+// FindTrialBalanceContext is the same as FindTrialBalance but the request is bound to the given context
+func FindTrialBalanceContext(ctx context.Context, cl *http.Client, p TrialBalanceParameters) (*Report, error) {
+	return FindReportContext(ctx, cl, "TrialBalance", p.params())
+}
+
+// AgedReportParameters are the parameters of the aged receivables and
+// payables, the ContactID is required
+type AgedReportParameters struct {
+	// The contact of the report
+	ContactID string
+	// The date of the Aged report, today by default
+	Date *Date
+	// The from and to dates of the invoices shown
+	FromDate *Date
+	ToDate   *Date
+}
+
+func (p AgedReportParameters) params() map[string]string {
+	params := reportParameters{}
+	params.setString("contactID", p.ContactID)
+	params.setDate("date", p.Date)
+	params.setDate("fromDate", p.FromDate)
+	params.setDate("toDate", p.ToDate)
+	return params
+}
+
+// Validate checks the aged report parameters against the rules documented by Xero
+func (p AgedReportParameters) Validate() error {
+	r := rules{}
+	r.required("ContactID", p.ContactID != "")
+	return r.err()
+}
+
+// FindAgedReceivablesByContact will get the invoices owed by the contact
+// grouped by their age
+func FindAgedReceivablesByContact(cl *http.Client, p AgedReportParameters) (*Report, error) {
+	return FindAgedReceivablesByContactContext(context.Background(), cl, p)
+}
+
+// FindAgedReceivablesByContactContext is the same as FindAgedReceivablesByContact but the request is bound to the given context
+func FindAgedReceivablesByContactContext(ctx context.Context, cl *http.Client, p AgedReportParameters) (*Report, error) {
+	if err := validate(ctx, p); err != nil {
+		return nil, err
+	}
+	return FindReportContext(ctx, cl, "AgedReceivablesByContact", p.params())
+}
+
+// FindAgedPayablesByContact will get the bills owed to the contact grouped by
+// their age
+func FindAgedPayablesByContact(cl *http.Client, p AgedReportParameters) (*Report, error) {
+	return FindAgedPayablesByContactContext(context.Background(), cl, p)
+}
+
+// FindAgedPayablesByContactContext is the same as FindAgedPayablesByContact but the request is bound to the given context
+func FindAgedPayablesByContactContext(ctx context.Context, cl *http.Client, p AgedReportParameters) (*Report, error) {
+	if err := validate(ctx, p); err != nil {
+		return nil, err
+	}
+	return FindReportContext(ctx, cl, "AgedPayablesByContact", p.params())
+}
+
+// periods checks the number of periods compared by a report, 0 is not sent
+// and leaves the default of Xero
+func (r *rules) periods(periods int, max int) {
+	if periods < 0 || periods > max {
+		r.add("Periods", "must be between 0 (the default) and %d", max)
+	}
+}
+
+// BankSummaryParameters are the parameters of the bank summary, all of them
+// are optional
+type BankSummaryParameters struct {
+	// The from and to dates of the report, the current month by default
+	FromDate *Date
+	ToDate   *Date
+}
+
+func (p BankSummaryParameters) params() map[string]string {
+	params := reportParameters{}
+	params.setDate("fromDate", p.FromDate)
+	params.setDate("toDate", p.ToDate)
+	return params
+}
+
+// FindBankSummary will get the balances and the cash movements of the bank
+// accounts in the given period, or in the current month
+func FindBankSummary(cl *http.Client, p BankSummaryParameters) (*Report, error) {
+	return FindBankSummaryContext(context.Background(), cl, p)
+}
+
+// FindBankSummaryContext is the same as FindBankSummary but the request is bound to the given context
+func FindBankSummaryContext(ctx context.Context, cl *http.Client, p BankSummaryParameters) (*Report, error) {
+	return FindReportContext(ctx, cl, "BankSummary", p.params())
+}
+
+// BudgetSummaryParameters are the parameters of the budget summary, all of
+// them are optional
+type BudgetSummaryParameters struct {
+	// The date for the Budget Summary report e.g. 2018-03-31
+	Date *Date
+	// The number of periods to compare, up to 12
+	Periods int
+	// The period size to compare to
+	Timeframe ReportTimeframe
+}
+
+func (p BudgetSummaryParameters) params() map[string]string {
+	params := reportParameters{}
+	params.setDate("date", p.Date)
+	params.setInt("periods", p.Periods)
+	// The budget summary takes the number of months of the timeframe
+	params.setInt("timeframe", p.Timeframe.months())
+	return params
+}
+
+// Validate checks the budget summary parameters against the rules documented by Xero
+func (p BudgetSummaryParameters) Validate() error {
+	r := rules{}
+	r.periods(p.Periods, 12)
+	r.enum("Timeframe", p.Timeframe, p.Timeframe != "")
+	return r.err()
+}
+
+// FindBudgetSummary will get the summary of the overall budget of the
+// organisation
+func FindBudgetSummary(cl *http.Client, p BudgetSummaryParameters) (*Report, error) {
+	return FindBudgetSummaryContext(context.Background(), cl, p)
+}
+
+// FindBudgetSummaryContext is the same as FindBudgetSummary but the request is bound to the given context
+func FindBudgetSummaryContext(ctx context.Context, cl *http.Client, p BudgetSummaryParameters) (*Report, error) {
+	if err := validate(ctx, p); err != nil {
+		return nil, err
+	}
+	return FindReportContext(ctx, cl, "BudgetSummary", p.params())
+}
+
+// ExecutiveSummaryParameters are the parameters of the executive summary,
+// all of them are optional
+type ExecutiveSummaryParameters struct {
+	// The date for the Executive Summary report, the current month by default
+	Date *Date
+}
+
+func (p ExecutiveSummaryParameters) params() map[string]string {
+	params := reportParameters{}
+	params.setDate("date", p.Date)
+	return params
+}
+
+// FindExecutiveSummary will get the executive summary of the month of the
+// given date, or of the current month
+func FindExecutiveSummary(cl *http.Client, p ExecutiveSummaryParameters) (*Report, error) {
+	return FindExecutiveSummaryContext(context.Background(), cl, p)
+}
+
+// FindExecutiveSummaryContext is the same as FindExecutiveSummary but the request is bound to the given context
+func FindExecutiveSummaryContext(ctx context.Context, cl *http.Client, p ExecutiveSummaryParameters) (*Report, error) {
+	return FindReportContext(ctx, cl, "ExecutiveSummary", p.params())
+}
+
+// ReportTable is a report flattened as a table: the titles of the columns
+// and a row per account or summary, keyed by their section
+type ReportTable struct {
+	// Columns are the titles of the header row, the first one is usually empty
+	Columns []string
+	Rows    []ReportTableRow
+}
+
+// ReportTableRow is a row of a flattened report
+type ReportTableRow struct {
+	// Section is the title of the section holding the row, empty for the
+	// rows outside of any section
+	Section string
+	// Account is the value of the first cell, e.g. the name of the account or
+	// the title of a summary like Total Bank
+	Account string
+	// AccountID is the identifier of the account of the row, if any
+	AccountID string
+	// Summary is true for the summary rows e.g. the totals of the sections
+	Summary bool
+	// Cells are all the cells of the row, including the first one
+	Cells []ReportCell
+}
+
+// Value returns the cell of the row in the column with the given title, the
+// second value is false when there is no such column
+func (t *ReportTable) Value(row ReportTableRow, column string) (ReportCell, bool) {
+	for n, title := range t.Columns {
+		if title == column && n < len(row.Cells) {
+			return row.Cells[n], true
+		}
+	}
+	return ReportCell{}, false
+}
+
+// Find returns the first row of the section with the given account name or
+// AccountID, the second value is false when it is not found. An empty section
+// matches any section
+func (t *ReportTable) Find(section string, account string) (ReportTableRow, bool) {
+	for _, row := range t.Rows {
+		if section != "" && !strings.EqualFold(row.Section, section) {
+			continue
+		}
+		if strings.EqualFold(row.Account, account) || (row.AccountID != "" && strings.EqualFold(row.AccountID, account)) {
+			return row, true
+		}
+	}
+	return ReportTableRow{}, false
+}
+
+// Table flattens the report into a ReportTable. The rows of the nested
+// sections get the title of the innermost titled section
+func (r *Report) Table() *ReportTable {
+	t := &ReportTable{Columns: []string{}, Rows: []ReportTableRow{}}
+	t.add(r.Rows, "")
+	return t
+}
+
+func (t *ReportTable) add(rows []ReportRow, section string) {
+	for _, row := range rows {
+		switch row.RowType {
+		case ReportRowTypeHeader:
+			if len(t.Columns) == 0 {
+				for _, c := range row.Cells {
+					t.Columns = append(t.Columns, c.Value)
+				}
+			}
+		case ReportRowTypeSection:
+			title := row.Title
+			if title == "" {
+				title = section
+			}
+			t.add(row.Rows, title)
+		default:
+			if len(row.Cells) == 0 {
+				continue
+			}
+			flat := ReportTableRow{
+				Section: section,
+				Account: row.Cells[0].Value,
+				Summary: row.RowType == ReportRowTypeSummaryRow,
+				Cells:   row.Cells,
+			}
+			for _, c := range row.Cells {
+				if id := c.Attribute(accountAttribute); id != "" {
+					flat.AccountID = id
+					break
+				}
+			}
+			t.Rows = append(t.Rows, flat)
+		}
+	}
+}
+
+// ReportRowType is the type of a report row
+type ReportRowType string
+
+// The report row types
+const (
+	ReportRowTypeHeader     ReportRowType = "Header"
+	ReportRowTypeSection    ReportRowType = "Section"
+	ReportRowTypeRow        ReportRowType = "Row"
+	ReportRowTypeSummaryRow ReportRowType = "SummaryRow"
+)
+
+// IsValid reports whether the row type is one of the documented values
+func (v ReportRowType) IsValid() bool {
+	switch v {
+	case ReportRowTypeHeader,
+		ReportRowTypeSection,
+		ReportRowTypeRow,
+		ReportRowTypeSummaryRow:
+		return true
+	}
+	return false
+}
+
+// ReportTimeframe is the size of the periods compared by a report
+type ReportTimeframe string
+
+// The report timeframes
+const (
+	ReportTimeframeMonth   ReportTimeframe = "MONTH"
+	ReportTimeframeQuarter ReportTimeframe = "QUARTER"
+	ReportTimeframeYear    ReportTimeframe = "YEAR"
+)
+
+// IsValid reports whether the timeframe is one of the documented values
+func (v ReportTimeframe) IsValid() bool {
+	switch v {
+	case ReportTimeframeMonth,
+		ReportTimeframeQuarter,
+		ReportTimeframeYear:
+		return true
+	}
+	return false
+}
+
+// months returns the number of months of the timeframe, or 0 when it is not set
+func (v ReportTimeframe) months() int {
+	switch v {
+	case ReportTimeframeMonth:
+		return 1
+	case ReportTimeframeQuarter:
+		return 3
+	case ReportTimeframeYear:
+		return 12
+	}
+	return 0
+}
@@ -0,0 +1,128 @@
+package accounting
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+)
+
+const bankAccountID = "13918178-849a-4823-9a31-57b7eac713d7"
+
+// balanceSheet is a report with the layout of the Xero Balance Sheet: a
+// header, titled sections with nested untitled ones and summary rows
+func balanceSheet() Report {
+	cells := func(values ...string) []ReportCell {
+		c := make([]ReportCell, len(values))
+		for n, v := range values {
+			c[n] = ReportCell{Value: v}
+		}
+		return c
+	}
+	bank := cells("Business Bank Account", "12,500.00", "9,000.00")
+	for n := range bank {
+		bank[n].Attributes = []ReportAttribute{{ID: "account", Value: bankAccountID}}
+	}
+	return Report{
+		ReportID:   "BalanceSheet",
+		ReportName: "Balance Sheet",
+		ReportType: "BalanceSheet",
+		Rows: []ReportRow{
+			{RowType: ReportRowTypeHeader, Cells: cells("", "31 Mar 2020", "31 Mar 2019")},
+			{RowType: ReportRowTypeSection, Title: "Assets"},
+			{RowType: ReportRowTypeSection, Title: "Bank", Rows: []ReportRow{
+				{RowType: ReportRowTypeRow, Cells: bank},
+				{RowType: ReportRowTypeSummaryRow, Cells: cells("Total Bank", "12,500.00", "9,000.00")},
+			}},
+			{RowType: ReportRowTypeSection, Title: "Current Assets", Rows: []ReportRow{
+				{RowType: ReportRowTypeRow, Cells: cells("Accounts Receivable", "-1,200.50", "")},
+				{RowType: ReportRowTypeSection, Rows: []ReportRow{
+					{RowType: ReportRowTypeRow, Cells: cells("Inventory", "300.00", "250.00")},
+				}},
+			}},
+			{RowType: ReportRowTypeSection, Rows: []ReportRow{
+				{RowType: ReportRowTypeRow},
+				{RowType: ReportRowTypeSummaryRow, Cells: cells("Net Assets", "11,599.50", "9,250.00")},
+			}},
+		},
+	}
+}
+
+func TestReportTable(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	if err := s.Seed(tenantID, "Reports", balanceSheet()); err != nil {
+		t.Fatal(err)
+	}
+	cl := s.Client(tenantID)
+	ctx := helpers.WithEndpoints(context.Background(), s.Endpoints())
+
+	report, err := FindBalanceSheetContext(ctx, cl, BalanceSheetParameters{Date: NewDate(2020, time.March, 31), Periods: 1})
+	if err != nil {
+		t.Fatalf("FindBalanceSheetContext() error = %v", err)
+	}
+	table := report.Table()
+	if want := []string{"", "31 Mar 2020", "31 Mar 2019"}; !reflect.DeepEqual(table.Columns, want) {
+		t.Errorf("Columns = %q, want %q", table.Columns, want)
+	}
+	type flat struct {
+		section   string
+		account   string
+		accountID string
+		summary   bool
+	}
+	var rows []flat
+	for _, row := range table.Rows {
+		rows = append(rows, flat{row.Section, row.Account, row.AccountID, row.Summary})
+	}
+	want := []flat{
+		{"Bank", "Business Bank Account", bankAccountID, false},
+		{"Bank", "Total Bank", "", true},
+		{"Current Assets", "Accounts Receivable", "", false},
+		{"Current Assets", "Inventory", "", false},
+		{"", "Net Assets", "", true},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Rows = %+v, want %+v", rows, want)
+	}
+
+	tests := []struct {
+		section string
+		account string
+		column  string
+		want    Decimal
+	}{
+		{section: "Bank", account: "Business Bank Account", column: "31 Mar 2020", want: "12500"},
+		{account: bankAccountID, column: "31 Mar 2019", want: "9000"},
+		{section: "current assets", account: "accounts receivable", column: "31 Mar 2020", want: "-1200.5"},
+		{section: "Current Assets", account: "Accounts Receivable", column: "31 Mar 2019", want: "0"},
+		{account: "Net Assets", column: "31 Mar 2020", want: "11599.5"},
+	}
+	for _, tt := range tests {
+		row, ok := table.Find(tt.section, tt.account)
+		if !ok {
+			t.Errorf("Find(%q, %q) found nothing", tt.section, tt.account)
+			continue
+		}
+		cell, ok := table.Value(row, tt.column)
+		if !ok {
+			t.Errorf("Value(%q, %q) found nothing", tt.account, tt.column)
+			continue
+		}
+		got, err := cell.Decimal()
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("Value(%q, %q) = %s %v, want %s", tt.account, tt.column, got, err, tt.want)
+		}
+	}
+	if _, ok := table.Find("Bank", "Inventory"); ok {
+		t.Errorf("Find(Bank, Inventory) found the row of another section")
+	}
+	row, _ := table.Find("", "Inventory")
+	if _, ok := table.Value(row, "31 Dec 2020"); ok {
+		t.Errorf("Value() found a missing column")
+	}
+}
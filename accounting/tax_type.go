@@ -0,0 +1,89 @@
+package accounting
+
+import "regexp"
+
+var customTaxType = regexp.MustCompile(`^TAX\d{3}$`)
+
+// TaxType is the tax rate applied to an account, an item or a line item. The
+// available values depend on the country of the organisation, and each one
+// has also its own rates with codes like TAX001, so it is an open set that
+// validation never rejects
+type TaxType string
+
+// The tax types
+const (
+	TaxTypeNone               TaxType = "NONE"
+	TaxTypeInput              TaxType = "INPUT"
+	TaxTypeOutput             TaxType = "OUTPUT"
+	TaxTypeExemptInput        TaxType = "EXEMPTINPUT"
+	TaxTypeExemptOutput       TaxType = "EXEMPTOUTPUT"
+	TaxTypeExemptExpenses     TaxType = "EXEMPTEXPENSES"
+	TaxTypeExemptIncome       TaxType = "EXEMPTINCOME"
+	TaxTypeExemptCapital      TaxType = "EXEMPTCAPITAL"
+	TaxTypeExemptExport       TaxType = "EXEMPTEXPORT"
+	TaxTypeBASExcluded        TaxType = "BASEXCLUDED"
+	TaxTypeGSTOnImports       TaxType = "GSTONIMPORTS"
+	TaxTypeGSTOnCapImports    TaxType = "GSTONCAPIMPORTS"
+	TaxTypeInputTaxed         TaxType = "INPUTTAXED"
+	TaxTypeCapexInput         TaxType = "CAPEXINPUT"
+	TaxTypeCapexInput2        TaxType = "CAPEXINPUT2"
+	TaxTypeZeroRatedInput     TaxType = "ZERORATEDINPUT"
+	TaxTypeZeroRatedOutput    TaxType = "ZERORATEDOUTPUT"
+	TaxTypeECZRInput          TaxType = "ECZRINPUT"
+	TaxTypeECZROutput         TaxType = "ECZROUTPUT"
+	TaxTypeECZROutputServices TaxType = "ECZROUTPUTSERVICES"
+	TaxTypeRRInput            TaxType = "RRINPUT"
+	TaxTypeRROutput           TaxType = "RROUTPUT"
+	TaxTypeSRInput            TaxType = "SRINPUT"
+	TaxTypeSROutput           TaxType = "SROUTPUT"
+	TaxTypeInput2             TaxType = "INPUT2"
+	TaxTypeOutput2            TaxType = "OUTPUT2"
+	TaxTypeReverseCharges     TaxType = "REVERSECHARGES"
+	TaxTypeECAcquisitions     TaxType = "ECACQUISITIONS"
+	TaxTypeDRChargeSupply20   TaxType = "DRCHARGESUPPLY20"
+	TaxTypeECOutput           TaxType = "ECOUTPUT"
+	TaxTypeBLInput            TaxType = "BLINPUT"
+	TaxTypeIMInput2           TaxType = "IMINPUT2"
+)
+
+// IsValid reports whether the tax type is one of the constants above or a
+// rate of the organisation. The list is not complete for every country, a
+// false result does not mean Xero rejects the tax type
+func (v TaxType) IsValid() bool {
+	switch v {
+	case TaxTypeNone,
+		TaxTypeInput,
+		TaxTypeOutput,
+		TaxTypeExemptInput,
+		TaxTypeExemptOutput,
+		TaxTypeExemptExpenses,
+		TaxTypeExemptIncome,
+		TaxTypeExemptCapital,
+		TaxTypeExemptExport,
+		TaxTypeBASExcluded,
+		TaxTypeGSTOnImports,
+		TaxTypeGSTOnCapImports,
+		TaxTypeInputTaxed,
+		TaxTypeCapexInput,
+		TaxTypeCapexInput2,
+		TaxTypeZeroRatedInput,
+		TaxTypeZeroRatedOutput,
+		TaxTypeECZRInput,
+		TaxTypeECZROutput,
+		TaxTypeECZROutputServices,
+		TaxTypeRRInput,
+		TaxTypeRROutput,
+		TaxTypeSRInput,
+		TaxTypeSROutput,
+		TaxTypeInput2,
+		TaxTypeOutput2,
+		TaxTypeReverseCharges,
+		TaxTypeECAcquisitions,
+		TaxTypeDRChargeSupply20,
+		TaxTypeECOutput,
+		TaxTypeBLInput,
+		TaxTypeIMInput2:
+		return true
+	}
+	return customTaxType.MatchString(string(v))
+}
@@ -0,0 +1,57 @@
+package accounting
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEnumIsValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{ IsValid() bool }
+		want  bool
+	}{
+		{name: "invoice type", value: InvoiceTypeAccRec, want: true},
+		{name: "unknown invoice type", value: InvoiceType("ACCRECCREDIT"), want: false},
+		{name: "invoice status", value: InvoiceStatusAuthorised, want: true},
+		{name: "lower case invoice status", value: InvoiceStatus("authorised"), want: false},
+		{name: "account class", value: AccountClassRevenue, want: true},
+		{name: "unknown account class", value: AccountClass("INCOME"), want: false},
+		{name: "payment type", value: PaymentTypeAccRecPayment, want: true},
+		{name: "empty payment status", value: PaymentStatus(""), want: false},
+		{name: "tax type", value: TaxTypeOutput, want: true},
+		{name: "tax rate of the organisation", value: TaxType("TAX001"), want: true},
+		{name: "malformed tax rate", value: TaxType("TAX1"), want: false},
+		{name: "unknown tax type", value: TaxType("VAT"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.value.IsValid(); got != tt.want {
+				t.Errorf("%q IsValid() = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnumJSON(t *testing.T) {
+	// the values added by Xero later are decoded as they are, only the
+	// validation rejects them
+	var invoice Invoice
+	if err := json.Unmarshal([]byte(`{"Type":"ACCREC","Status":"ARCHIVED","LineItems":[{"TaxType":"TAX002"}]}`), &invoice); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if invoice.Type != InvoiceTypeAccRec || invoice.Status != InvoiceStatus("ARCHIVED") || invoice.LineItems[0].TaxType != TaxType("TAX002") {
+		t.Errorf("Unmarshal() = %s %s %s, want the values of the JSON", invoice.Type, invoice.Status, invoice.LineItems[0].TaxType)
+	}
+	buf, err := json.Marshal(Invoice{Type: InvoiceTypeAccPay, Status: InvoiceStatusDraft})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["Type"] != "ACCPAY" || fields["Status"] != "DRAFT" {
+		t.Errorf("Marshal() = %s, want the Type and the Status as strings", buf)
+	}
+}
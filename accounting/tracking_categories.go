@@ -1,5 +1,19 @@
 package accounting
 
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	trackingCategoriesPath = "TrackingCategories"
+	trackingOptionsPath    = "Options"
+)
+
 //TrackingCategory is used to segment data within a Xero organisation
 type TrackingCategory struct {
 
@@ -10,13 +24,235 @@ type TrackingCategory struct {
 	Name string `json:"Name,omitempty"`
 
 	// The status of a tracking category
-	Status string `json:"Status,omitempty"`
+	Status TrackingStatus `json:"Status,omitempty"`
 
 	// See Tracking Options
 	Options []TrackingOption `json:"Options,omitempty"`
+
+	// The name of the option set on a line item or a journal line e.g. North
+	Option string `json:"Option,omitempty"`
 }
 
 //TrackingCategories is a collection of TrackingCategories
 type TrackingCategories struct {
 	TrackingCategories []TrackingCategory `json:"TrackingCategories"`
 }
+
+func unmarshalTrackingCategory(trackingCategoryResponseBytes []byte) (*TrackingCategories, error) {
+	var trackingCategoryResponse *TrackingCategories
+	err := json.Unmarshal(trackingCategoryResponseBytes, &trackingCategoryResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return trackingCategoryResponse, err
+}
+
+// FindTrackingCategories will get the active tracking categories with their
+// options. Additional querystringParameters such as where, order and
+// includeArchived (see Query.IncludeArchived) can be added as a map
+func FindTrackingCategories(cl *http.Client, queryParameters map[string]string) (*TrackingCategories, error) {
+	return FindTrackingCategoriesContext(context.Background(), cl, queryParameters)
+}
+
+// FindTrackingCategoriesContext is the same as FindTrackingCategories but the request is bound to the given context
+func FindTrackingCategoriesContext(ctx context.Context, cl *http.Client, queryParameters map[string]string) (*TrackingCategories, error) {
+	trackingCategoryResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, trackingCategoriesPath), nil, queryParameters)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalTrackingCategory(trackingCategoryResponseBytes)
+}
+
+// FindTrackingCategory will get a single tracking category - trackingCategoryID must be a GUID for a tracking category
+func FindTrackingCategory(cl *http.Client, trackingCategoryID uuid.UUID) (*TrackingCategory, error) {
+	return FindTrackingCategoryContext(context.Background(), cl, trackingCategoryID)
+}
+
+// FindTrackingCategoryContext is the same as FindTrackingCategory but the request is bound to the given context
+func FindTrackingCategoryContext(ctx context.Context, cl *http.Client, trackingCategoryID uuid.UUID) (*TrackingCategory, error) {
+	trackingCategoryResponseBytes, err := helpers.FindContext(ctx, cl, helpers.AccountingURL(ctx, trackingCategoriesPath, trackingCategoryID.String()), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	t, err := unmarshalTrackingCategory(trackingCategoryResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.TrackingCategories) > 0 {
+		return &t.TrackingCategories[0], nil
+	}
+	return nil, nil
+}
+
+// Create will create the tracking category, Xero only creates one at a time.
+// Only its Name and Status are sent, its options must be added with AddOption
+func (t *TrackingCategory) Create(cl *http.Client) (*TrackingCategories, error) {
+	return t.CreateContext(context.Background(), cl)
+}
+
+// CreateContext is the same as Create but the request is bound to the given context
+func (t *TrackingCategory) CreateContext(ctx context.Context, cl *http.Client) (*TrackingCategories, error) {
+	if err := validate(ctx, t); err != nil {
+		return nil, err
+	}
+	buf, err := json.Marshal(TrackingCategory{Name: t.Name, Status: t.Status})
+	if err != nil {
+		return nil, err
+	}
+	trackingCategoryResponseBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, trackingCategoriesPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalTrackingCategory(trackingCategoryResponseBytes)
+}
+
+// Update will rename the tracking category or change its status, e.g. to
+// TrackingStatusArchived
+func (t *TrackingCategory) Update(cl *http.Client) (*TrackingCategories, error) {
+	return t.UpdateContext(context.Background(), cl)
+}
+
+// UpdateContext is the same as Update but the request is bound to the given context
+func (t *TrackingCategory) UpdateContext(ctx context.Context, cl *http.Client) (*TrackingCategories, error) {
+	if err := validate(ctx, t); err != nil {
+		return nil, err
+	}
+	buf, err := json.Marshal(TrackingCategory{Name: t.Name, Status: t.Status})
+	if err != nil {
+		return nil, err
+	}
+	trackingCategoryResponseBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, trackingCategoriesPath, t.TrackingCategoryID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalTrackingCategory(trackingCategoryResponseBytes)
+}
+
+// Patch will update only the given fields of the tracking category, e.g.
+// Patch(cl, "Status") to archive it without sending the options
+func (t *TrackingCategory) Patch(cl *http.Client, fields ...string) (*TrackingCategories, error) {
+	return t.PatchContext(context.Background(), cl, fields...)
+}
+
+// PatchContext is the same as Patch but the request is bound to the given context
+func (t *TrackingCategory) PatchContext(ctx context.Context, cl *http.Client, fields ...string) (*TrackingCategories, error) {
+	buf, err := patchBody("TrackingCategories", "TrackingCategoryID", t, fields)
+	if err != nil {
+		return nil, err
+	}
+	buf, err = helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, trackingCategoriesPath, t.TrackingCategoryID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalTrackingCategory(buf)
+}
+
+// RemoveTrackingCategory will delete the tracking category with the given ID,
+// only the ones that haven't been used can be deleted, the others must be
+// archived
+func RemoveTrackingCategory(cl *http.Client, trackingCategoryID uuid.UUID) (*TrackingCategories, error) {
+	return RemoveTrackingCategoryContext(context.Background(), cl, trackingCategoryID)
+}
+
+// RemoveTrackingCategoryContext is the same as RemoveTrackingCategory but the request is bound to the given context
+func RemoveTrackingCategoryContext(ctx context.Context, cl *http.Client, trackingCategoryID uuid.UUID) (*TrackingCategories, error) {
+	trackingCategoryResponseBytes, err := helpers.RemoveContext(ctx, cl, helpers.AccountingURL(ctx, trackingCategoriesPath, trackingCategoryID.String()))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalTrackingCategory(trackingCategoryResponseBytes)
+}
+
+// AddOption will add the given option to the tracking category, the option
+// needs its Name
+func (t *TrackingCategory) AddOption(cl *http.Client, option TrackingOption) (*Options, error) {
+	return t.AddOptionContext(context.Background(), cl, option)
+}
+
+// AddOptionContext is the same as AddOption but the request is bound to the given context
+func (t *TrackingCategory) AddOptionContext(ctx context.Context, cl *http.Client, option TrackingOption) (*Options, error) {
+	if err := validate(ctx, newOption(option)); err != nil {
+		return nil, err
+	}
+	buf, err := json.Marshal(TrackingOption{Name: option.Name})
+	if err != nil {
+		return nil, err
+	}
+	optionResponseBytes, err := helpers.CreateContext(ctx, cl, helpers.AccountingURL(ctx, trackingCategoriesPath, t.TrackingCategoryID, trackingOptionsPath), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalOption(optionResponseBytes)
+}
+
+// UpdateOption will rename the given option of the tracking category or
+// change its status, e.g. to TrackingStatusArchived. The option needs its
+// TrackingOptionID, even when the validation is not enabled
+func (t *TrackingCategory) UpdateOption(cl *http.Client, option TrackingOption) (*Options, error) {
+	return t.UpdateOptionContext(context.Background(), cl, option)
+}
+
+// UpdateOptionContext is the same as UpdateOption but the request is bound to the given context
+func (t *TrackingCategory) UpdateOptionContext(ctx context.Context, cl *http.Client, option TrackingOption) (*Options, error) {
+	if option.TrackingOptionID == "" {
+		return nil, missing("TrackingOptionID")
+	}
+	if err := validate(ctx, &option); err != nil {
+		return nil, err
+	}
+	buf, err := json.Marshal(TrackingOption{Name: option.Name, Status: option.Status})
+	if err != nil {
+		return nil, err
+	}
+	optionResponseBytes, err := helpers.UpdateContext(ctx, cl, helpers.AccountingURL(ctx, trackingCategoriesPath, t.TrackingCategoryID, trackingOptionsPath, option.TrackingOptionID), buf)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalOption(optionResponseBytes)
+}
+
+// RemoveOption will delete the option with the given ID from the tracking
+// category, only the options that haven't been used can be deleted
+func (t *TrackingCategory) RemoveOption(cl *http.Client, trackingOptionID uuid.UUID) (*Options, error) {
+	return t.RemoveOptionContext(context.Background(), cl, trackingOptionID)
+}
+
+// RemoveOptionContext is the same as RemoveOption but the request is bound to the given context
+func (t *TrackingCategory) RemoveOptionContext(ctx context.Context, cl *http.Client, trackingOptionID uuid.UUID) (*Options, error) {
+	optionResponseBytes, err := helpers.RemoveContext(ctx, cl, helpers.AccountingURL(ctx, trackingCategoriesPath, t.TrackingCategoryID, trackingOptionsPath, trackingOptionID.String()))
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalOption(optionResponseBytes)
+}
+
+// TrackingStatus is the status of a tracking category or option
+type TrackingStatus string
+
+// The tracking statuses
+const (
+	TrackingStatusActive   TrackingStatus = "ACTIVE"
+	TrackingStatusArchived TrackingStatus = "ARCHIVED"
+	TrackingStatusDeleted  TrackingStatus = "DELETED"
+)
+
+// IsValid reports whether the status is one of the documented values
+func (v TrackingStatus) IsValid() bool {
+	switch v {
+	case TrackingStatusActive,
+		TrackingStatusArchived,
+		TrackingStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// Validate checks the tracking category against the rules documented by Xero
+func (t *TrackingCategory) Validate() error {
+	r := rules{}
+	r.required("Name", t.Name != "" || t.TrackingCategoryID != "")
+	r.maxLength("Name", t.Name, 100)
+	r.enum("Status", t.Status, t.Status != "")
+	return r.err()
+}
@@ -0,0 +1,70 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+)
+
+func TestTrackingOptions(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	cl := s.Client(s.TenantID())
+	ctx := WithValidation(helpers.WithEndpoints(context.Background(), s.Endpoints()))
+
+	category := TrackingCategory{Name: "Region"}
+	created, err := category.CreateContext(ctx, cl)
+	if err != nil {
+		t.Fatalf("CreateContext() error = %v", err)
+	}
+	category = created.TrackingCategories[0]
+	categoryID := uuid.Must(uuid.FromString(category.TrackingCategoryID))
+
+	options := map[string]string{}
+	for _, name := range []string{"North", "South"} {
+		added, err := category.AddOptionContext(ctx, cl, TrackingOption{Name: name})
+		if err != nil {
+			t.Fatalf("AddOptionContext(%s) error = %v", name, err)
+		}
+		if len(added.Options) != 1 || added.Options[0].TrackingOptionID == "" || added.Options[0].Name != name {
+			t.Fatalf("AddOptionContext(%s) = %+v, want the added option", name, added)
+		}
+		options[name] = added.Options[0].TrackingOptionID
+	}
+
+	renamed, err := category.UpdateOptionContext(ctx, cl, TrackingOption{TrackingOptionID: options["North"], Name: "North East"})
+	if err != nil {
+		t.Fatalf("UpdateOptionContext() error = %v", err)
+	}
+	if len(renamed.Options) != 1 || renamed.Options[0].Name != "North East" || renamed.Options[0].Status != TrackingStatusActive {
+		t.Errorf("UpdateOptionContext() = %+v, want the option renamed", renamed)
+	}
+	if _, err := category.RemoveOptionContext(ctx, cl, uuid.Must(uuid.FromString(options["South"]))); err != nil {
+		t.Fatalf("RemoveOptionContext() error = %v", err)
+	}
+
+	found, err := FindTrackingCategoryContext(ctx, cl, categoryID)
+	if err != nil {
+		t.Fatalf("FindTrackingCategoryContext() error = %v", err)
+	}
+	if len(found.Options) != 1 || found.Options[0].TrackingOptionID != options["North"] || found.Options[0].Name != "North East" {
+		t.Errorf("options = %+v, want North East only", found.Options)
+	}
+
+	// the options without a name or an ID aren't sent
+	sent := len(s.Requests())
+	if _, err := category.AddOptionContext(ctx, cl, TrackingOption{}); !errors.Is(err, helpers.ErrValidation) {
+		t.Errorf("AddOptionContext() error = %v, want a validation error", err)
+	}
+	noValidation := helpers.WithEndpoints(context.Background(), s.Endpoints())
+	if _, err := category.UpdateOptionContext(noValidation, cl, TrackingOption{Name: "West"}); !errors.Is(err, helpers.ErrValidation) {
+		t.Errorf("UpdateOptionContext() error = %v, want a validation error", err)
+	}
+	if got := len(s.Requests()); got != sent {
+		t.Errorf("%d requests sent, want none", got-sent)
+	}
+}
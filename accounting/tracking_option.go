@@ -1,5 +1,9 @@
 package accounting
 
+import (
+	"encoding/json"
+)
+
 //TrackingOption is an option from within a Tracking category
 type TrackingOption struct {
 
@@ -10,7 +14,7 @@ type TrackingOption struct {
 	Name string `json:"Name,omitempty"`
 
 	// The status of a tracking option
-	Status string `json:"Status,omitempty"`
+	Status TrackingStatus `json:"Status,omitempty"`
 
 	// Filter by a tracking categorye.g. 297c2dc5-cc47-4afd-8ec8-74990b8761e9
 	TrackingCategoryID string `json:"TrackingCategoryID,omitempty"`
@@ -20,3 +24,35 @@ type TrackingOption struct {
 type Options struct {
 	Options []TrackingOption `json:"Options,omitempty"`
 }
+
+func unmarshalOption(optionResponseBytes []byte) (*Options, error) {
+	var optionResponse *Options
+	err := json.Unmarshal(optionResponseBytes, &optionResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return optionResponse, err
+}
+
+// Validate checks the tracking option updated with UpdateOption against the
+// rules documented by Xero, it needs its TrackingOptionID
+func (o *TrackingOption) Validate() error {
+	r := rules{}
+	r.required("TrackingOptionID", o.TrackingOptionID != "")
+	r.maxLength("Name", o.Name, 50)
+	r.enum("Status", o.Status, o.Status != "")
+	return r.err()
+}
+
+// newOption is the check of an option added to a tracking category, which
+// needs its Name since it doesn't have an ID yet
+type newOption TrackingOption
+
+func (o newOption) Validate() error {
+	r := rules{}
+	r.required("Name", o.Name != "")
+	r.maxLength("Name", o.Name, 50)
+	r.enum("Status", o.Status, o.Status != "")
+	return r.err()
+}
@@ -0,0 +1,129 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+type validationKey struct{}
+
+// WithValidation returns a context making the Create and Update functions
+// validate the payloads before sending them, so the invalid ones fail without
+// reaching Xero
+func WithValidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, validationKey{}, true)
+}
+
+// validator is implemented by every payload with a Validate method
+type validator interface {
+	Validate() error
+}
+
+// validate runs the validation of the payload when the context asks for it
+func validate(ctx context.Context, v validator) error {
+	if enabled, _ := ctx.Value(validationKey{}).(bool); !enabled {
+		return nil
+	}
+	return v.Validate()
+}
+
+// FieldError is a field of a payload breaking one of the rules of Xero. The
+// Field is the path of the field in the JSON payload, e.g.
+// Invoices[0].LineItems[1].Description
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error method will return the field with its error message
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors are all the FieldErrors found in a payload, it matches
+// helpers.ErrValidation with errors.Is like the validation errors of Xero
+type ValidationErrors []FieldError
+
+// Error method will return all the errors in a single line
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for n, e := range v {
+		messages[n] = e.Error()
+	}
+	return "xero: invalid payload: " + strings.Join(messages, "; ")
+}
+
+// Is method will make errors.Is(err, helpers.ErrValidation) true
+func (v ValidationErrors) Is(target error) bool {
+	return target == helpers.ErrValidation
+}
+
+// rules collects the FieldErrors of a payload
+type rules struct {
+	errs ValidationErrors
+}
+
+func (r *rules) add(field string, format string, args ...interface{}) {
+	r.errs = append(r.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *rules) required(field string, present bool) {
+	if !present {
+		r.add(field, "is required")
+	}
+}
+
+func (r *rules) maxLength(field string, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		r.add(field, "must be at most %d characters long", max)
+	}
+}
+
+// enum checks the value when it is set
+func (r *rules) enum(field string, value interface {
+	IsValid() bool
+}, set bool) {
+	if set && !value.IsValid() {
+		r.add(field, "has an unknown value %q", value)
+	}
+}
+
+// nested adds the errors of an element of the payload under the given field
+func (r *rules) nested(field string, err error) {
+	if errs, ok := err.(ValidationErrors); ok {
+		for _, e := range errs {
+			r.errs = append(r.errs, FieldError{Field: field + "." + e.Field, Message: e.Message})
+		}
+	} else if err != nil {
+		r.add(field, "%s", err.Error())
+	}
+}
+
+// err returns nil when there are no errors, so it can be compared with nil
+func (r *rules) err() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return r.errs
+}
+
+// missing returns the error of a field the URL of a request is built with,
+// which is checked even when the validation is not enabled
+func missing(field string) error {
+	r := rules{}
+	r.required(field, false)
+	return r.err()
+}
+
+// validateAll validates each element of a collection under the given field
+func validateAll(field string, n int, element func(int) validator) error {
+	r := rules{}
+	for i := 0; i < n; i++ {
+		r.nested(fmt.Sprintf("%s[%d]", field, i), element(i).Validate())
+	}
+	return r.err()
+}
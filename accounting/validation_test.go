@@ -0,0 +1,128 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quickaco/xerosdk/helpers"
+	"github.com/quickaco/xerosdk/xerotest"
+)
+
+func TestInvoicesValidate(t *testing.T) {
+	contact := Contact{ContactID: "3e776c4b-ea9e-4bb1-96be-6b0c7a71a37f"}
+	lines := []LineItem{{Description: "Consulting", Quantity: "1", UnitAmount: "100"}}
+	tests := []struct {
+		name     string
+		invoices Invoices
+		want     []string
+	}{
+		{
+			name:     "valid",
+			invoices: Invoices{Invoices: []Invoice{{Type: InvoiceTypeAccRec, Contact: contact, LineItems: lines}}},
+		},
+		{
+			name:     "draft without lines",
+			invoices: Invoices{Invoices: []Invoice{{Type: InvoiceTypeAccRec, Contact: contact, Status: InvoiceStatusDraft}}},
+		},
+		{
+			name:     "new invoice",
+			invoices: Invoices{Invoices: []Invoice{{}}},
+			want:     []string{"Invoices[0].Type", "Invoices[0].Contact", "Invoices[0].LineItems"},
+		},
+		{
+			name:     "existing invoice",
+			invoices: Invoices{Invoices: []Invoice{{InvoiceID: "6a539484-9a5f-41e3-a2c1-a6be5d4f8af0", Reference: "Order 2"}}},
+		},
+		{
+			name: "nested fields",
+			invoices: Invoices{Invoices: []Invoice{
+				{Type: InvoiceTypeAccRec, Contact: contact, LineItems: lines},
+				{Type: InvoiceTypeAccPay, Contact: contact, Status: "APPROVED", LineItems: []LineItem{
+					{Description: "Consulting"},
+					{Quantity: "1", DiscountRate: "10"},
+				}},
+			}},
+			want: []string{
+				"Invoices[1].Status",
+				"Invoices[1].LineItems[1].Description",
+				"Invoices[1].LineItems[1].DiscountRate",
+			},
+		},
+		{
+			name: "dates and lengths",
+			invoices: Invoices{Invoices: []Invoice{{
+				Type:      InvoiceTypeAccRec,
+				Contact:   contact,
+				LineItems: lines,
+				Reference: strings.Repeat("é", 256),
+				Date:      NewDate(2020, time.March, 2),
+				DueDate:   NewDate(2020, time.March, 1),
+			}}},
+			want: []string{"Invoices[0].Reference", "Invoices[0].DueDate"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.invoices.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want no error", err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) || !errors.Is(err, helpers.ErrValidation) {
+				t.Fatalf("Validate() = %v, want ValidationErrors", err)
+			}
+			var fields []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("Validate() fields = %v, want %v (%v)", fields, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestWithValidation(t *testing.T) {
+	s := xerotest.NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	// the server rejects the contacts without a name like Xero
+	s.Validate = func(path string, element map[string]interface{}) []string {
+		if name, _ := element["Name"].(string); path == "Contacts" && name == "" {
+			return []string{"The contact name must be specified"}
+		}
+		return nil
+	}
+	cl := s.Client(tenantID)
+	ctx := helpers.WithEndpoints(context.Background(), s.Endpoints())
+	contacts := Contacts{Contacts: []Contact{{EmailAddress: "ap@example.com"}}}
+
+	// without the validation the payload reaches the server
+	_, err := contacts.CreateContext(ctx, cl)
+	if !errors.Is(err, helpers.ErrValidation) || !strings.Contains(err.Error(), "The contact name must be specified") {
+		t.Errorf("CreateContext() error = %v, want the validation error of the server", err)
+	}
+	sent := len(s.Requests())
+	if sent != 1 {
+		t.Fatalf("%d requests sent, want 1", sent)
+	}
+
+	_, err = contacts.CreateContext(WithValidation(ctx), cl)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "Contacts[0].Name" {
+		t.Errorf("CreateContext() error = %v, want the Name required", err)
+	}
+	if got := len(s.Requests()); got != sent {
+		t.Errorf("%d requests sent with the validation, want none", got-sent)
+	}
+	if got := len(s.Records(tenantID, "Contacts")); got != 0 {
+		t.Errorf("%d contacts saved, want none", got)
+	}
+}
@@ -5,13 +5,11 @@ import (
 	"net/http"
 
 	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/helpers"
 	"golang.org/x/oauth2"
 )
 
 const (
-	authURL  = "https://login.xero.com/identity/connect/authorize"
-	tokenURL = "https://identity.xero.com/connect/token"
-
 	tenantIDHeader = "xero-tenant-id"
 )
 
@@ -22,32 +20,58 @@ type Config struct {
 	ClientSecret string
 	Scopes       []string
 	RedirectURL  string
+
+	// Transport used under the XeroTransport by the clients built with the
+	// Provider, e.g. a shared helpers.RateLimitTransport. Defaults to
+	// http.DefaultTransport
+	Transport http.RoundTripper
+
+	// RetryPolicy, if set, is applied to all the calls done with the clients
+	// built with the Provider and to the calls to the token endpoint
+	RetryPolicy *helpers.RetryPolicy
+
+	// Endpoints overrides the URLs of the Xero APIs, only Authorize and Token
+	// are used by the Provider. The empty ones use the default URLs, which can
+	// be set with the XERO_*_URL environment variables
+	Endpoints helpers.Endpoints
 }
 
 // Provider type will keep the minimum structure for make the connection
 // between quicka and Xero
 type Provider struct {
-	conf *oauth2.Config
-	ctx  context.Context
+	conf      *oauth2.Config
+	ctx       context.Context
+	transport http.RoundTripper
+	retry     *helpers.RetryPolicy
+	endpoints helpers.Endpoints
 }
 
 // NewProvider function will build a new Provider with the given criteria
 func NewProvider(c Config) *Provider {
+	endpoints := c.Endpoints.WithDefaults()
 	return &Provider{
 		conf: &oauth2.Config{
 			ClientID:     c.ClientID,
 			ClientSecret: c.ClientSecret,
 			Scopes:       c.Scopes,
 			Endpoint: oauth2.Endpoint{
-				AuthURL:  authURL,
-				TokenURL: tokenURL,
+				AuthURL:  endpoints.Authorize,
+				TokenURL: endpoints.Token,
 			},
 			RedirectURL: c.RedirectURL,
 		},
-		ctx: context.Background(),
+		ctx:       context.Background(),
+		transport: c.Transport,
+		retry:     c.RetryPolicy,
+		endpoints: endpoints,
 	}
 }
 
+// Endpoints returns the URLs of the Xero APIs configured in the Provider
+func (c *Provider) Endpoints() helpers.Endpoints {
+	return c.endpoints
+}
+
 // XeroTransport represents the information needed for custom Xero transport
 type XeroTransport struct {
 	T        http.RoundTripper
@@ -57,8 +81,9 @@ type XeroTransport struct {
 // RoundTrip method will add on each request the custom header for inform the
 // tenantID
 func (xt *XeroTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req.Header.Add(tenantIDHeader, xt.TenantID.String())
-	return xt.T.RoundTrip(req)
+	r := req.Clone(req.Context())
+	r.Header.Set(tenantIDHeader, xt.TenantID.String())
+	return xt.T.RoundTrip(r)
 }
 
 // NewXeroTransport will build a new XeroTransport based on the given Tenant
@@ -87,20 +112,74 @@ func (c *Provider) GetAuthURL(state string) string {
 // GetTokenFromCode method will find the token with the given code, this method
 // should be called after a success callback received from auth process
 func (c *Provider) GetTokenFromCode(code string) (*oauth2.Token, error) {
-	return c.conf.Exchange(c.ctx, code)
+	return c.GetTokenFromCodeContext(c.ctx, code)
+}
+
+// GetTokenFromCodeContext is the same as GetTokenFromCode but the exchange
+// request is bound to the given context
+func (c *Provider) GetTokenFromCodeContext(ctx context.Context, code string) (*oauth2.Token, error) {
+	return c.conf.Exchange(c.tokenContext(ctx, true), code)
 }
 
 // Refresh method will refresh the given token
 func (c *Provider) Refresh(t *oauth2.Token) (*oauth2.Token, error) {
-	return c.conf.TokenSource(c.ctx, t).Token()
+	return c.RefreshContext(c.ctx, t)
+}
+
+// RefreshContext is the same as Refresh but the refresh request is bound to
+// the given context
+func (c *Provider) RefreshContext(ctx context.Context, t *oauth2.Token) (*oauth2.Token, error) {
+	return c.conf.TokenSource(c.tokenContext(ctx, false), t).Token()
+}
+
+// tokenContext will add to the context the http.Client used by oauth2 for
+// reaching the token endpoint with the Transport and the RetryPolicy of the
+// Provider. Only the code exchanges are retried: a code can only be used
+// once, so a replayed exchange can't issue a second token and at worst fails
+// with invalid_grant. The refreshes are not, when the response is lost Xero
+// has already rotated the refresh token and a replay would fail with
+// invalid_grant hiding the original error
+func (c *Provider) tokenContext(ctx context.Context, retry bool) context.Context {
+	if ctx.Value(oauth2.HTTPClient) != nil || (c.transport == nil && c.retry == nil) {
+		return ctx
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
+		Transport: c.base(retry),
+	})
 }
 
-// Client will build a custom http.Client for Xero
+// base returns the Transport of the Provider wrapped by its RetryPolicy, the
+// POST requests are only retried when retryPost is true
+func (c *Provider) base(retryPost bool) http.RoundTripper {
+	t := c.transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	if c.retry == nil {
+		return t
+	}
+	p := *c.retry
+	if retryPost {
+		p.Idempotent = func(*http.Request) bool { return true }
+	}
+	return p.Transport(t)
+}
+
+// Client will build a custom http.Client for Xero, the token will be refreshed
+// when needed using the context of the request being sent
 func (c *Provider) Client(s *Session) *http.Client {
+	xt := NewXeroTransport(s.TenantID)
+	if c.transport != nil {
+		xt.T = c.transport
+	}
+	var base http.RoundTripper = xt
+	if c.retry != nil {
+		base = c.retry.Transport(xt)
+	}
 	return &http.Client{
-		Transport: &oauth2.Transport{
-			Base:   NewXeroTransport(s.TenantID),
-			Source: oauth2.ReuseTokenSource(nil, NewTokenRefresher(s.Repo, s.Token, c, s.UserID)),
+		Transport: &Transport{
+			Base:   base,
+			Source: NewTokenRefresher(s.Repo, s.Token, c, s.UserID),
 		},
 	}
 }
@@ -108,5 +187,17 @@ func (c *Provider) Client(s *Session) *http.Client {
 // NewClient method will return a new http.Client for use in our calls, using
 // the TokenSource will even refresh the token if needed
 func (c *Provider) NewClient(t *oauth2.Token) *http.Client {
-	return c.conf.Client(c.ctx, t)
+	return c.NewClientContext(c.ctx, t)
+}
+
+// NewClientContext is the same as NewClient but the given context will be used
+// when the token needs to be refreshed. The calls are sent with the Transport
+// and the RetryPolicy of the Provider
+func (c *Provider) NewClientContext(ctx context.Context, t *oauth2.Token) *http.Client {
+	return &http.Client{
+		Transport: &Transport{
+			Base:   c.base(false),
+			Source: c.conf.TokenSource(c.tokenContext(ctx, false), t),
+		},
+	}
 }
@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"context"
+	"sync"
+
 	"github.com/gofrs/uuid"
 	"golang.org/x/oauth2"
 )
@@ -15,6 +18,7 @@ type Repository interface {
 
 // TokenRefresher keep the information needed for our custom TokenSource
 type TokenRefresher struct {
+	mu       sync.Mutex
 	repo     Repository
 	token    *oauth2.Token
 	provider *Provider
@@ -35,14 +39,23 @@ func NewTokenRefresher(repo Repository, token *oauth2.Token, provider *Provider,
 // Token method is the custom implementation of the refresh token process using
 // a session repo as a base
 func (t *TokenRefresher) Token() (*oauth2.Token, error) {
+	return t.TokenContext(t.provider.ctx)
+}
+
+// TokenContext is the same as Token but the refresh request, if needed, is
+// bound to the given context
+func (t *TokenRefresher) TokenContext(ctx context.Context) (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if !t.token.Valid() {
-		token, err := t.provider.Refresh(t.token)
+		token, err := t.provider.RefreshContext(ctx, t.token)
 		if err != nil {
 			return nil, err
 		}
 		if err = t.repo.UpdateSession(t.userID, token); err != nil {
 			return nil, err
 		}
+		t.token = token
 		return token, nil
 	}
 	return t.token, nil
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// ContextTokenSource is a TokenSource that is able to use a context when it
+// needs to reach the token endpoint
+type ContextTokenSource interface {
+	TokenContext(ctx context.Context) (*oauth2.Token, error)
+}
+
+// Transport will authorize each request with the token given by Source. When
+// Source is a ContextTokenSource the token is asked using the context of the
+// request, so the cancellation, deadlines and values of the request are also
+// used for the token refresh
+type Transport struct {
+	Base   http.RoundTripper
+	Source oauth2.TokenSource
+}
+
+// RoundTrip method will add the Authorization header to a copy of the request
+// before sending it with the Base transport
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Source == nil {
+		closeBody(req)
+		return nil, errors.New("auth: Transport's Source is nil")
+	}
+	var (
+		token *oauth2.Token
+		err   error
+	)
+	if cs, ok := t.Source.(ContextTokenSource); ok {
+		token, err = cs.TokenContext(req.Context())
+	} else {
+		token, err = t.Source.Token()
+	}
+	if err != nil {
+		closeBody(req)
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	token.SetAuthHeader(r)
+	return t.base().RoundTrip(r)
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
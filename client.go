@@ -0,0 +1,255 @@
+package xerosdk
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/accounting"
+	"github.com/quickaco/xerosdk/auth"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+const (
+	tenantIDHeader  = "xero-tenant-id"
+	userAgentHeader = "User-Agent"
+)
+
+// Logger is the interface used for logging the calls, *log.Logger
+// satisfies it
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithUserAgent sets the User-Agent header sent on each call
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithRetryPolicy retries the failed calls following the given policy
+func WithRetryPolicy(policy helpers.RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = &policy
+	}
+}
+
+// WithLogger logs each call and retry with the given logger
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithRateLimiter tracks the rate limits of the calls with the given
+// RateLimitTransport, share the same one between all your clients
+func WithRateLimiter(limiter *helpers.RateLimitTransport) Option {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
+// WithEndpoints makes the calls use the given base URLs, e.g. for pointing the
+// SDK to an httptest.Server
+func WithEndpoints(endpoints helpers.Endpoints) Option {
+	return func(c *Client) {
+		c.endpoints = &endpoints
+	}
+}
+
+// WithValidation validates the payloads of the creates and updates before
+// sending them, the invalid ones fail with accounting.ValidationErrors
+func WithValidation() Option {
+	return func(c *Client) {
+		c.validation = true
+	}
+}
+
+// WithTenant sends the given tenant on each call, it is only needed when the
+// given http.Client doesn't already set it (e.g. it doesn't use an
+// auth.XeroTransport) or for tracking its rate limits with WithRateLimiter
+func WithTenant(tenantID uuid.UUID) Option {
+	return func(c *Client) {
+		c.tenantID = tenantID
+	}
+}
+
+// Client is the single entry point for calling the Xero API. It keeps the
+// configuration shared by all the calls and exposes a service per resource
+type Client struct {
+	http       *http.Client
+	userAgent  string
+	retry      *helpers.RetryPolicy
+	logger     Logger
+	limiter    *helpers.RateLimitTransport
+	tenantID   uuid.UUID
+	endpoints  *helpers.Endpoints
+	validation bool
+
+	Accounts           *AccountsService
+	Attachments        *AttachmentsService
+	BankTransactions   *BankTransactionsService
+	BankTransfers      *BankTransfersService
+	BatchPayments      *BatchPaymentsService
+	BrandingThemes     *BrandingThemesService
+	ContactGroups      *ContactGroupsService
+	Contacts           *ContactsService
+	CreditNotes        *CreditNotesService
+	Currencies         *CurrenciesService
+	Employees          *EmployeesService
+	History            *HistoryService
+	InvoiceReminders   *InvoiceRemindersService
+	Invoices           *InvoicesService
+	Items              *ItemsService
+	Journals           *JournalsService
+	ManualJournals     *ManualJournalsService
+	Organisations      *OrganisationsService
+	Overpayments       *OverpaymentsService
+	Payments           *PaymentsService
+	Prepayments        *PrepaymentsService
+	Reports            *ReportsService
+	TrackingCategories *TrackingCategoriesService
+	Connections        *ConnectionsService
+}
+
+// NewClient will build a Client on top of the given http.Client, which is in
+// charge of the authorization e.g. the one returned by auth.Provider.Client
+func NewClient(cl *http.Client, opts ...Option) *Client {
+	if cl == nil {
+		cl = http.DefaultClient
+	}
+	c := &Client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// From the inner to the outer transport: the rate limiter must see the
+	// tenant header and the retries go through the rate limiter
+	t := cl.Transport
+	if t == nil {
+		t = http.DefaultTransport
+	}
+	if c.limiter != nil {
+		t = c.limiter.Wrap(t)
+	}
+	if c.userAgent != "" || c.tenantID != uuid.Nil {
+		t = &headerTransport{t: t, userAgent: c.userAgent, tenantID: c.tenantID}
+	}
+	if c.retry != nil {
+		policy := *c.retry
+		if policy.OnRetry == nil && c.logger != nil {
+			policy.OnRetry = func(req *http.Request, attempt int, wait time.Duration, response *http.Response, err error) {
+				c.logger.Printf("xero: retrying %s %s in %s after attempt %d: %s", req.Method, req.URL.Path, wait, attempt, describe(response, err))
+			}
+		}
+		t = policy.Transport(t)
+	}
+	if c.logger != nil {
+		t = &logTransport{t: t, logger: c.logger}
+	}
+	c.http = &http.Client{
+		Transport:     t,
+		CheckRedirect: cl.CheckRedirect,
+		Jar:           cl.Jar,
+		Timeout:       cl.Timeout,
+	}
+
+	c.Accounts = &AccountsService{client: c}
+	c.Attachments = &AttachmentsService{client: c}
+	c.BankTransactions = &BankTransactionsService{client: c}
+	c.BankTransfers = &BankTransfersService{client: c}
+	c.BatchPayments = &BatchPaymentsService{client: c}
+	c.BrandingThemes = &BrandingThemesService{client: c}
+	c.ContactGroups = &ContactGroupsService{client: c}
+	c.Contacts = &ContactsService{client: c}
+	c.CreditNotes = &CreditNotesService{client: c}
+	c.Currencies = &CurrenciesService{client: c}
+	c.Employees = &EmployeesService{client: c}
+	c.History = &HistoryService{client: c}
+	c.InvoiceReminders = &InvoiceRemindersService{client: c}
+	c.Invoices = &InvoicesService{client: c}
+	c.Items = &ItemsService{client: c}
+	c.Journals = &JournalsService{client: c}
+	c.ManualJournals = &ManualJournalsService{client: c}
+	c.Organisations = &OrganisationsService{client: c}
+	c.Overpayments = &OverpaymentsService{client: c}
+	c.Payments = &PaymentsService{client: c}
+	c.Prepayments = &PrepaymentsService{client: c}
+	c.Reports = &ReportsService{client: c}
+	c.TrackingCategories = &TrackingCategoriesService{client: c}
+	c.Connections = &ConnectionsService{client: c}
+	return c
+}
+
+// NewClientFromSession will build a Client for the tenant of the given
+// session, using the Provider for the authorization
+func NewClientFromSession(p *auth.Provider, s *auth.Session, opts ...Option) *Client {
+	return NewClient(p.Client(s), append([]Option{WithTenant(s.TenantID), WithEndpoints(p.Endpoints())}, opts...)...)
+}
+
+// HTTPClient returns the configured http.Client used by the Client, it can be
+// used for calling directly the functions of the accounting and connection
+// packages together with Context
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// Context returns a context carrying the configuration of the Client (e.g.
+// the endpoints or the validation) that must be used when calling directly the functions of the
+// accounting and connection packages
+func (c *Client) Context(ctx context.Context) context.Context {
+	return c.context(ctx)
+}
+
+func (c *Client) context(ctx context.Context) context.Context {
+	if c.endpoints != nil {
+		ctx = helpers.WithEndpoints(ctx, *c.endpoints)
+	}
+	if c.validation {
+		ctx = accounting.WithValidation(ctx)
+	}
+	return ctx
+}
+
+// headerTransport adds the headers configured in the Client
+type headerTransport struct {
+	t         http.RoundTripper
+	userAgent string
+	tenantID  uuid.UUID
+}
+
+func (ht *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if ht.userAgent != "" {
+		r.Header.Set(userAgentHeader, ht.userAgent)
+	}
+	if ht.tenantID != uuid.Nil && r.Header.Get(tenantIDHeader) == "" {
+		r.Header.Set(tenantIDHeader, ht.tenantID.String())
+	}
+	return ht.t.RoundTrip(r)
+}
+
+// logTransport logs each call with its result and duration
+type logTransport struct {
+	t      http.RoundTripper
+	logger Logger
+}
+
+func (lt *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := lt.t.RoundTrip(req)
+	lt.logger.Printf("xero: %s %s %s in %s", req.Method, req.URL.Path, describe(response, err), time.Since(start))
+	return response, err
+}
+
+func describe(response *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return response.Status
+}
@@ -1,6 +1,7 @@
 package connection
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
@@ -8,10 +9,6 @@ import (
 	"github.com/quickaco/xerosdk/helpers"
 )
 
-const (
-	connectionsURL = "https://api.xero.com/connections"
-)
-
 // Tenant type will keep information about the Xero tenant
 type Tenant struct {
 	ID         uuid.UUID `json:"id,omitempty"`
@@ -21,7 +18,12 @@ type Tenant struct {
 
 // GetTenants will return the value of the getting information from xero
 func GetTenants(cl *http.Client) (tenants []Tenant, err error) {
-	tenantResponseBytes, err := helpers.Find(cl, connectionsURL, nil, nil)
+	return GetTenantsContext(context.Background(), cl)
+}
+
+// GetTenantsContext is the same as GetTenants but the request is bound to the given context
+func GetTenantsContext(ctx context.Context, cl *http.Client) (tenants []Tenant, err error) {
+	tenantResponseBytes, err := helpers.FindContext(ctx, cl, helpers.ConnectionsURL(ctx), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -33,7 +35,12 @@ func GetTenants(cl *http.Client) (tenants []Tenant, err error) {
 
 // DeleteTenant will remove the connection with the given connectionID
 func DeleteTenant(cl *http.Client, connectionID uuid.UUID) error {
-	_, err := helpers.Remove(cl, connectionsURL+"/"+connectionID.String())
+	return DeleteTenantContext(context.Background(), cl, connectionID)
+}
+
+// DeleteTenantContext is the same as DeleteTenant but the request is bound to the given context
+func DeleteTenantContext(ctx context.Context, cl *http.Client, connectionID uuid.UUID) error {
+	_, err := helpers.RemoveContext(ctx, cl, helpers.ConnectionsURL(ctx, connectionID.String()))
 	if err != nil {
 		return err
 	}
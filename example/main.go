@@ -126,7 +126,7 @@ func StartXeroAuthHandler(w http.ResponseWriter, r *http.Request) {
 // XeroAuthCallbackHandler is the handler in where we are going to receive a
 // successful callback with a code that can we use to get our user token
 func XeroAuthCallbackHandler(w http.ResponseWriter, r *http.Request) {
-	token, err := c.GetTokenFromCode(r.FormValue("code"))
+	token, err := c.GetTokenFromCodeContext(r.Context(), r.FormValue("code"))
 	if err != nil {
 		log.Panic(err)
 	}
@@ -175,12 +175,12 @@ func XeroContactsHandler(w http.ResponseWriter, r *http.Request) {
 	})
 	contacts := []accounting.Contact{}
 
-	tenants, err := connection.GetTenants(cl)
+	tenants, err := connection.GetTenantsContext(r.Context(), cl)
 	if err != nil {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		c, err := accounting.FindContacts(c.Client(&auth.Session{
+		c, err := accounting.FindContactsContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
@@ -251,12 +251,12 @@ func XeroInvoicesHandler(w http.ResponseWriter, r *http.Request) {
 		log.Panic(err)
 	}
 	for _, tenant := range tenants {
-		i, err := accounting.FindInvoices(c.Client(&auth.Session{
+		i, err := accounting.FindAllInvoicesContext(r.Context(), c.Client(&auth.Session{
 			Token:    se,
 			UserID:   uuid.Nil,
 			TenantID: tenant.TenantID,
 			Repo:     repo,
-		}))
+		}), time.Time{}, nil)
 		if err != nil {
 			log.Panic(err)
 		}
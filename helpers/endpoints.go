@@ -0,0 +1,110 @@
+package helpers
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// The default base URLs of the Xero APIs
+const (
+	DefaultAccountingURL  = "https://api.xero.com/api.xro/2.0"
+	DefaultConnectionsURL = "https://api.xero.com/connections"
+	DefaultAuthorizeURL   = "https://login.xero.com/identity/connect/authorize"
+	DefaultTokenURL       = "https://identity.xero.com/connect/token"
+)
+
+// The environment variables that override the default base URLs
+const (
+	AccountingURLEnv  = "XERO_ACCOUNTING_URL"
+	ConnectionsURLEnv = "XERO_CONNECTIONS_URL"
+	AuthorizeURLEnv   = "XERO_AUTHORIZE_URL"
+	TokenURLEnv       = "XERO_TOKEN_URL"
+)
+
+// Endpoints keeps the base URLs of the Xero APIs, so the SDK can be pointed to
+// a local stand-in e.g. an httptest.Server. The empty fields use the default
+// URL
+type Endpoints struct {
+	// Base URL of the Accounting API
+	Accounting string
+
+	// URL of the connections endpoint
+	Connections string
+
+	// URL of the OAuth2 authorize endpoint
+	Authorize string
+
+	// URL of the OAuth2 token endpoint
+	Token string
+}
+
+type endpointsKey struct{}
+
+// DefaultEndpoints returns the production URLs overridden by the environment
+// variables, when they are set
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		Accounting:  fromEnv(AccountingURLEnv, DefaultAccountingURL),
+		Connections: fromEnv(ConnectionsURLEnv, DefaultConnectionsURL),
+		Authorize:   fromEnv(AuthorizeURLEnv, DefaultAuthorizeURL),
+		Token:       fromEnv(TokenURLEnv, DefaultTokenURL),
+	}
+}
+
+// WithDefaults returns the endpoints with the empty fields set to their
+// default URL
+func (e Endpoints) WithDefaults() Endpoints {
+	d := DefaultEndpoints()
+	if e.Accounting == "" {
+		e.Accounting = d.Accounting
+	}
+	if e.Connections == "" {
+		e.Connections = d.Connections
+	}
+	if e.Authorize == "" {
+		e.Authorize = d.Authorize
+	}
+	if e.Token == "" {
+		e.Token = d.Token
+	}
+	return e
+}
+
+// WithEndpoints returns a context that makes the calls done with it use the
+// given endpoints
+func WithEndpoints(ctx context.Context, e Endpoints) context.Context {
+	return context.WithValue(ctx, endpointsKey{}, e.WithDefaults())
+}
+
+// EndpointsFromContext returns the endpoints set in the context with
+// WithEndpoints, or the default ones
+func EndpointsFromContext(ctx context.Context) Endpoints {
+	if e, ok := ctx.Value(endpointsKey{}).(Endpoints); ok {
+		return e
+	}
+	return DefaultEndpoints()
+}
+
+// AccountingURL builds the URL of the Accounting API for the given path parts
+// using the endpoints of the context
+func AccountingURL(ctx context.Context, parts ...string) string {
+	return joinURL(EndpointsFromContext(ctx).Accounting, parts)
+}
+
+// ConnectionsURL builds the URL of the connections endpoint for the given path
+// parts using the endpoints of the context
+func ConnectionsURL(ctx context.Context, parts ...string) string {
+	return joinURL(EndpointsFromContext(ctx).Connections, parts)
+}
+
+func joinURL(base string, parts []string) string {
+	return strings.Join(append([]string{strings.TrimSuffix(base, "/")}, parts...), "/")
+}
+
+func fromEnv(key string, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -1,6 +1,12 @@
 package helpers
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 // Error is a type that tries to decode the Xero API error, couldn't find anything
 // on the documentation that give me a clear vision about how Xero is managing
@@ -26,3 +32,171 @@ func DecodeError(buf []byte) Error {
 	}
 	return e
 }
+
+// The error kinds an APIError can match using errors.Is, e.g.
+// errors.Is(err, helpers.ErrNotFound)
+var (
+	ErrNotFound     = errors.New("xero: not found")
+	ErrUnauthorized = errors.New("xero: unauthorized")
+	ErrRateLimited  = errors.New("xero: rate limited")
+	ErrValidation   = errors.New("xero: validation error")
+	ErrServer       = errors.New("xero: server error")
+)
+
+const correlationIDHeader = "Xero-Correlation-Id"
+
+// ValidationError is a validation message returned by Xero for an element of
+// the request
+type ValidationError struct {
+	Message string `json:"Message,omitempty"`
+}
+
+// Warning is a non blocking message returned by Xero for an element of the
+// request
+type Warning struct {
+	Message string `json:"Message,omitempty"`
+}
+
+// ErrorElement is one of the elements sent in the request that Xero returns
+// back with its validation errors and warnings. Raw keeps the whole element so
+// it can be decoded into the right model if needed
+type ErrorElement struct {
+	ValidationErrors []ValidationError `json:"ValidationErrors,omitempty"`
+	Warnings         []Warning         `json:"Warnings,omitempty"`
+	Raw              json.RawMessage   `json:"-"`
+}
+
+// UnmarshalJSON keeps a copy of the raw element besides decoding the messages
+func (e *ErrorElement) UnmarshalJSON(buf []byte) error {
+	type element ErrorElement
+	var el element
+	if err := json.Unmarshal(buf, &el); err != nil {
+		return err
+	}
+	*e = ErrorElement(el)
+	e.Raw = append(json.RawMessage(nil), buf...)
+	return nil
+}
+
+// APIError is the error returned by all the calls when Xero answers with a
+// status code >= 400. It mixes the fields of the Accounting API errors
+// (ErrorNumber, Type, Message and Elements) and the problem details used by
+// the identity and connections endpoints (Title, Detail and Instance)
+type APIError struct {
+	// HTTP status code of the response
+	StatusCode int `json:"-"`
+
+	// Xero error number e.g. 10 for a ValidationException
+	ErrorNumber int `json:"ErrorNumber,omitempty"`
+
+	// Xero exception type e.g. ValidationException
+	Type string `json:"Type,omitempty"`
+
+	// Description of the error
+	Message string `json:"Message,omitempty"`
+
+	// Elements of the request with their validation errors and warnings
+	Elements []ErrorElement `json:"Elements,omitempty"`
+
+	Title    string `json:"Title,omitempty"`
+	Detail   string `json:"Detail,omitempty"`
+	Instance string `json:"Instance,omitempty"`
+
+	// Rate limit information sent with the response
+	RateLimit RateLimit `json:"-"`
+
+	// Identifier of the request on Xero side, useful when contacting support
+	CorrelationID string `json:"-"`
+
+	// Raw body of the response
+	Body []byte `json:"-"`
+}
+
+// NewAPIError will build an APIError from the given response and its already
+// read body
+func NewAPIError(response *http.Response, body []byte) *APIError {
+	e := &APIError{}
+	// Some errors (e.g. 404) are not json, so we don't care about the error
+	_ = json.Unmarshal(body, e)
+	e.StatusCode = response.StatusCode
+	e.RateLimit = ParseRateLimit(response.Header)
+	e.CorrelationID = response.Header.Get(correlationIDHeader)
+	e.Body = body
+	return e
+}
+
+// Error returns a readable description including the validation messages
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "xero: %d", e.StatusCode)
+	switch {
+	case e.Type != "" || e.Message != "":
+		if e.Type != "" {
+			b.WriteString(" " + e.Type)
+		}
+		if e.Message != "" {
+			b.WriteString(": " + e.Message)
+		}
+	case e.Title != "" || e.Detail != "":
+		if e.Title != "" {
+			b.WriteString(" " + e.Title)
+		}
+		if e.Detail != "" {
+			b.WriteString(": " + e.Detail)
+		}
+	case len(e.Body) > 0:
+		b.WriteString(" " + strings.TrimSpace(string(e.Body)))
+	default:
+		b.WriteString(" " + http.StatusText(e.StatusCode))
+	}
+	if messages := e.ValidationErrors(); len(messages) > 0 {
+		parts := make([]string, 0, len(messages))
+		for _, m := range messages {
+			parts = append(parts, m.Message)
+		}
+		b.WriteString(" (" + strings.Join(parts, "; ") + ")")
+	}
+	return b.String()
+}
+
+// Kind returns the sentinel error matching this error or nil if there isn't
+// a specific kind for the status code
+func (e *APIError) Kind() error {
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrValidation
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	}
+	return nil
+}
+
+// Is allows errors.Is to match the APIError against the error kinds
+func (e *APIError) Is(target error) bool {
+	kind := e.Kind()
+	return kind != nil && kind == target
+}
+
+// ValidationErrors returns the validation errors of all the elements
+func (e *APIError) ValidationErrors() []ValidationError {
+	var out []ValidationError
+	for _, el := range e.Elements {
+		out = append(out, el.ValidationErrors...)
+	}
+	return out
+}
+
+// Warnings returns the warnings of all the elements
+func (e *APIError) Warnings() []Warning {
+	var out []Warning
+	for _, el := range e.Elements {
+		out = append(out, el.Warnings...)
+	}
+	return out
+}
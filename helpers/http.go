@@ -2,14 +2,34 @@ package helpers
 
 import (
 	"bytes"
-	"errors"
+	"context"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 )
 
+const modifiedSinceHeader = "If-Modified-Since"
+
+// ModifiedSinceHeaders returns the If-Modified-Since header for the given
+// time, or no headers at all when the time is zero
+func ModifiedSinceHeaders(modifiedSince time.Time) map[string]string {
+	additionalHeaders := map[string]string{}
+	if !modifiedSince.IsZero() {
+		additionalHeaders[modifiedSinceHeader] = modifiedSince.Format(time.RFC3339)
+	}
+	return additionalHeaders
+}
+
 // Find function encapsulate all the GET method calls to Xero API
 func Find(cl *http.Client, endpoint string, additionalHeaders map[string]string, queryParameters map[string]string) ([]byte, error) {
-	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	return FindContext(context.Background(), cl, endpoint, additionalHeaders, queryParameters)
+}
+
+// FindContext works like Find but the request is bound to the given context,
+// so it will be cancelled when the context is done
+func FindContext(ctx context.Context, cl *http.Client, endpoint string, additionalHeaders map[string]string, queryParameters map[string]string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -27,52 +47,137 @@ func Find(cl *http.Client, endpoint string, additionalHeaders map[string]string,
 
 // Create function encapsulate all the POST method calls to Xero API
 func Create(cl *http.Client, endpoint string, body []byte) ([]byte, error) {
+	return CreateContext(context.Background(), cl, endpoint, body)
+}
+
+// CreateContext works like Create but the request is bound to the given
+// context, so it will be cancelled when the context is done. The request is
+// sent with the Idempotency-Key of the context, see WithIdempotencyKey
+func CreateContext(ctx context.Context, cl *http.Client, endpoint string, body []byte) ([]byte, error) {
 	// We need to use here th PUT method due the constraints from the Xero API
-	request, err := http.NewRequest(http.MethodPut, endpoint, bytes.NewReader(body))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	request.Header.Add("Content-Type", "application/json")
+	request.Header.Set(idempotencyKeyHeader, idempotencyKeyFor(ctx))
 
 	return process(cl, request)
 }
 
 // Update function encapsulate all the PUT method calls to Xero API
 func Update(cl *http.Client, endpoint string, body []byte) ([]byte, error) {
+	return UpdateContext(context.Background(), cl, endpoint, body)
+}
+
+// UpdateContext works like Update but the request is bound to the given
+// context, so it will be cancelled when the context is done. The request is
+// sent with the Idempotency-Key of the context, see WithIdempotencyKey
+func UpdateContext(ctx context.Context, cl *http.Client, endpoint string, body []byte) ([]byte, error) {
 	// We need to use here the POST method due the constraints from the Xero API
-	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	request.Header.Add("Content-Type", "application/json")
+	request.Header.Set(idempotencyKeyHeader, idempotencyKeyFor(ctx))
 
 	return process(cl, request)
 }
 
 // Remove function encapsulate all the DELETE method calls to Xero API
 func Remove(cl *http.Client, endpoint string) ([]byte, error) {
-	request, err := http.NewRequest(http.MethodDelete, endpoint, nil)
+	return RemoveContext(context.Background(), cl, endpoint)
+}
+
+// RemoveContext works like Remove but the request is bound to the given
+// context, so it will be cancelled when the context is done. The request is
+// sent with the Idempotency-Key of the context, see WithIdempotencyKey
+func RemoveContext(ctx context.Context, cl *http.Client, endpoint string) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
+	request.Header.Set(idempotencyKeyHeader, idempotencyKeyFor(ctx))
+
+	return process(cl, request)
+}
+
+// FindRaw function encapsulate the GET method calls to Xero API returning a
+// file instead of JSON, e.g. an attachment
+func FindRaw(cl *http.Client, endpoint string, accept string) (io.ReadCloser, string, error) {
+	return FindRawContext(context.Background(), cl, endpoint, accept)
+}
+
+// FindRawContext works like FindRaw but the request is bound to the given
+// context, so it will be cancelled when the context is done. It asks for the
+// given MIME type, any type when empty, and returns the body with the MIME
+// type of the response. The caller must close the body
+func FindRawContext(ctx context.Context, cl *http.Client, endpoint string, accept string) (io.ReadCloser, string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if accept == "" {
+		accept = "*/*"
+	}
+	request.Header.Set("Accept", accept)
+
+	response, err := send(cl, request)
+	if err != nil {
+		return nil, "", err
+	}
+	return response.Body, response.Header.Get("Content-Type"), nil
+}
+
+// CreateRaw function encapsulate the PUT method calls to Xero API sending a
+// file instead of JSON, e.g. an attachment
+func CreateRaw(cl *http.Client, endpoint string, contentType string, body io.Reader) ([]byte, error) {
+	return CreateRawContext(context.Background(), cl, endpoint, contentType, body)
+}
+
+// CreateRawContext works like CreateRaw but the request is bound to the given
+// context, so it will be cancelled when the context is done. The body is sent
+// as it is with the given Content-Type and the JSON response is returned. The
+// request is sent with the Idempotency-Key of the context, see
+// WithIdempotencyKey, but it is only retried when the body is a
+// *bytes.Reader, a *bytes.Buffer or a *strings.Reader, which can be read again
+func CreateRawContext(ctx context.Context, cl *http.Client, endpoint string, contentType string, body io.Reader) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("Content-Type", contentType)
+	request.Header.Set(idempotencyKeyHeader, idempotencyKeyFor(ctx))
 
 	return process(cl, request)
 }
 
 func process(cl *http.Client, request *http.Request) ([]byte, error) {
 	request.Header.Add("Accept", "application/json")
-	response, err := cl.Do(request)
+	response, err := send(cl, request)
 	if err != nil {
 		return nil, err
 	}
 	defer response.Body.Close()
 
-	responseBytes, err := ioutil.ReadAll(response.Body)
+	return ioutil.ReadAll(response.Body)
+}
+
+// send does the request turning the error responses into an APIError, the
+// body of the other responses is left to the caller
+func send(cl *http.Client, request *http.Request) (*http.Response, error) {
+	response, err := cl.Do(request)
 	if err != nil {
 		return nil, err
 	}
 	if response.StatusCode >= http.StatusBadRequest {
-		return nil, errors.New(string(responseBytes))
+		defer response.Body.Close()
+		responseBytes, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, NewAPIError(response, responseBytes)
 	}
-	return responseBytes, nil
+	return response, nil
 }
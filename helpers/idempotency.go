@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+)
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context making the create, update and remove
+// calls done with it send the given Idempotency-Key, so Xero doesn't apply
+// them twice when they are sent again. The key identifies a single operation,
+// a call sending several requests derives a key for each of them. Without a
+// key in the context each call generates a random one, that is reused by the
+// retries of its request
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the key set in the context with
+// WithIdempotencyKey, or an empty string
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
+
+// idempotencyKeyFor returns the key of the context or a new random one
+func idempotencyKeyFor(ctx context.Context) string {
+	if key := IdempotencyKeyFromContext(ctx); key != "" {
+		return key
+	}
+	return uuid.Must(uuid.NewV4()).String()
+}
@@ -0,0 +1,77 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	const endpoint = "https://api.xero.com/api.xro/2.0/Invoices"
+	calls := []struct {
+		name string
+		send func(ctx context.Context, cl *http.Client) ([]byte, error)
+	}{
+		{name: "create", send: func(ctx context.Context, cl *http.Client) ([]byte, error) {
+			return CreateContext(ctx, cl, endpoint, []byte(`{"Invoices":[]}`))
+		}},
+		{name: "update", send: func(ctx context.Context, cl *http.Client) ([]byte, error) {
+			return UpdateContext(ctx, cl, endpoint, []byte(`{"Invoices":[]}`))
+		}},
+		{name: "remove", send: func(ctx context.Context, cl *http.Client) ([]byte, error) {
+			return RemoveContext(ctx, cl, endpoint+"/6a539484-9a5f-41e3-a2c1-a6be5d4f8af0")
+		}},
+	}
+	for _, call := range calls {
+		for _, key := range []string{"", "import-7"} {
+			t.Run(call.name+" "+key, func(t *testing.T) {
+				// the first attempt fails, so every call is sent twice
+				fake := &fakeTransport{responses: []fakeResponse{
+					{status: http.StatusServiceUnavailable},
+					{status: http.StatusOK},
+				}}
+				policy := RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+				cl := &http.Client{Transport: policy.Transport(fake)}
+				ctx := context.Background()
+				if key != "" {
+					ctx = WithIdempotencyKey(ctx, key)
+				}
+
+				for n := 0; n < 2; n++ {
+					if _, err := call.send(ctx, cl); err != nil {
+						t.Fatalf("call %d error = %v", n, err)
+					}
+				}
+				if got := fake.sent(); got != 3 {
+					t.Fatalf("sent %d requests, want 3", got)
+				}
+				keys := make([]string, len(fake.requests))
+				for n, req := range fake.requests {
+					keys[n] = req.Header.Get(idempotencyKeyHeader)
+				}
+				if keys[0] != keys[1] {
+					t.Errorf("the retry sent the key %q, want the one of the first attempt %q", keys[1], keys[0])
+				}
+				switch {
+				case key != "" && (keys[0] != key || keys[2] != key):
+					t.Errorf("keys = %q, want %q for every call", keys, key)
+				case key == "" && (uuid.FromStringOrNil(keys[0]) == uuid.Nil || keys[2] == keys[0]):
+					t.Errorf("keys = %q, want a new random one for each call", keys)
+				}
+			})
+		}
+	}
+}
+
+func TestIdempotencyKeyFromContext(t *testing.T) {
+	ctx := context.Background()
+	if got := IdempotencyKeyFromContext(ctx); got != "" {
+		t.Errorf("IdempotencyKeyFromContext() = %q, want none", got)
+	}
+	if got := IdempotencyKeyFromContext(WithIdempotencyKey(ctx, "payment-run-3")); got != "payment-run-3" {
+		t.Errorf("IdempotencyKeyFromContext() = %q, want payment-run-3", got)
+	}
+}
@@ -0,0 +1,382 @@
+package helpers
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	minLimitRemainingHeader    = "X-MinLimit-Remaining"
+	dayLimitRemainingHeader    = "X-DayLimit-Remaining"
+	appMinLimitRemainingHeader = "X-AppMinLimit-Remaining"
+	rateLimitProblemHeader     = "X-Rate-Limit-Problem"
+	retryAfterHeader           = "Retry-After"
+)
+
+// RateLimit keeps the rate limit information sent by Xero on each response.
+// The remaining values are -1 when the header was not present
+type RateLimit struct {
+	// Calls remaining in the current minute for the tenant
+	MinuteRemaining int
+
+	// Calls remaining in the current day for the tenant
+	DayRemaining int
+
+	// Calls remaining in the current minute for the whole app
+	AppMinuteRemaining int
+
+	// Which limit has been hit on a 429 response: minute, day or appminute
+	Problem string
+
+	// Time to wait before retrying, only sent on 429 responses
+	RetryAfter time.Duration
+}
+
+// ParseRateLimit will read the rate limit headers from the given header
+func ParseRateLimit(h http.Header) RateLimit {
+	rl := RateLimit{
+		MinuteRemaining:    headerInt(h, minLimitRemainingHeader),
+		DayRemaining:       headerInt(h, dayLimitRemainingHeader),
+		AppMinuteRemaining: headerInt(h, appMinLimitRemainingHeader),
+		Problem:            h.Get(rateLimitProblemHeader),
+	}
+	if seconds := headerInt(h, retryAfterHeader); seconds > 0 {
+		rl.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return rl
+}
+
+func headerInt(h http.Header, key string) int {
+	v, err := strconv.Atoi(h.Get(key))
+	if err != nil {
+		return -1
+	}
+	return v
+}
+
+const (
+	tenantIDHeader = "xero-tenant-id"
+
+	// Xero allows 5 concurrent calls per tenant
+	defaultMaxConcurrent = 5
+	defaultMaxRetries    = 3
+	// Retry-After values bigger than this one (e.g. when the day limit is hit)
+	// will not be honoured and the 429 will be returned to the caller, like
+	// for the later calls until the Retry-After is over
+	defaultMaxRetryAfter = time.Minute
+	// Used when a 429 comes without Retry-After header
+	defaultRetryAfter = time.Second
+)
+
+// Quota is the last rate limit information known for a tenant
+type Quota struct {
+	RateLimit
+
+	// When the information was received
+	UpdatedAt time.Time
+
+	// Calls for the tenant will wait until this time because of a 429
+	BlockedUntil time.Time
+
+	// Calls for the tenant will fail with a 429 without being sent until this
+	// time, because Xero asked for waiting longer than MaxRetryAfter (e.g. when
+	// the day limit is hit)
+	RejectedUntil time.Time
+}
+
+type tenantLimiter struct {
+	sem   chan struct{}
+	quota Quota
+}
+
+// RateLimitTransport is a http.RoundTripper that keeps track of the Xero rate
+// limits per tenant (using the xero-tenant-id header), limits the number of
+// concurrent calls for each tenant and retries the calls answered with a 429
+// after waiting the time given in the Retry-After header.
+// It can be used as the T of an auth.XeroTransport, and the same transport
+// should be shared by all the clients so the limits are tracked together
+type RateLimitTransport struct {
+	T http.RoundTripper
+
+	// Maximum number of concurrent calls per tenant
+	MaxConcurrent int
+
+	// Maximum number of retries for a call answered with a 429
+	MaxRetries int
+
+	// Maximum Retry-After the transport will wait for before retrying
+	MaxRetryAfter time.Duration
+
+	mu            sync.Mutex
+	tenants       map[string]*tenantLimiter
+	appRemaining  *int
+	blockedUntil  time.Time
+	rejectedUntil time.Time
+}
+
+// NewRateLimitTransport will build a RateLimitTransport with the default
+// limits on top of the given transport
+func NewRateLimitTransport(t http.RoundTripper) *RateLimitTransport {
+	return &RateLimitTransport{
+		T:             t,
+		MaxConcurrent: defaultMaxConcurrent,
+		MaxRetries:    defaultMaxRetries,
+		MaxRetryAfter: defaultMaxRetryAfter,
+	}
+}
+
+// Quota returns the last rate limit information known for the given tenant
+func (rt *RateLimitTransport) Quota(tenantID string) Quota {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if tl, ok := rt.tenants[tenantID]; ok {
+		return tl.quota
+	}
+	return Quota{RateLimit: RateLimit{MinuteRemaining: -1, DayRemaining: -1, AppMinuteRemaining: -1}}
+}
+
+// AppMinuteRemaining returns the last known number of calls remaining in the
+// current minute for the whole app, -1 if unknown
+func (rt *RateLimitTransport) AppMinuteRemaining() int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.appRemaining == nil {
+		return -1
+	}
+	return *rt.appRemaining
+}
+
+// Wrap returns a transport sharing the limits tracked by rt but sending the
+// requests with the given transport, useful for sharing the same limits
+// between clients with different transports
+func (rt *RateLimitTransport) Wrap(t http.RoundTripper) http.RoundTripper {
+	return &sharedRateLimitTransport{
+		limiter: rt,
+		t:       t,
+	}
+}
+
+// RoundTrip method will send the request once there is room for the tenant,
+// retrying it while it gets 429 responses
+func (rt *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.roundTrip(rt.T, req)
+}
+
+func (rt *RateLimitTransport) roundTrip(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx := req.Context()
+	tenantID := req.Header.Get(tenantIDHeader)
+	tl := rt.tenant(tenantID)
+	if response := rt.rejected(req, tl); response != nil {
+		return response, nil
+	}
+
+	select {
+	case tl.sem <- struct{}{}:
+	case <-ctx.Done():
+		closeBody(req)
+		return nil, ctx.Err()
+	}
+	release := func() { <-tl.sem }
+
+	for attempt := 0; ; attempt++ {
+		if err := sleepContext(ctx, time.Until(rt.blockedUntilFor(tl))); err != nil {
+			release()
+			closeBody(req)
+			return nil, err
+		}
+		r := req
+		if attempt > 0 {
+			var err error
+			if r, err = rewindRequest(req); err != nil {
+				release()
+				return nil, err
+			}
+		}
+		response, err := base.RoundTrip(r)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		rl := ParseRateLimit(response.Header)
+		wait := rl.RetryAfter
+		if response.StatusCode == http.StatusTooManyRequests && wait == 0 {
+			wait = defaultRetryAfter
+		}
+		rt.update(tl, rl, response.StatusCode == http.StatusTooManyRequests, wait)
+
+		if response.StatusCode != http.StatusTooManyRequests ||
+			attempt >= rt.MaxRetries || wait > rt.maxRetryAfter() || !canRewind(req) {
+			response.Body = &releaseOnClose{ReadCloser: response.Body, release: release}
+			return response, nil
+		}
+		drainBody(response)
+	}
+}
+
+func (rt *RateLimitTransport) tenant(tenantID string) *tenantLimiter {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.tenants == nil {
+		rt.tenants = map[string]*tenantLimiter{}
+	}
+	tl, ok := rt.tenants[tenantID]
+	if !ok {
+		max := rt.MaxConcurrent
+		if max <= 0 {
+			max = defaultMaxConcurrent
+		}
+		tl = &tenantLimiter{sem: make(chan struct{}, max)}
+		rt.tenants[tenantID] = tl
+	}
+	return tl
+}
+
+func (rt *RateLimitTransport) maxRetryAfter() time.Duration {
+	if rt.MaxRetryAfter <= 0 {
+		return defaultMaxRetryAfter
+	}
+	return rt.MaxRetryAfter
+}
+
+// rejected returns a 429 response without sending the request while Xero
+// asked for waiting longer than MaxRetryAfter, or nil
+func (rt *RateLimitTransport) rejected(req *http.Request, tl *tenantLimiter) *http.Response {
+	rt.mu.Lock()
+	until, problem := tl.quota.RejectedUntil, tl.quota.Problem
+	if rt.rejectedUntil.After(until) {
+		until, problem = rt.rejectedUntil, "appminute"
+	}
+	rt.mu.Unlock()
+	wait := time.Until(until)
+	if wait <= 0 {
+		return nil
+	}
+	closeBody(req)
+	header := http.Header{}
+	header.Set(retryAfterHeader, strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+	if problem != "" {
+		header.Set(rateLimitProblemHeader, problem)
+	}
+	return &http.Response{
+		Status:     "429 Too Many Requests",
+		StatusCode: http.StatusTooManyRequests,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
+
+func (rt *RateLimitTransport) blockedUntilFor(tl *tenantLimiter) time.Time {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.blockedUntil.After(tl.quota.BlockedUntil) {
+		return rt.blockedUntil
+	}
+	return tl.quota.BlockedUntil
+}
+
+func (rt *RateLimitTransport) update(tl *tenantLimiter, rl RateLimit, limited bool, wait time.Duration) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	now := time.Now()
+	tl.quota.RateLimit = rl
+	tl.quota.UpdatedAt = now
+	if rl.AppMinuteRemaining >= 0 {
+		remaining := rl.AppMinuteRemaining
+		rt.appRemaining = &remaining
+	}
+	if !limited {
+		return
+	}
+	// The app limit affects every tenant, the rest only the current one. The
+	// waits longer than MaxRetryAfter are not slept, the calls are rejected
+	until := now.Add(wait)
+	switch {
+	case rl.Problem == "appminute" && wait > rt.maxRetryAfter():
+		rt.rejectedUntil = until
+	case rl.Problem == "appminute":
+		rt.blockedUntil = until
+	case wait > rt.maxRetryAfter():
+		tl.quota.RejectedUntil = until
+	default:
+		tl.quota.BlockedUntil = until
+	}
+}
+
+type sharedRateLimitTransport struct {
+	limiter *RateLimitTransport
+	t       http.RoundTripper
+}
+
+func (st *sharedRateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return st.limiter.roundTrip(st.t, req)
+}
+
+// releaseOnClose will free the tenant slot once the caller is done with the
+// response body
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest returns a copy of the request with a fresh body so it can be
+// sent again
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+func drainBody(response *http.Response) {
+	io.Copy(ioutil.Discard, io.LimitReader(response.Body, 4096))
+	response.Body.Close()
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// sleepContext waits for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,289 @@
+package helpers
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeResponse is a response served by a fakeTransport
+type fakeResponse struct {
+	status int
+	header map[string]string
+	err    error
+}
+
+// fakeTransport serves its responses in order, repeating the last one, and
+// keeps the requests it got
+type fakeTransport struct {
+	mu        sync.Mutex
+	responses []fakeResponse
+	requests  []*http.Request
+	bodies    []string
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body := ""
+	if req.Body != nil {
+		buf, _ := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		body = string(buf)
+	}
+	f.requests = append(f.requests, req)
+	f.bodies = append(f.bodies, body)
+	r := f.responses[len(f.responses)-1]
+	if n := len(f.requests) - 1; n < len(f.responses) {
+		r = f.responses[n]
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	header := http.Header{}
+	for key, value := range r.header {
+		header.Set(key, value)
+	}
+	return &http.Response{
+		StatusCode: r.status,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func (f *fakeTransport) sent() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func newRequest(t *testing.T, method string, body *bytes.Reader) *http.Request {
+	var req *http.Request
+	var err error
+	if body == nil {
+		req, err = http.NewRequest(method, "https://api.xero.com/api.xro/2.0/Invoices", nil)
+	} else {
+		req, err = http.NewRequest(method, "https://api.xero.com/api.xro/2.0/Invoices", body)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(tenantIDHeader, "tenant")
+	return req
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   RateLimit
+	}{
+		{
+			name:   "no headers",
+			header: map[string]string{},
+			want:   RateLimit{MinuteRemaining: -1, DayRemaining: -1, AppMinuteRemaining: -1},
+		},
+		{
+			name: "remaining",
+			header: map[string]string{
+				minLimitRemainingHeader:    "59",
+				dayLimitRemainingHeader:    "4999",
+				appMinLimitRemainingHeader: "9999",
+			},
+			want: RateLimit{MinuteRemaining: 59, DayRemaining: 4999, AppMinuteRemaining: 9999},
+		},
+		{
+			name: "limited",
+			header: map[string]string{
+				minLimitRemainingHeader: "0",
+				rateLimitProblemHeader:  "minute",
+				retryAfterHeader:        "12",
+			},
+			want: RateLimit{MinuteRemaining: 0, DayRemaining: -1, AppMinuteRemaining: -1, Problem: "minute", RetryAfter: 12 * time.Second},
+		},
+		{
+			name:   "invalid Retry-After",
+			header: map[string]string{retryAfterHeader: "soon"},
+			want:   RateLimit{MinuteRemaining: -1, DayRemaining: -1, AppMinuteRemaining: -1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for key, value := range tt.header {
+				h.Set(key, value)
+			}
+			if got := ParseRateLimit(h); got != tt.want {
+				t.Errorf("ParseRateLimit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	limited := func(retryAfter string, problem string) fakeResponse {
+		return fakeResponse{status: http.StatusTooManyRequests, header: map[string]string{
+			retryAfterHeader:       retryAfter,
+			rateLimitProblemHeader: problem,
+		}}
+	}
+	ok := fakeResponse{status: http.StatusOK, header: map[string]string{minLimitRemainingHeader: "42"}}
+
+	tests := []struct {
+		name       string
+		responses  []fakeResponse
+		maxRetries int
+		streaming  bool
+		calls      int
+		wantStatus int
+		wantSent   int
+	}{
+		{
+			name:       "not limited",
+			responses:  []fakeResponse{ok},
+			maxRetries: defaultMaxRetries,
+			calls:      2,
+			wantStatus: http.StatusOK,
+			wantSent:   2,
+		},
+		{
+			name:       "retried after the Retry-After",
+			responses:  []fakeResponse{limited("1", "minute"), ok},
+			maxRetries: defaultMaxRetries,
+			calls:      1,
+			wantStatus: http.StatusOK,
+			wantSent:   2,
+		},
+		{
+			name:       "retries exhausted",
+			responses:  []fakeResponse{limited("1", "minute")},
+			maxRetries: 1,
+			calls:      1,
+			wantStatus: http.StatusTooManyRequests,
+			wantSent:   2,
+		},
+		{
+			name:       "streaming body not retried",
+			responses:  []fakeResponse{limited("1", "minute"), ok},
+			maxRetries: defaultMaxRetries,
+			streaming:  true,
+			calls:      1,
+			wantStatus: http.StatusTooManyRequests,
+			wantSent:   1,
+		},
+		{
+			name:       "Retry-After longer than MaxRetryAfter rejects the next calls",
+			responses:  []fakeResponse{limited("3600", "day"), ok},
+			maxRetries: defaultMaxRetries,
+			calls:      3,
+			wantStatus: http.StatusTooManyRequests,
+			wantSent:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTransport{responses: tt.responses}
+			rt := NewRateLimitTransport(fake)
+			rt.MaxRetries = tt.maxRetries
+			var response *http.Response
+			for i := 0; i < tt.calls; i++ {
+				req := newRequest(t, http.MethodPost, bytes.NewReader([]byte(`{"Invoices":[]}`)))
+				if tt.streaming {
+					req.GetBody = nil
+				}
+				var err error
+				if response, err = rt.RoundTrip(req); err != nil {
+					t.Fatalf("RoundTrip() error = %v", err)
+				}
+				response.Body.Close()
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if got := fake.sent(); got != tt.wantSent {
+				t.Errorf("sent %d requests, want %d", got, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransportRejected(t *testing.T) {
+	fake := &fakeTransport{responses: []fakeResponse{{status: http.StatusTooManyRequests, header: map[string]string{
+		retryAfterHeader:       "3600",
+		rateLimitProblemHeader: "day",
+	}}}}
+	rt := NewRateLimitTransport(fake)
+	for i := 0; i < 2; i++ {
+		response, err := rt.RoundTrip(newRequest(t, http.MethodGet, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		rl := ParseRateLimit(response.Header)
+		if rl.Problem != "day" || rl.RetryAfter < 59*time.Minute {
+			t.Errorf("call %d: RateLimit = %+v, want the day problem with about 1h to wait", i, rl)
+		}
+	}
+	quota := rt.Quota("tenant")
+	if wait := time.Until(quota.RejectedUntil); wait < 59*time.Minute || wait > time.Hour {
+		t.Errorf("RejectedUntil is in %s, want about 1h", wait)
+	}
+	if !quota.BlockedUntil.IsZero() {
+		t.Errorf("BlockedUntil = %s, want zero", quota.BlockedUntil)
+	}
+	if other := rt.Quota("other"); !other.RejectedUntil.IsZero() {
+		t.Errorf("the other tenants are rejected until %s", other.RejectedUntil)
+	}
+}
+
+func TestRateLimitTransportMaxConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	running, peak := 0, 0
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+	})
+	rt := NewRateLimitTransport(base)
+	rt.MaxConcurrent = 2
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := rt.RoundTrip(newRequest(t, http.MethodGet, nil))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			response.Body.Close()
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if peak != 2 {
+		t.Errorf("%d concurrent calls, want 2", peak)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
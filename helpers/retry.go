@@ -0,0 +1,204 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	defaultMaxAttempts = 3
+	defaultMinBackoff  = 500 * time.Millisecond
+	defaultMaxBackoff  = 30 * time.Second
+)
+
+// RetryPolicy describes how the transient failures (connection errors and
+// some status codes) are retried. The zero value of each field means its
+// default value is used. The 429 responses are not retried by default as
+// they are handled by the RateLimitTransport
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one. Defaults to 3
+	MaxAttempts int
+
+	// Backoff before the first retry, doubled on each retry. Defaults to 500ms
+	MinBackoff time.Duration
+
+	// Maximum backoff between two attempts. Defaults to 30s
+	MaxBackoff time.Duration
+
+	// Status codes that will be retried. Defaults to 500, 502, 503 and 504
+	RetryableStatus []int
+
+	// Retryable, if set, replaces the check of RetryableStatus and errors to
+	// decide if an attempt must be retried. Only one of response and err is
+	// not nil
+	Retryable func(req *http.Request, response *http.Response, err error) bool
+
+	// Idempotent decides if a request can be sent again. Defaults to
+	// IsIdempotent, so the creates and updates are only retried when they
+	// carry an Idempotency-Key
+	Idempotent func(req *http.Request) bool
+
+	// OnRetry, if set, is called before waiting for each retry, e.g. for
+	// logging it. attempt is the number of the attempt that failed
+	OnRetry func(req *http.Request, attempt int, wait time.Duration, response *http.Response, err error)
+}
+
+// DefaultRetryPolicy returns the RetryPolicy with all the default values
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     defaultMaxAttempts,
+		MinBackoff:      defaultMinBackoff,
+		MaxBackoff:      defaultMaxBackoff,
+		RetryableStatus: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+// IsIdempotent returns if the request can be safely sent more than once. GET,
+// HEAD, OPTIONS and DELETE are, PUT (create) and POST (update) are only when
+// the request has an Idempotency-Key header
+func IsIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(idempotencyKeyHeader) != ""
+}
+
+// Backoff returns the time to wait before the given retry (starting at 1),
+// using an exponential backoff with jitter
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	min, max := p.MinBackoff, p.MaxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	d := min
+	for i := 1; i < retry && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	// Half of the backoff is fixed and the other half is random
+	half := d / 2
+	return half + jitter.duration(half)
+}
+
+// jitter is the source of the random part of the backoffs, seeded so the
+// clients started at the same time don't retry in lockstep
+var jitter = &lockedRand{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// lockedRand is a rand.Rand safe for concurrent use
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// duration returns a random duration in [0, max]
+func (l *lockedRand) duration(max time.Duration) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return time.Duration(l.r.Int63n(int64(max) + 1))
+}
+
+// Transport returns a RetryTransport applying the policy on top of the given
+// transport
+func (p RetryPolicy) Transport(t http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		T:      t,
+		Policy: p,
+	}
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return defaultMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) idempotent(req *http.Request) bool {
+	if p.Idempotent != nil {
+		return p.Idempotent(req)
+	}
+	return IsIdempotent(req)
+}
+
+func (p RetryPolicy) retryable(req *http.Request, response *http.Response, err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(req, response, err)
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	statuses := p.RetryableStatus
+	if statuses == nil {
+		statuses = DefaultRetryPolicy().RetryableStatus
+	}
+	for _, status := range statuses {
+		if response.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryTransport is a http.RoundTripper that retries the failed requests
+// following its Policy
+type RetryTransport struct {
+	T      http.RoundTripper
+	Policy RetryPolicy
+}
+
+// RoundTrip method will send the request and retry it while it fails with a
+// retryable error, the request is idempotent and there are attempts left
+func (rt *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	p := rt.Policy
+	canRetry := p.idempotent(req) && canRewind(req)
+
+	for attempt := 1; ; attempt++ {
+		r := req
+		if attempt > 1 {
+			var err error
+			if r, err = rewindRequest(req); err != nil {
+				return nil, err
+			}
+		}
+		response, err := rt.base().RoundTrip(r)
+		if !canRetry || attempt >= p.maxAttempts() || !p.retryable(req, response, err) {
+			return response, err
+		}
+
+		wait := p.Backoff(attempt)
+		if response != nil {
+			if after := ParseRateLimit(response.Header).RetryAfter; after > wait {
+				wait = after
+			}
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(req, attempt, wait, response, err)
+		}
+		if response != nil {
+			drainBody(response)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (rt *RetryTransport) base() http.RoundTripper {
+	if rt.T != nil {
+		return rt.T
+	}
+	return http.DefaultTransport
+}
@@ -0,0 +1,242 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsIdempotent(t *testing.T) {
+	tests := []struct {
+		method string
+		key    string
+		want   bool
+	}{
+		{method: http.MethodGet, want: true},
+		{method: http.MethodHead, want: true},
+		{method: http.MethodOptions, want: true},
+		{method: http.MethodDelete, want: true},
+		{method: http.MethodPut, want: false},
+		{method: http.MethodPost, want: false},
+		{method: http.MethodPut, key: "create-1", want: true},
+		{method: http.MethodPost, key: "update-1", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.key, func(t *testing.T) {
+			req := newRequest(t, tt.method, nil)
+			if tt.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.key)
+			}
+			if got := IsIdempotent(req); got != tt.want {
+				t.Errorf("IsIdempotent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		min    time.Duration
+		max    time.Duration
+	}{
+		{name: "first retry", policy: DefaultRetryPolicy(), retry: 1, min: 250 * time.Millisecond, max: 500 * time.Millisecond},
+		{name: "doubled", policy: DefaultRetryPolicy(), retry: 3, min: time.Second, max: 2 * time.Second},
+		{name: "capped", policy: DefaultRetryPolicy(), retry: 20, min: 15 * time.Second, max: 30 * time.Second},
+		{name: "zero policy uses the defaults", policy: RetryPolicy{}, retry: 1, min: 250 * time.Millisecond, max: 500 * time.Millisecond},
+		{
+			name:   "custom",
+			policy: RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond},
+			retry:  3,
+			min:    150 * time.Millisecond,
+			max:    300 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := tt.policy.Backoff(tt.retry); got < tt.min || got > tt.max {
+					t.Fatalf("Backoff(%d) = %s, want between %s and %s", tt.retry, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	unavailable := fakeResponse{status: http.StatusServiceUnavailable}
+	ok := fakeResponse{status: http.StatusOK}
+	const body = `{"Invoices":[{"Reference":"INV-1"}]}`
+
+	tests := []struct {
+		name        string
+		method      string
+		key         string
+		body        bool
+		streaming   bool
+		maxAttempts int
+		responses   []fakeResponse
+		wantStatus  int
+		wantErr     bool
+		wantSent    int
+	}{
+		{
+			name:       "success",
+			method:     http.MethodGet,
+			responses:  []fakeResponse{ok},
+			wantStatus: http.StatusOK,
+			wantSent:   1,
+		},
+		{
+			name:       "retryable status",
+			method:     http.MethodGet,
+			responses:  []fakeResponse{unavailable, unavailable, ok},
+			wantStatus: http.StatusOK,
+			wantSent:   3,
+		},
+		{
+			name:       "attempts exhausted",
+			method:     http.MethodGet,
+			responses:  []fakeResponse{unavailable},
+			wantStatus: http.StatusServiceUnavailable,
+			wantSent:   defaultMaxAttempts,
+		},
+		{
+			name:        "custom attempts",
+			method:      http.MethodGet,
+			maxAttempts: 5,
+			responses:   []fakeResponse{unavailable},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantSent:    5,
+		},
+		{
+			name:       "connection error",
+			method:     http.MethodGet,
+			responses:  []fakeResponse{{err: errors.New("connection reset")}, ok},
+			wantStatus: http.StatusOK,
+			wantSent:   2,
+		},
+		{
+			name:       "status not retryable",
+			method:     http.MethodGet,
+			responses:  []fakeResponse{{status: http.StatusNotFound}, ok},
+			wantStatus: http.StatusNotFound,
+			wantSent:   1,
+		},
+		{
+			name:       "429 left to the rate limit transport",
+			method:     http.MethodGet,
+			responses:  []fakeResponse{{status: http.StatusTooManyRequests}, ok},
+			wantStatus: http.StatusTooManyRequests,
+			wantSent:   1,
+		},
+		{
+			name:       "create without Idempotency-Key",
+			method:     http.MethodPut,
+			body:       true,
+			responses:  []fakeResponse{unavailable, ok},
+			wantStatus: http.StatusServiceUnavailable,
+			wantSent:   1,
+		},
+		{
+			name:       "create with Idempotency-Key",
+			method:     http.MethodPut,
+			key:        "create-1",
+			body:       true,
+			responses:  []fakeResponse{unavailable, ok},
+			wantStatus: http.StatusOK,
+			wantSent:   2,
+		},
+		{
+			name:       "streaming body",
+			method:     http.MethodPut,
+			key:        "create-1",
+			body:       true,
+			streaming:  true,
+			responses:  []fakeResponse{unavailable, ok},
+			wantStatus: http.StatusServiceUnavailable,
+			wantSent:   1,
+		},
+		{
+			name:      "connection error not retried",
+			method:    http.MethodPost,
+			body:      true,
+			responses: []fakeResponse{{err: errors.New("connection reset")}, ok},
+			wantErr:   true,
+			wantSent:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTransport{responses: tt.responses}
+			policy := DefaultRetryPolicy()
+			policy.MinBackoff = time.Millisecond
+			policy.MaxBackoff = 2 * time.Millisecond
+			policy.MaxAttempts = tt.maxAttempts
+			var req *http.Request
+			if tt.body {
+				req = newRequest(t, tt.method, bytes.NewReader([]byte(body)))
+			} else {
+				req = newRequest(t, tt.method, nil)
+			}
+			if tt.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.key)
+			}
+			if tt.streaming {
+				req.GetBody = nil
+			}
+
+			response, err := policy.Transport(fake).RoundTrip(req)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("RoundTrip() error = nil, want an error")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("RoundTrip() error = %v", err)
+				}
+				response.Body.Close()
+				if response.StatusCode != tt.wantStatus {
+					t.Errorf("StatusCode = %d, want %d", response.StatusCode, tt.wantStatus)
+				}
+			}
+			if got := fake.sent(); got != tt.wantSent {
+				t.Fatalf("sent %d requests, want %d", got, tt.wantSent)
+			}
+			if tt.body {
+				for n, got := range fake.bodies {
+					if got != body {
+						t.Errorf("attempt %d sent %q, want %q", n+1, got, body)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportOnRetry(t *testing.T) {
+	fake := &fakeTransport{responses: []fakeResponse{
+		{status: http.StatusServiceUnavailable, header: map[string]string{retryAfterHeader: "1"}},
+		{status: http.StatusOK},
+	}}
+	var waits []time.Duration
+	policy := RetryPolicy{
+		MinBackoff: time.Millisecond,
+		MaxBackoff: time.Millisecond,
+		OnRetry: func(req *http.Request, attempt int, wait time.Duration, response *http.Response, err error) {
+			waits = append(waits, wait)
+		},
+	}
+	response, err := policy.Transport(fake).RoundTrip(newRequest(t, http.MethodGet, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if len(waits) != 1 || waits[0] != time.Second {
+		t.Errorf("OnRetry waits = %v, want the Retry-After of 1s", waits)
+	}
+}
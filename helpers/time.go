@@ -70,3 +70,43 @@ func getTimestampAndOffset(regex *regexp.Regexp, timeString string) (int64, int6
 	}
 	return timestamp, offset, nil
 }
+
+var dotNetJSONTime = regexp.MustCompile(`^/Date\((-?\d+)([+-]\d{4})?\)/$`)
+
+// isoLayouts are the ISO 8601 forms used by the Xero API, the ones without
+// offset are UTC
+var isoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// ParseTime parses a time in the .Net JSON format, e.g. /Date(1494201600000+0000)/,
+// or in any of the ISO 8601 forms returned by the Xero API
+func ParseTime(value string) (time.Time, error) {
+	if m := dotNetJSONTime.FindStringSubmatch(value); m != nil {
+		ms, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t := time.Unix(0, ms*int64(time.Millisecond)).UTC()
+		if m[2] != "" && m[2][1:] != "0000" {
+			hours, _ := strconv.Atoi(m[2][1:3])
+			minutes, _ := strconv.Atoi(m[2][3:])
+			offset := hours*3600 + minutes*60
+			if m[2][0] == '-' {
+				offset = -offset
+			}
+			t = t.In(time.FixedZone("", offset))
+		}
+		return t, nil
+	}
+	var err error
+	for _, layout := range isoLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
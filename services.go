@@ -0,0 +1,779 @@
+package xerosdk
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/accounting"
+	"github.com/quickaco/xerosdk/connection"
+	"github.com/quickaco/xerosdk/helpers"
+)
+
+// AccountsService handles the calls to the Accounts endpoint
+type AccountsService struct {
+	client *Client
+}
+
+// List will get the accounts matching the given queryParameters, a zero
+// modifiedSince will not filter by date
+func (s *AccountsService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Accounts, error) {
+	return accounting.FindAccountsModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the account with the given ID
+func (s *AccountsService) Get(ctx context.Context, accountID uuid.UUID) (*accounting.Account, error) {
+	return accounting.FindAccountContext(s.client.context(ctx), s.client.http, accountID)
+}
+
+// Create will create the given accounts
+func (s *AccountsService) Create(ctx context.Context, accounts *accounting.Accounts) (*accounting.Accounts, error) {
+	return accounts.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will update the given account
+func (s *AccountsService) Update(ctx context.Context, account *accounting.Account) (*accounting.Accounts, error) {
+	return account.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the account, even when they are zero
+func (s *AccountsService) Patch(ctx context.Context, account *accounting.Account, fields ...string) (*accounting.Accounts, error) {
+	return account.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// Remove will delete the account with the given ID
+func (s *AccountsService) Remove(ctx context.Context, accountID uuid.UUID) (*accounting.Accounts, error) {
+	return accounting.RemoveAccountContext(s.client.context(ctx), s.client.http, accountID)
+}
+
+// AttachmentsService handles the calls to the attachments of the documents
+type AttachmentsService struct {
+	client *Client
+}
+
+// List will get the attachments of the document with the given docType and
+// id, e.g. accounting.AttachmentDocTypeInvoices and the InvoiceID
+func (s *AttachmentsService) List(ctx context.Context, docType accounting.AttachmentDocType, id string) (*accounting.Attachments, error) {
+	return accounting.FindAttachmentsContext(s.client.context(ctx), s.client.http, docType, id)
+}
+
+// Get will download the attachment with the given ID, the content must be closed
+func (s *AttachmentsService) Get(ctx context.Context, docType accounting.AttachmentDocType, id string, attachmentID uuid.UUID) (*accounting.AttachmentContent, error) {
+	return accounting.FindAttachmentContentContext(s.client.context(ctx), s.client.http, docType, id, attachmentID)
+}
+
+// GetByFileName will download the attachment with the given file name, the content must be closed
+func (s *AttachmentsService) GetByFileName(ctx context.Context, docType accounting.AttachmentDocType, id string, fileName string) (*accounting.AttachmentContent, error) {
+	return accounting.FindAttachmentContentByFileNameContext(s.client.context(ctx), s.client.http, docType, id, fileName)
+}
+
+// Create will upload the given content as the attachment of the document
+func (s *AttachmentsService) Create(ctx context.Context, docType accounting.AttachmentDocType, id string, attachment *accounting.Attachment, content io.Reader) (*accounting.Attachments, error) {
+	return attachment.CreateContext(s.client.context(ctx), s.client.http, docType, id, content)
+}
+
+// BankTransactionsService handles the calls to the BankTransactions endpoint
+type BankTransactionsService struct {
+	client *Client
+}
+
+// List will get a page of the bank transactions matching the given
+// queryParameters, a zero modifiedSince will not filter by date
+func (s *BankTransactionsService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.BankTransactions, error) {
+	return accounting.FindBankTransactionsModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// All will get the bank transactions of all the pages
+func (s *BankTransactionsService) All(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.BankTransactions, error) {
+	return accounting.FindAllBankTransactionsContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Iterator returns an iterator walking all the pages of bank transactions
+func (s *BankTransactionsService) Iterator(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) *accounting.BankTransactionIterator {
+	return accounting.NewBankTransactionIterator(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the bank transaction with the given ID
+func (s *BankTransactionsService) Get(ctx context.Context, bankTransactionID uuid.UUID) (*accounting.BankTransaction, error) {
+	return accounting.FindBankTransactionContext(s.client.context(ctx), s.client.http, bankTransactionID)
+}
+
+// Create will create the given bank transactions
+func (s *BankTransactionsService) Create(ctx context.Context, bankTransactions *accounting.BankTransactions) (*accounting.BankTransactions, error) {
+	return bankTransactions.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// CreateAll will create the given bank transactions in batches, with a result per element
+func (s *BankTransactionsService) CreateAll(ctx context.Context, bankTransactions *accounting.BankTransactions) ([]accounting.BankTransactionResult, error) {
+	return bankTransactions.CreateAllContext(s.client.context(ctx), s.client.http)
+}
+
+// UpdateAll will create or update the given bank transactions in batches, with a result per element
+func (s *BankTransactionsService) UpdateAll(ctx context.Context, bankTransactions *accounting.BankTransactions) ([]accounting.BankTransactionResult, error) {
+	return bankTransactions.UpdateAllContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will update the given bank transaction
+func (s *BankTransactionsService) Update(ctx context.Context, bankTransaction *accounting.BankTransaction) (*accounting.BankTransactions, error) {
+	return bankTransaction.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the bank transaction, even when they are zero
+func (s *BankTransactionsService) Patch(ctx context.Context, bankTransaction *accounting.BankTransaction, fields ...string) (*accounting.BankTransactions, error) {
+	return bankTransaction.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// BankTransfersService handles the calls to the BankTransfers endpoint
+type BankTransfersService struct {
+	client *Client
+}
+
+// List will get the bank transfers matching the given queryParameters, a zero
+// modifiedSince will not filter by date
+func (s *BankTransfersService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.BankTransfers, error) {
+	return accounting.FindBankTransfersModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the bank transfer with the given ID
+func (s *BankTransfersService) Get(ctx context.Context, bankTransferID uuid.UUID) (*accounting.BankTransfer, error) {
+	return accounting.FindBankTransferContext(s.client.context(ctx), s.client.http, bankTransferID)
+}
+
+// Create will create the given bank transfers
+func (s *BankTransfersService) Create(ctx context.Context, bankTransfers *accounting.BankTransfers) (*accounting.BankTransfers, error) {
+	return bankTransfers.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// BatchPaymentsService handles the calls to the BatchPayments endpoint
+type BatchPaymentsService struct {
+	client *Client
+}
+
+// List will get the batch payments matching the given queryParameters, a zero
+// modifiedSince will not filter by date
+func (s *BatchPaymentsService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.BatchPayments, error) {
+	return accounting.FindBatchPaymentsModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the batch payment with the given ID
+func (s *BatchPaymentsService) Get(ctx context.Context, batchPaymentID uuid.UUID) (*accounting.BatchPayment, error) {
+	return accounting.FindBatchPaymentContext(s.client.context(ctx), s.client.http, batchPaymentID)
+}
+
+// Create will create the given batch payments
+func (s *BatchPaymentsService) Create(ctx context.Context, batchPayments *accounting.BatchPayments) (*accounting.BatchPayments, error) {
+	return batchPayments.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// Remove will delete the batch payment with the given ID, setting its status to DELETED
+func (s *BatchPaymentsService) Remove(ctx context.Context, batchPaymentID uuid.UUID) (*accounting.BatchPayments, error) {
+	return accounting.RemoveBatchPaymentContext(s.client.context(ctx), s.client.http, batchPaymentID)
+}
+
+// BrandingThemesService handles the calls to the BrandingThemes endpoint
+type BrandingThemesService struct {
+	client *Client
+}
+
+// List will get all the branding themes
+func (s *BrandingThemesService) List(ctx context.Context) ([]accounting.BrandingTheme, error) {
+	return accounting.FindBrandingThemesContext(s.client.context(ctx), s.client.http)
+}
+
+// ContactGroupsService handles the calls to the ContactGroups endpoint
+type ContactGroupsService struct {
+	client *Client
+}
+
+// List will get all the contact groups
+func (s *ContactGroupsService) List(ctx context.Context) (*accounting.ContactGroups, error) {
+	return accounting.FindContactGroupsContext(s.client.context(ctx), s.client.http)
+}
+
+// Get will get the contact group with the given ID, including its contacts
+func (s *ContactGroupsService) Get(ctx context.Context, contactGroupID uuid.UUID) (*accounting.ContactGroups, error) {
+	return accounting.FindContactGroupContext(s.client.context(ctx), s.client.http, contactGroupID)
+}
+
+// Create will create the given contact groups
+func (s *ContactGroupsService) Create(ctx context.Context, contactGroups *accounting.ContactGroups) (*accounting.ContactGroups, error) {
+	return contactGroups.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will update the given contact group
+func (s *ContactGroupsService) Update(ctx context.Context, contactGroup *accounting.ContactGroup) (*accounting.ContactGroups, error) {
+	return contactGroup.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the contact group, even when they are zero
+func (s *ContactGroupsService) Patch(ctx context.Context, contactGroup *accounting.ContactGroup, fields ...string) (*accounting.ContactGroups, error) {
+	return contactGroup.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// Remove will delete the contact group with the given ID
+func (s *ContactGroupsService) Remove(ctx context.Context, contactGroupID uuid.UUID) (*accounting.ContactGroups, error) {
+	return accounting.RemoveContactGroupContext(s.client.context(ctx), s.client.http, contactGroupID)
+}
+
+// ContactsService handles the calls to the Contacts endpoint
+type ContactsService struct {
+	client *Client
+}
+
+// List will get a page of the contacts matching the given queryParameters, a
+// zero modifiedSince will not filter by date
+func (s *ContactsService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Contacts, error) {
+	return accounting.FindContactsModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// All will get the contacts of all the pages
+func (s *ContactsService) All(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Contacts, error) {
+	return accounting.FindAllContactsContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Iterator returns an iterator walking all the pages of contacts
+func (s *ContactsService) Iterator(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) *accounting.ContactIterator {
+	return accounting.NewContactIterator(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the contact with the given ID
+func (s *ContactsService) Get(ctx context.Context, contactID uuid.UUID) (*accounting.Contact, error) {
+	return accounting.FindContactContext(s.client.context(ctx), s.client.http, contactID)
+}
+
+// Create will create the given contacts
+func (s *ContactsService) Create(ctx context.Context, contacts *accounting.Contacts) (*accounting.Contacts, error) {
+	return contacts.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// CreateAll will create the given contacts in batches, with a result per element
+func (s *ContactsService) CreateAll(ctx context.Context, contacts *accounting.Contacts) ([]accounting.ContactResult, error) {
+	return contacts.CreateAllContext(s.client.context(ctx), s.client.http)
+}
+
+// UpdateAll will create or update the given contacts in batches, with a result per element
+func (s *ContactsService) UpdateAll(ctx context.Context, contacts *accounting.Contacts) ([]accounting.ContactResult, error) {
+	return contacts.UpdateAllContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will update the given contact
+func (s *ContactsService) Update(ctx context.Context, contact *accounting.Contact) (*accounting.Contacts, error) {
+	return contact.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the contact, even when they are zero
+func (s *ContactsService) Patch(ctx context.Context, contact *accounting.Contact, fields ...string) (*accounting.Contacts, error) {
+	return contact.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// CreditNotesService handles the calls to the CreditNotes endpoint
+type CreditNotesService struct {
+	client *Client
+}
+
+// List will get a page of the credit notes matching the given
+// queryParameters, a zero modifiedSince will not filter by date
+func (s *CreditNotesService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.CreditNotes, error) {
+	return accounting.FindCreditNotesModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// All will get the credit notes of all the pages
+func (s *CreditNotesService) All(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.CreditNotes, error) {
+	return accounting.FindAllCreditNotesContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Iterator returns an iterator walking all the pages of credit notes
+func (s *CreditNotesService) Iterator(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) *accounting.CreditNoteIterator {
+	return accounting.NewCreditNoteIterator(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the credit note with the given ID
+func (s *CreditNotesService) Get(ctx context.Context, creditNoteID uuid.UUID) (*accounting.CreditNote, error) {
+	return accounting.FindCreditNoteContext(s.client.context(ctx), s.client.http, creditNoteID)
+}
+
+// Create will create the given credit notes
+func (s *CreditNotesService) Create(ctx context.Context, creditNotes *accounting.CreditNotes) (*accounting.CreditNotes, error) {
+	return creditNotes.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will update the given credit note
+func (s *CreditNotesService) Update(ctx context.Context, creditNote *accounting.CreditNote) (*accounting.CreditNotes, error) {
+	return creditNote.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the credit note, even when they are zero
+func (s *CreditNotesService) Patch(ctx context.Context, creditNote *accounting.CreditNote, fields ...string) (*accounting.CreditNotes, error) {
+	return creditNote.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// CurrenciesService handles the calls to the Currencies endpoint
+type CurrenciesService struct {
+	client *Client
+}
+
+// List will get all the currencies
+func (s *CurrenciesService) List(ctx context.Context) (*accounting.Currencies, error) {
+	return accounting.FindCurrenciesContext(s.client.context(ctx), s.client.http)
+}
+
+// Create will add the given currencies to the organisation
+func (s *CurrenciesService) Create(ctx context.Context, currencies *accounting.Currencies) (*accounting.Currencies, error) {
+	return currencies.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// EmployeesService handles the calls to the Employees endpoint
+type EmployeesService struct {
+	client *Client
+}
+
+// List will get the employees matching the given queryParameters
+func (s *EmployeesService) List(ctx context.Context, queryParameters map[string]string) (*accounting.Employees, error) {
+	return accounting.FindEmployeesContext(s.client.context(ctx), s.client.http, queryParameters)
+}
+
+// Create will create the given employees
+func (s *EmployeesService) Create(ctx context.Context, employees *accounting.Employees) (*accounting.Employees, error) {
+	return employees.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will update the given employee
+func (s *EmployeesService) Update(ctx context.Context, employee *accounting.Employee) (*accounting.Employees, error) {
+	return employee.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the employee, even when they are zero
+func (s *EmployeesService) Patch(ctx context.Context, employee *accounting.Employee, fields ...string) (*accounting.Employees, error) {
+	return employee.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// HistoryService handles the calls to the history of the documents
+type HistoryService struct {
+	client *Client
+}
+
+// List will get the history and notes of the document with the given type
+// (e.g. Invoices) and ID
+func (s *HistoryService) List(ctx context.Context, docType string, id string) (*accounting.HistoryRecords, error) {
+	return accounting.FindHistoryAndNotesContext(s.client.context(ctx), s.client.http, docType, id)
+}
+
+// Create will add the given notes to the document with the given type and ID
+func (s *HistoryService) Create(ctx context.Context, docType string, id string, records *accounting.HistoryRecords) (*accounting.HistoryRecords, error) {
+	return records.CreateContext(s.client.context(ctx), s.client.http, docType, id)
+}
+
+// InvoiceRemindersService handles the calls to the InvoiceReminders endpoint
+type InvoiceRemindersService struct {
+	client *Client
+}
+
+// List will get the invoice reminders settings
+func (s *InvoiceRemindersService) List(ctx context.Context) (*accounting.InvoiceReminders, error) {
+	return accounting.FindInvoiceRemindersContext(s.client.context(ctx), s.client.http)
+}
+
+// InvoicesService handles the calls to the Invoices endpoint
+type InvoicesService struct {
+	client *Client
+}
+
+// List will get a page of the invoices matching the given queryParameters, a
+// zero modifiedSince will not filter by date
+func (s *InvoicesService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Invoices, error) {
+	return accounting.FindInvoicesModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// All will get the invoices of all the pages
+func (s *InvoicesService) All(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Invoices, error) {
+	return accounting.FindAllInvoicesContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Iterator returns an iterator walking all the pages of invoices
+func (s *InvoicesService) Iterator(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) *accounting.InvoiceIterator {
+	return accounting.NewInvoiceIterator(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the invoice with the given ID
+func (s *InvoicesService) Get(ctx context.Context, invoiceID uuid.UUID) (*accounting.Invoice, error) {
+	return accounting.FindInvoiceContext(s.client.context(ctx), s.client.http, invoiceID)
+}
+
+// Create will create the given invoices
+func (s *InvoicesService) Create(ctx context.Context, invoices *accounting.Invoices) (*accounting.Invoices, error) {
+	return invoices.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// CreateAll will create the given invoices in batches, with a result per element
+func (s *InvoicesService) CreateAll(ctx context.Context, invoices *accounting.Invoices) ([]accounting.InvoiceResult, error) {
+	return invoices.CreateAllContext(s.client.context(ctx), s.client.http)
+}
+
+// UpdateAll will create or update the given invoices in batches, with a result per element
+func (s *InvoicesService) UpdateAll(ctx context.Context, invoices *accounting.Invoices) ([]accounting.InvoiceResult, error) {
+	return invoices.UpdateAllContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will update the given invoice
+func (s *InvoicesService) Update(ctx context.Context, invoice *accounting.Invoice) (*accounting.Invoices, error) {
+	return invoice.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the invoice, even when they are zero
+func (s *InvoicesService) Patch(ctx context.Context, invoice *accounting.Invoice, fields ...string) (*accounting.Invoices, error) {
+	return invoice.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// ItemsService handles the calls to the Items endpoint
+type ItemsService struct {
+	client *Client
+}
+
+// List will get the items matching the given queryParameters, a zero
+// modifiedSince will not filter by date
+func (s *ItemsService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Items, error) {
+	return accounting.FindItemsContext(s.client.context(ctx), s.client.http, helpers.ModifiedSinceHeaders(modifiedSince), queryParameters)
+}
+
+// Get will get the item with the given ID
+func (s *ItemsService) Get(ctx context.Context, itemID uuid.UUID) (*accounting.Item, error) {
+	return accounting.FindItemContext(s.client.context(ctx), s.client.http, itemID)
+}
+
+// Create will create the given items
+func (s *ItemsService) Create(ctx context.Context, items *accounting.Items) (*accounting.Items, error) {
+	return items.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// CreateAll will create the given items in batches, with a result per element
+func (s *ItemsService) CreateAll(ctx context.Context, items *accounting.Items) ([]accounting.ItemResult, error) {
+	return items.CreateAllContext(s.client.context(ctx), s.client.http)
+}
+
+// UpdateAll will create or update the given items in batches, with a result per element
+func (s *ItemsService) UpdateAll(ctx context.Context, items *accounting.Items) ([]accounting.ItemResult, error) {
+	return items.UpdateAllContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will update the given item
+func (s *ItemsService) Update(ctx context.Context, item *accounting.Item) (*accounting.Items, error) {
+	return item.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the item, even when they are zero
+func (s *ItemsService) Patch(ctx context.Context, item *accounting.Item, fields ...string) (*accounting.Items, error) {
+	return item.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// Remove will delete the item with the given ID
+func (s *ItemsService) Remove(ctx context.Context, itemID uuid.UUID) (*accounting.Items, error) {
+	return accounting.RemoveItemContext(s.client.context(ctx), s.client.http, itemID)
+}
+
+// JournalsService handles the calls to the Journals endpoint
+type JournalsService struct {
+	client *Client
+}
+
+// List will get up to 100 journals with a JournalNumber greater than the
+// given offset, a zero modifiedSince will not filter by date
+func (s *JournalsService) List(ctx context.Context, modifiedSince time.Time, offset int, queryParameters map[string]string) (*accounting.Journals, error) {
+	return accounting.FindJournalsModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, offset, queryParameters)
+}
+
+// All will get all the journals after the given offset
+func (s *JournalsService) All(ctx context.Context, modifiedSince time.Time, offset int, queryParameters map[string]string) (*accounting.Journals, error) {
+	return accounting.FindAllJournalsContext(s.client.context(ctx), s.client.http, modifiedSince, offset, queryParameters)
+}
+
+// Iterator returns an iterator walking the journals after the given offset
+func (s *JournalsService) Iterator(ctx context.Context, modifiedSince time.Time, offset int, queryParameters map[string]string) *accounting.JournalIterator {
+	return accounting.NewJournalIterator(s.client.context(ctx), s.client.http, modifiedSince, offset, queryParameters)
+}
+
+// Get will get the journal with the given ID
+func (s *JournalsService) Get(ctx context.Context, journalID uuid.UUID) (*accounting.Journal, error) {
+	return accounting.FindJournalContext(s.client.context(ctx), s.client.http, journalID)
+}
+
+// ManualJournalsService handles the calls to the ManualJournals endpoint
+type ManualJournalsService struct {
+	client *Client
+}
+
+// List will get a page of the manual journals matching the given
+// queryParameters, a zero modifiedSince will not filter by date
+func (s *ManualJournalsService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.ManualJournals, error) {
+	return accounting.FindManualJournalsModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// All will get the manual journals of all the pages
+func (s *ManualJournalsService) All(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.ManualJournals, error) {
+	return accounting.FindAllManualJournalsContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Iterator returns an iterator walking all the pages of manual journals
+func (s *ManualJournalsService) Iterator(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) *accounting.ManualJournalIterator {
+	return accounting.NewManualJournalIterator(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the manual journal with the given ID
+func (s *ManualJournalsService) Get(ctx context.Context, manualJournalID uuid.UUID) (*accounting.ManualJournal, error) {
+	return accounting.FindManualJournalContext(s.client.context(ctx), s.client.http, manualJournalID)
+}
+
+// Create will create the given manual journals, their lines must balance
+func (s *ManualJournalsService) Create(ctx context.Context, manualJournals *accounting.ManualJournals) (*accounting.ManualJournals, error) {
+	return manualJournals.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will update the given manual journal
+func (s *ManualJournalsService) Update(ctx context.Context, manualJournal *accounting.ManualJournal) (*accounting.ManualJournals, error) {
+	return manualJournal.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the manual journal, even when they are zero
+func (s *ManualJournalsService) Patch(ctx context.Context, manualJournal *accounting.ManualJournal, fields ...string) (*accounting.ManualJournals, error) {
+	return manualJournal.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// OrganisationsService handles the calls to the Organisations endpoint
+type OrganisationsService struct {
+	client *Client
+}
+
+// List will get the organisation of the tenant
+func (s *OrganisationsService) List(ctx context.Context) (*accounting.OrganisationCollection, error) {
+	return accounting.FindOrganisationsContext(s.client.context(ctx), s.client.http)
+}
+
+// OverpaymentsService handles the calls to the Overpayments endpoint
+type OverpaymentsService struct {
+	client *Client
+}
+
+// List will get a page of the overpayments matching the given queryParameters, a
+// zero modifiedSince will not filter by date
+func (s *OverpaymentsService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Overpayments, error) {
+	return accounting.FindOverpaymentsModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// All will get the overpayments of all the pages
+func (s *OverpaymentsService) All(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Overpayments, error) {
+	return accounting.FindAllOverpaymentsContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Iterator returns an iterator walking all the pages of overpayments
+func (s *OverpaymentsService) Iterator(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) *accounting.OverpaymentIterator {
+	return accounting.NewOverpaymentIterator(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the overpayment with the given ID
+func (s *OverpaymentsService) Get(ctx context.Context, overpaymentID uuid.UUID) (*accounting.Overpayment, error) {
+	return accounting.FindOverpaymentContext(s.client.context(ctx), s.client.http, overpaymentID)
+}
+
+// Allocate will apply the overpayment to the invoices of the given allocations
+func (s *OverpaymentsService) Allocate(ctx context.Context, overpayment *accounting.Overpayment, allocations ...accounting.Allocation) (*accounting.Allocations, error) {
+	return overpayment.AllocateContext(s.client.context(ctx), s.client.http, allocations...)
+}
+
+// Refund will refund the overpayment with the given payment
+func (s *OverpaymentsService) Refund(ctx context.Context, overpayment *accounting.Overpayment, payment accounting.Payment) (*accounting.Payments, error) {
+	return overpayment.RefundContext(s.client.context(ctx), s.client.http, payment)
+}
+
+// PaymentsService handles the calls to the Payments endpoint
+type PaymentsService struct {
+	client *Client
+}
+
+// List will get a page of the payments matching the given queryParameters, a
+// zero modifiedSince will not filter by date
+func (s *PaymentsService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Payments, error) {
+	return accounting.FindPaymentsModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// All will get the payments of all the pages
+func (s *PaymentsService) All(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Payments, error) {
+	return accounting.FindAllPaymentsContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Iterator returns an iterator walking all the pages of payments
+func (s *PaymentsService) Iterator(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) *accounting.PaymentIterator {
+	return accounting.NewPaymentIterator(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the payment with the given ID
+func (s *PaymentsService) Get(ctx context.Context, paymentID uuid.UUID) (*accounting.Payment, error) {
+	return accounting.FindPaymentContext(s.client.context(ctx), s.client.http, paymentID)
+}
+
+// Create will create the given payments
+func (s *PaymentsService) Create(ctx context.Context, payments *accounting.Payments) (*accounting.Payments, error) {
+	return payments.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// CreateAll will create the given payments in batches, with a result per element
+func (s *PaymentsService) CreateAll(ctx context.Context, payments *accounting.Payments) ([]accounting.PaymentResult, error) {
+	return payments.CreateAllContext(s.client.context(ctx), s.client.http)
+}
+
+// Remove will delete the payment with the given ID, setting its status to DELETED
+func (s *PaymentsService) Remove(ctx context.Context, paymentID uuid.UUID) (*accounting.Payments, error) {
+	return accounting.RemovePaymentContext(s.client.context(ctx), s.client.http, paymentID)
+}
+
+// PrepaymentsService handles the calls to the Prepayments endpoint
+type PrepaymentsService struct {
+	client *Client
+}
+
+// List will get a page of the prepayments matching the given queryParameters, a
+// zero modifiedSince will not filter by date
+func (s *PrepaymentsService) List(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Prepayments, error) {
+	return accounting.FindPrepaymentsModifiedSinceContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// All will get the prepayments of all the pages
+func (s *PrepaymentsService) All(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) (*accounting.Prepayments, error) {
+	return accounting.FindAllPrepaymentsContext(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Iterator returns an iterator walking all the pages of prepayments
+func (s *PrepaymentsService) Iterator(ctx context.Context, modifiedSince time.Time, queryParameters map[string]string) *accounting.PrepaymentIterator {
+	return accounting.NewPrepaymentIterator(s.client.context(ctx), s.client.http, modifiedSince, queryParameters)
+}
+
+// Get will get the prepayment with the given ID
+func (s *PrepaymentsService) Get(ctx context.Context, prepaymentID uuid.UUID) (*accounting.Prepayment, error) {
+	return accounting.FindPrepaymentContext(s.client.context(ctx), s.client.http, prepaymentID)
+}
+
+// Allocate will apply the prepayment to the invoices of the given allocations
+func (s *PrepaymentsService) Allocate(ctx context.Context, prepayment *accounting.Prepayment, allocations ...accounting.Allocation) (*accounting.Allocations, error) {
+	return prepayment.AllocateContext(s.client.context(ctx), s.client.http, allocations...)
+}
+
+// Refund will refund the prepayment with the given payment
+func (s *PrepaymentsService) Refund(ctx context.Context, prepayment *accounting.Prepayment, payment accounting.Payment) (*accounting.Payments, error) {
+	return prepayment.RefundContext(s.client.context(ctx), s.client.http, payment)
+}
+
+// ReportsService handles the calls to the Reports endpoint
+type ReportsService struct {
+	client *Client
+}
+
+// Get will get the report with the given name, for the reports without a
+// typed method
+func (s *ReportsService) Get(ctx context.Context, name string, queryParameters map[string]string) (*accounting.Report, error) {
+	return accounting.FindReportContext(s.client.context(ctx), s.client.http, name, queryParameters)
+}
+
+// BalanceSheet will get the balance sheet
+func (s *ReportsService) BalanceSheet(ctx context.Context, p accounting.BalanceSheetParameters) (*accounting.Report, error) {
+	return accounting.FindBalanceSheetContext(s.client.context(ctx), s.client.http, p)
+}
+
+// ProfitAndLoss will get the profit and loss
+func (s *ReportsService) ProfitAndLoss(ctx context.Context, p accounting.ProfitAndLossParameters) (*accounting.Report, error) {
+	return accounting.FindProfitAndLossContext(s.client.context(ctx), s.client.http, p)
+}
+
+// TrialBalance will get the trial balance
+func (s *ReportsService) TrialBalance(ctx context.Context, p accounting.TrialBalanceParameters) (*accounting.Report, error) {
+	return accounting.FindTrialBalanceContext(s.client.context(ctx), s.client.http, p)
+}
+
+// AgedReceivablesByContact will get the aged receivables of a contact
+func (s *ReportsService) AgedReceivablesByContact(ctx context.Context, p accounting.AgedReportParameters) (*accounting.Report, error) {
+	return accounting.FindAgedReceivablesByContactContext(s.client.context(ctx), s.client.http, p)
+}
+
+// AgedPayablesByContact will get the aged payables of a contact
+func (s *ReportsService) AgedPayablesByContact(ctx context.Context, p accounting.AgedReportParameters) (*accounting.Report, error) {
+	return accounting.FindAgedPayablesByContactContext(s.client.context(ctx), s.client.http, p)
+}
+
+// BankSummary will get the bank summary
+func (s *ReportsService) BankSummary(ctx context.Context, p accounting.BankSummaryParameters) (*accounting.Report, error) {
+	return accounting.FindBankSummaryContext(s.client.context(ctx), s.client.http, p)
+}
+
+// BudgetSummary will get the budget summary
+func (s *ReportsService) BudgetSummary(ctx context.Context, p accounting.BudgetSummaryParameters) (*accounting.Report, error) {
+	return accounting.FindBudgetSummaryContext(s.client.context(ctx), s.client.http, p)
+}
+
+// ExecutiveSummary will get the executive summary
+func (s *ReportsService) ExecutiveSummary(ctx context.Context, p accounting.ExecutiveSummaryParameters) (*accounting.Report, error) {
+	return accounting.FindExecutiveSummaryContext(s.client.context(ctx), s.client.http, p)
+}
+
+// TrackingCategoriesService handles the calls to the TrackingCategories endpoint
+type TrackingCategoriesService struct {
+	client *Client
+}
+
+// List will get the tracking categories matching the given queryParameters,
+// see Query.IncludeArchived for getting the archived ones too
+func (s *TrackingCategoriesService) List(ctx context.Context, queryParameters map[string]string) (*accounting.TrackingCategories, error) {
+	return accounting.FindTrackingCategoriesContext(s.client.context(ctx), s.client.http, queryParameters)
+}
+
+// Get will get the tracking category with the given ID
+func (s *TrackingCategoriesService) Get(ctx context.Context, trackingCategoryID uuid.UUID) (*accounting.TrackingCategory, error) {
+	return accounting.FindTrackingCategoryContext(s.client.context(ctx), s.client.http, trackingCategoryID)
+}
+
+// Create will create the given tracking category
+func (s *TrackingCategoriesService) Create(ctx context.Context, trackingCategory *accounting.TrackingCategory) (*accounting.TrackingCategories, error) {
+	return trackingCategory.CreateContext(s.client.context(ctx), s.client.http)
+}
+
+// Update will rename or change the status of the given tracking category
+func (s *TrackingCategoriesService) Update(ctx context.Context, trackingCategory *accounting.TrackingCategory) (*accounting.TrackingCategories, error) {
+	return trackingCategory.UpdateContext(s.client.context(ctx), s.client.http)
+}
+
+// Patch will update only the given fields of the tracking category, even when they are zero
+func (s *TrackingCategoriesService) Patch(ctx context.Context, trackingCategory *accounting.TrackingCategory, fields ...string) (*accounting.TrackingCategories, error) {
+	return trackingCategory.PatchContext(s.client.context(ctx), s.client.http, fields...)
+}
+
+// Remove will delete the tracking category with the given ID
+func (s *TrackingCategoriesService) Remove(ctx context.Context, trackingCategoryID uuid.UUID) (*accounting.TrackingCategories, error) {
+	return accounting.RemoveTrackingCategoryContext(s.client.context(ctx), s.client.http, trackingCategoryID)
+}
+
+// AddOption will add the given option to the tracking category
+func (s *TrackingCategoriesService) AddOption(ctx context.Context, trackingCategory *accounting.TrackingCategory, option accounting.TrackingOption) (*accounting.Options, error) {
+	return trackingCategory.AddOptionContext(s.client.context(ctx), s.client.http, option)
+}
+
+// UpdateOption will rename or change the status of the given option of the tracking category
+func (s *TrackingCategoriesService) UpdateOption(ctx context.Context, trackingCategory *accounting.TrackingCategory, option accounting.TrackingOption) (*accounting.Options, error) {
+	return trackingCategory.UpdateOptionContext(s.client.context(ctx), s.client.http, option)
+}
+
+// RemoveOption will delete the option with the given ID from the tracking category
+func (s *TrackingCategoriesService) RemoveOption(ctx context.Context, trackingCategory *accounting.TrackingCategory, trackingOptionID uuid.UUID) (*accounting.Options, error) {
+	return trackingCategory.RemoveOptionContext(s.client.context(ctx), s.client.http, trackingOptionID)
+}
+
+// ConnectionsService handles the calls to the connections endpoint
+type ConnectionsService struct {
+	client *Client
+}
+
+// List will get the tenants connected
+func (s *ConnectionsService) List(ctx context.Context) ([]connection.Tenant, error) {
+	return connection.GetTenantsContext(s.client.context(ctx), s.client.http)
+}
+
+// Remove will remove the connection with the given ID
+func (s *ConnectionsService) Remove(ctx context.Context, connectionID uuid.UUID) error {
+	return connection.DeleteTenantContext(s.client.context(ctx), s.client.http, connectionID)
+}
@@ -0,0 +1,421 @@
+package xerotest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/auth"
+)
+
+// Mode tells if a Recorder saves the real responses or serves the saved ones
+type Mode int
+
+const (
+	// Replay serves the interactions of the cassette without reaching the
+	// network
+	Replay Mode = iota
+	// Record sends the requests with the wrapped transport and saves them
+	// together with their responses in the cassette
+	Record
+)
+
+const redacted = "REDACTED"
+
+// base64Encoding is the Encoding of the bodies saved in base64, the ones that
+// are neither JSON nor a form like the content of the attachments
+const base64Encoding = "base64"
+
+// DefaultScrubFields are the JSON, form and query fields holding tokens or
+// personal information that are redacted before saving a cassette
+var DefaultScrubFields = []string{
+	"access_token", "refresh_token", "id_token",
+	"Name", "FirstName", "LastName", "EmailAddress",
+	"PhoneNumber", "PhoneAreaCode", "PhoneCountryCode",
+	"AddressLine1", "AddressLine2", "AddressLine3", "AddressLine4",
+	"BankAccountNumber", "BankAccountName", "BankAccountDetails", "TaxNumber",
+}
+
+// recordedHeaders are the request headers kept in the cassette, any other one
+// is dropped
+var recordedHeaders = []string{"Accept", "Content-Type", modifiedSinceHeader}
+
+// redactedHeaders are the request headers kept in the cassette with their
+// value redacted
+var redactedHeaders = []string{"Authorization"}
+
+// secretFormFields are the fields of the token requests always redacted, the
+// JSON bodies are not checked for them since e.g. Code is also the code of
+// the accounts
+var secretFormFields = []string{"code", "client_secret", "code_verifier", "refresh_token", "client_assertion"}
+
+// Cassette is the content of a cassette file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request with its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the scrubbed request of an Interaction. The TenantID is
+// replaced by a UUID derived from it, so the real one is never saved
+type RecordedRequest struct {
+	Method   string      `json:"method"`
+	Path     string      `json:"path"`
+	Query    string      `json:"query,omitempty"`
+	TenantID string      `json:"tenantId,omitempty"`
+	Header   http.Header `json:"header,omitempty"`
+	Body     string      `json:"body,omitempty"`
+	// Encoding is "base64" when the Body is a binary one saved in base64
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// RecordedResponse is the scrubbed response of an Interaction
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	// Encoding is "base64" when the Body is a binary one saved in base64
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Recorder is an http.RoundTripper saving the interactions with Xero in a
+// cassette file and serving them back later. It can wrap an
+// auth.XeroTransport or be used as its T.
+//
+// The interactions are matched on the method, path, query and xero-tenant-id
+// of the requests and each one is served once, in the order they were saved,
+// so repeated calls get the same sequence of responses than when recording.
+// When replaying, the tenant can be either the real one or the UUID that
+// replaced it in the cassette.
+type Recorder struct {
+	// T is the transport used for sending the requests in Record mode
+	T    http.RoundTripper
+	Mode Mode
+	Path string
+	// ScrubFields are the fields redacted in the bodies, the queries and the
+	// where filters, DefaultScrubFields when nil
+	ScrubFields []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder returns a Recorder using the cassette file at path. In Replay
+// mode the file is loaded straight away, in Record mode it is overwritten
+// with each new interaction
+func NewRecorder(path string, mode Mode, t http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{
+		T:    t,
+		Mode: mode,
+		Path: path,
+	}
+	if mode == Record {
+		return r, nil
+	}
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &r.cassette); err != nil {
+		return nil, err
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Interactions returns the interactions of the cassette
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// RoundTrip method will record or replay the request depending on the Mode
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.Mode == Record {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	res, err := r.transport().RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	tenantID := r.tenantID(req)
+	interaction := Interaction{
+		Request: r.recordRequest(req, tenantID),
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     recordResponseHeader(res.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.Encoding = r.recordBody(body, req.Header.Get("Content-Type"), tenantID)
+	interaction.Response.Body, interaction.Response.Encoding = r.recordBody(resBody, res.Header.Get("Content-Type"), tenantID)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	// the tenant may be the real one, or the one found in the cassette
+	tenantID := r.tenantID(req)
+	hidden := r.recordRequest(req, tenantID)
+	given := r.recordRequest(req, "")
+	given.TenantID = tenantID
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n, interaction := range r.cassette.Interactions {
+		if r.used[n] || !(interaction.Request.matches(hidden) || interaction.Request.matches(given)) {
+			continue
+		}
+		body := []byte(interaction.Response.Body)
+		if interaction.Response.Encoding == base64Encoding {
+			var err error
+			if body, err = base64.StdEncoding.DecodeString(interaction.Response.Body); err != nil {
+				return nil, fmt.Errorf("xerotest: the body of the interaction %d in %s is not valid base64: %v", n, r.Path, err)
+			}
+		}
+		r.used[n] = true
+		header := http.Header{}
+		for key, values := range interaction.Response.Header {
+			header[key] = append([]string(nil), values...)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("xerotest: no interaction left in %s for %s %s", r.Path, req.Method, req.URL)
+}
+
+func (r *Recorder) transport() http.RoundTripper {
+	if r.T == nil {
+		return http.DefaultTransport
+	}
+	return r.T
+}
+
+// tenantID returns the tenant of the request, taken from the TenantID of the
+// wrapped auth.XeroTransport when the header is not set yet
+func (r *Recorder) tenantID(req *http.Request) string {
+	if tenantID := req.Header.Get(tenantIDHeader); tenantID != "" {
+		return tenantID
+	}
+	if xt, ok := r.T.(*auth.XeroTransport); ok && xt.TenantID != uuid.Nil {
+		return xt.TenantID.String()
+	}
+	return ""
+}
+
+func (r *Recorder) recordRequest(req *http.Request, tenantID string) RecordedRequest {
+	recorded := RecordedRequest{
+		Method:   req.Method,
+		Path:     replaceTenant(req.URL.Path, tenantID),
+		Query:    replaceTenant(r.scrubValues(req.URL.Query()).Encode(), tenantID),
+		TenantID: replaceTenant(tenantID, tenantID),
+	}
+	for _, key := range append(recordedHeaders, redactedHeaders...) {
+		value := req.Header.Get(key)
+		if value == "" {
+			continue
+		}
+		if containsFold(redactedHeaders, key) {
+			value = redacted
+		}
+		if recorded.Header == nil {
+			recorded.Header = http.Header{}
+		}
+		recorded.Header.Set(key, value)
+	}
+	return recorded
+}
+
+func (rr RecordedRequest) matches(other RecordedRequest) bool {
+	return rr.Method == other.Method &&
+		rr.Path == other.Path &&
+		rr.Query == other.Query &&
+		strings.EqualFold(rr.TenantID, other.TenantID)
+}
+
+func recordResponseHeader(h http.Header) http.Header {
+	header := http.Header{}
+	for key, values := range h {
+		switch http.CanonicalHeaderKey(key) {
+		case "Set-Cookie", "Content-Length", "Date":
+			continue
+		}
+		header[key] = append([]string(nil), values...)
+	}
+	return header
+}
+
+func (r *Recorder) scrubFields() []string {
+	if r.ScrubFields == nil {
+		return DefaultScrubFields
+	}
+	return r.ScrubFields
+}
+
+// recordBody returns the body as saved in the cassette with its Encoding. The
+// form encoded bodies, like the ones sent to the token endpoint, and the JSON
+// ones are scrubbed, any other is saved untouched in base64 since it may not
+// be valid UTF-8
+func (r *Recorder) recordBody(body []byte, contentType string, tenantID string) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return redacted, ""
+		}
+		return replaceTenant(r.scrubValues(values).Encode(), tenantID), ""
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || (mediaType == "" && json.Valid(body)):
+		return r.scrub(body, tenantID), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), base64Encoding
+}
+
+// scrubValues redacts the ScrubFields and the secretFormFields of a query or
+// a form, and the values compared with the ScrubFields in a where filter
+func (r *Recorder) scrubValues(values url.Values) url.Values {
+	fields := append(append([]string(nil), r.scrubFields()...), secretFormFields...)
+	for key, v := range values {
+		switch {
+		case containsFold(fields, key):
+			for n := range v {
+				v[n] = redacted
+			}
+		case strings.EqualFold(key, "where"):
+			for n := range v {
+				v[n] = r.scrubWhere(v[n])
+			}
+		}
+	}
+	return values
+}
+
+// scrubWhere redacts the strings compared with the ScrubFields in a where
+// filter, e.g. EmailAddress=="a@b.c" or Contact.Name.StartsWith("AC")
+func (r *Recorder) scrubWhere(where string) string {
+	fields := r.scrubFields()
+	if len(fields) == 0 {
+		return where
+	}
+	names := make([]string, len(fields))
+	for n, field := range fields {
+		names[n] = regexp.QuoteMeta(field)
+	}
+	pattern := regexp.MustCompile(`(?i)\b((?:` + strings.Join(names, "|") + `)(?:\.To(?:Lower|Upper)\(\))?\s*(?:==|!=|>=|<=|>|<|\.(?:Contains|StartsWith|EndsWith)\()\s*)"(?:[^"\\]|\\.)*"`)
+	return pattern.ReplaceAllString(where, `${1}"`+redacted+`"`)
+}
+
+// scrub redacts the ScrubFields of a JSON body and hides the tenant ID, any
+// other body is only stripped of the tenant ID
+func (r *Recorder) scrub(body []byte, tenantID string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err == nil {
+		if buf, err := json.Marshal(scrubValue(v, r.scrubFields())); err == nil {
+			body = buf
+		}
+	}
+	return replaceTenant(string(body), tenantID)
+}
+
+func scrubValue(v interface{}, fields []string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if containsFold(fields, key) {
+				if field != nil {
+					value[key] = redacted
+				}
+				continue
+			}
+			value[key] = scrubValue(field, fields)
+		}
+	case []interface{}:
+		for n := range value {
+			value[n] = scrubValue(value[n], fields)
+		}
+	}
+	return v
+}
+
+// replaceTenant replaces the tenant ID in s by a UUID derived from it, so the
+// cassettes keep a valid and stable UUID without disclosing the real one
+func replaceTenant(s string, tenantID string) string {
+	if tenantID == "" {
+		return s
+	}
+	hidden := uuid.NewV5(uuid.NamespaceURL, "xero-tenant:"+strings.ToLower(tenantID)).String()
+	s = strings.Replace(s, strings.ToLower(tenantID), hidden, -1)
+	return strings.Replace(s, strings.ToUpper(tenantID), hidden, -1)
+}
+
+// save must be called holding the lock
+func (r *Recorder) save() error {
+	buf, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.Path, buf, os.FileMode(0644))
+}
@@ -0,0 +1,318 @@
+package xerotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/auth"
+	"golang.org/x/oauth2"
+)
+
+// png is the start of a PNG file, which is not valid UTF-8
+var png = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0x00, 0x00, 0x0d, 0xff, 0xfe, 0x80}
+
+func TestRecorderRoundTrip(t *testing.T) {
+	path := cassettePath(t)
+	s := NewServer()
+	tenantID := s.TenantID()
+	token := s.Token()
+
+	recorder, err := NewRecorder(path, Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := cassetteClient(recorder, tenantID, token)
+	status, created := send(t, cl, http.MethodPut, s.URL()+accountingPath+"/Contacts", "application/json", []byte(`{"Contacts":[{"Name":"ACME","EmailAddress":"info@acme.test","IsSupplier":true}]}`))
+	if status != http.StatusOK {
+		t.Fatalf("PUT Contacts = %d %s", status, created)
+	}
+	id := firstID(t, created, "Contacts", "ContactID")
+	status, recorded := send(t, cl, http.MethodGet, s.URL()+accountingPath+"/Contacts/"+id, "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("GET Contacts/%s = %d %s", id, status, recorded)
+	}
+	s.Close()
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"ACME", "info@acme.test", token.AccessToken, tenantID.String()} {
+		if bytes.Contains(buf, []byte(secret)) {
+			t.Errorf("the cassette holds %q:\n%s", secret, buf)
+		}
+	}
+
+	replayer, err := NewRecorder(path, Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl = cassetteClient(replayer, tenantID, token)
+	if status, _ := send(t, cl, http.MethodPut, s.URL()+accountingPath+"/Contacts", "application/json", []byte(`{"Contacts":[{"Name":"ACME"}]}`)); status != http.StatusOK {
+		t.Errorf("replayed PUT Contacts = %d, want %d", status, http.StatusOK)
+	}
+	status, replayed := send(t, cl, http.MethodGet, s.URL()+accountingPath+"/Contacts/"+id, "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("replayed GET Contacts/%s = %d", id, status)
+	}
+	if got := firstID(t, replayed, "Contacts", "ContactID"); got != id {
+		t.Errorf("replayed ContactID = %s, want %s", got, id)
+	}
+	if !bytes.Contains(replayed, []byte(`"IsSupplier":true`)) || !bytes.Contains(replayed, []byte(`"Name":"REDACTED"`)) {
+		t.Errorf("replayed %s, want the recorded contact with its Name redacted", replayed)
+	}
+}
+
+func TestRecorderTenant(t *testing.T) {
+	path := cassettePath(t)
+	s := NewServer()
+	tenantID := s.TenantID()
+	token := s.Token()
+
+	recorder, err := NewRecorder(path, Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, body := send(t, cassetteClient(recorder, tenantID, token), http.MethodGet, s.URL()+accountingPath+"/Organisations", "", nil)
+	if status != http.StatusOK {
+		t.Fatalf("GET Organisations = %d %s", status, body)
+	}
+	s.Close()
+
+	hidden := uuid.Must(uuid.FromString(replaceTenant(tenantID.String(), tenantID.String())))
+	if hidden == tenantID {
+		t.Fatal("the tenant is not replaced")
+	}
+	interaction := recorder.Interactions()[0]
+	if interaction.Request.TenantID != hidden.String() {
+		t.Errorf("recorded tenant = %s, want %s", interaction.Request.TenantID, hidden)
+	}
+	if !strings.Contains(interaction.Response.Body, hidden.String()) || strings.Contains(strings.ToLower(interaction.Response.Body), tenantID.String()) {
+		t.Errorf("recorded body %s, want the OrganisationID replaced by %s", interaction.Response.Body, hidden)
+	}
+
+	for _, tenant := range []uuid.UUID{tenantID, hidden} {
+		replayer, err := NewRecorder(path, Replay, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status, _ := send(t, cassetteClient(replayer, tenant, token), http.MethodGet, s.URL()+accountingPath+"/Organisations", "", nil); status != http.StatusOK {
+			t.Errorf("replayed for the tenant %s = %d, want %d", tenant, status, http.StatusOK)
+		}
+	}
+	replayer, err := NewRecorder(path, Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cassetteClient(replayer, uuid.Must(uuid.NewV4()), token).Get(s.URL() + accountingPath + "/Organisations"); err == nil {
+		t.Error("replayed for another tenant, want an error")
+	}
+}
+
+func TestRecorderNoInteractionLeft(t *testing.T) {
+	path := cassettePath(t)
+	s := NewServer()
+	tenantID := s.TenantID()
+	token := s.Token()
+
+	recorder, err := NewRecorder(path, Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send(t, cassetteClient(recorder, tenantID, token), http.MethodGet, s.URL()+accountingPath+"/Contacts", "", nil)
+	s.Close()
+
+	replayer, err := NewRecorder(path, Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := cassetteClient(replayer, tenantID, token)
+	send(t, cl, http.MethodGet, s.URL()+accountingPath+"/Contacts", "", nil)
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "already served", url: s.URL() + accountingPath + "/Contacts"},
+		{name: "other query", url: s.URL() + accountingPath + "/Contacts?page=2"},
+		{name: "never recorded", url: s.URL() + accountingPath + "/Invoices"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cl.Get(tt.url)
+			if err == nil || !strings.Contains(err.Error(), "no interaction left") {
+				t.Errorf("error = %v, want no interaction left", err)
+			}
+		})
+	}
+}
+
+func TestRecorderBinaryBody(t *testing.T) {
+	path := cassettePath(t)
+	s := NewServer()
+	tenantID := s.TenantID()
+	token := s.Token()
+	if err := s.Seed(tenantID, "Invoices", map[string]interface{}{"InvoiceID": "9b6b4c3e-ffb2-4a9b-a4a3-3c4b0b6e0e51"}); err != nil {
+		t.Fatal(err)
+	}
+	attachment := s.URL() + accountingPath + "/Invoices/9b6b4c3e-ffb2-4a9b-a4a3-3c4b0b6e0e51/Attachments/logo.png"
+
+	recorder, err := NewRecorder(path, Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := cassetteClient(recorder, tenantID, token)
+	if status, body := send(t, cl, http.MethodPut, attachment, "image/png", png); status != http.StatusOK {
+		t.Fatalf("PUT %s = %d %s", attachment, status, body)
+	}
+	if status, body := send(t, cl, http.MethodGet, attachment, "", nil); status != http.StatusOK || !bytes.Equal(body, png) {
+		t.Fatalf("GET %s = %d %v, want %v", attachment, status, body, png)
+	}
+	s.Close()
+
+	interactions := recorder.Interactions()
+	if upload := interactions[0].Request; upload.Encoding != base64Encoding {
+		t.Errorf("upload saved with the encoding %q, want %q", upload.Encoding, base64Encoding)
+	}
+	if uploaded := interactions[0].Response; uploaded.Encoding != "" || !strings.Contains(uploaded.Body, `"FileName":"logo.png"`) {
+		t.Errorf("upload response saved as %q %s, want the JSON", uploaded.Encoding, uploaded.Body)
+	}
+	if download := interactions[1].Response; download.Encoding != base64Encoding {
+		t.Errorf("download saved with the encoding %q, want %q", download.Encoding, base64Encoding)
+	}
+
+	replayer, err := NewRecorder(path, Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl = cassetteClient(replayer, tenantID, token)
+	send(t, cl, http.MethodPut, attachment, "image/png", png)
+	status, body := send(t, cl, http.MethodGet, attachment, "", nil)
+	if status != http.StatusOK || !bytes.Equal(body, png) {
+		t.Errorf("replayed GET %s = %d %v, want %v", attachment, status, body, png)
+	}
+}
+
+func TestRecorderSaveError(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	recorder, err := NewRecorder(filepath.Join(filepath.Dir(cassettePath(t)), "missing", "cassette.json"), Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodGet, s.URL()+accountingPath+"/Contacts", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := recorder.RoundTrip(req)
+	if err == nil || res != nil {
+		t.Errorf("RoundTrip() = %v, %v, want no response and the error saving the cassette", res, err)
+	}
+}
+
+func TestScrubWhere(t *testing.T) {
+	tests := []struct {
+		name  string
+		where string
+		want  string
+	}{
+		{name: "equal", where: `EmailAddress=="a@b.c"`, want: `EmailAddress=="REDACTED"`},
+		{name: "not equal", where: `Name != "ACME"`, want: `Name != "REDACTED"`},
+		{name: "nested field", where: `Contact.Name=="ACME"`, want: `Contact.Name=="REDACTED"`},
+		{name: "method", where: `Name.StartsWith("AC")`, want: `Name.StartsWith("REDACTED")`},
+		{name: "lower case", where: `name.ToLower().Contains("acme")`, want: `name.ToLower().Contains("REDACTED")`},
+		{name: "escaped quote", where: `Name=="say \"hi\""`, want: `Name=="REDACTED"`},
+		{
+			name:  "other fields kept",
+			where: `Status=="ACTIVE" AND (FirstName=="Ann" OR LastName=="Lee") AND Total>10`,
+			want:  `Status=="ACTIVE" AND (FirstName=="REDACTED" OR LastName=="REDACTED") AND Total>10`,
+		},
+		{name: "field name suffix", where: `CompanyName=="ACME"`, want: `CompanyName=="ACME"`},
+		{name: "null", where: `EmailAddress!=null`, want: `EmailAddress!=null`},
+	}
+	r := &Recorder{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.scrubWhere(tt.where); got != tt.want {
+				t.Errorf("scrubWhere(%s) = %s, want %s", tt.where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecorderScrubsQuery(t *testing.T) {
+	path := cassettePath(t)
+	s := NewServer()
+	defer s.Close()
+	recorder, err := NewRecorder(path, Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send(t, cassetteClient(recorder, s.TenantID(), s.Token()), http.MethodGet, s.URL()+accountingPath+`/Contacts?where=EmailAddress%3D%3D%22a%40b.c%22&EmailAddress=a%40b.c&page=1`, "", nil)
+	query := recorder.Interactions()[0].Request.Query
+	if strings.Contains(query, "a%40b.c") || !strings.Contains(query, "page=1") {
+		t.Errorf("recorded query %s, want the EmailAddress redacted", query)
+	}
+}
+
+// send sends the request with the given client and returns the status and the
+// body of the response
+func send(t *testing.T, cl *http.Client, method string, url string, contentType string, body []byte) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	res, err := cl.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	buf, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, buf
+}
+
+// firstID returns the id of the first element of the collection in body
+func firstID(t *testing.T, body []byte, collection string, id string) string {
+	t.Helper()
+	var payload map[string]json.RawMessage
+	var elements []map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("%v: %s", err, body)
+	}
+	if err := json.Unmarshal(payload[collection], &elements); err != nil || len(elements) == 0 {
+		t.Fatalf("no %s in %s", collection, body)
+	}
+	value, _ := elements[0][id].(string)
+	return value
+}
+
+func cassettePath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "xerotest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "cassette.json")
+}
+
+func cassetteClient(recorder *Recorder, tenantID uuid.UUID, token *oauth2.Token) *http.Client {
+	return &http.Client{
+		Transport: &auth.Transport{
+			Base:   &auth.XeroTransport{T: recorder, TenantID: tenantID},
+			Source: oauth2.StaticTokenSource(token),
+		},
+	}
+}
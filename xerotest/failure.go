@@ -0,0 +1,122 @@
+package xerotest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Failure is a canned error response the Server will send instead of handling
+// the request. It is matched against the method and the path of the request
+// relative to the API root, e.g. "Invoices" or "Invoices/{id}" for the
+// accounting API, "connections" or "token"
+type Failure struct {
+	// Method of the requests to fail, empty matches every method
+	Method string
+	// Path prefix of the requests to fail, empty matches every path
+	Path string
+	// Times is how many requests will fail, zero means one
+	Times int
+
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// RateLimit returns a Failure answering with 429 Too Many Requests and the
+// given Retry-After, as Xero does when the minute limit is exceeded
+func RateLimit(path string, retryAfter time.Duration) Failure {
+	header := http.Header{}
+	header.Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+	header.Set("X-Rate-Limit-Problem", "minute")
+	header.Set("X-MinLimit-Remaining", "0")
+	return Failure{
+		Path:       path,
+		StatusCode: http.StatusTooManyRequests,
+		Header:     header,
+	}
+}
+
+// Unauthorized returns a Failure answering with 401 Unauthorized, as Xero does
+// when the access token is expired or revoked
+func Unauthorized(path string) Failure {
+	body, _ := json.Marshal(problem{
+		Title:  "Unauthorized",
+		Status: http.StatusUnauthorized,
+		Detail: "AuthenticationUnsuccessful",
+	})
+	return Failure{
+		Path:       path,
+		StatusCode: http.StatusUnauthorized,
+		Body:       string(body),
+	}
+}
+
+// ValidationFailure returns a Failure answering with 400 Bad Request and a
+// ValidationException with the given messages
+func ValidationFailure(path string, messages ...string) Failure {
+	return Failure{
+		Path:       path,
+		StatusCode: http.StatusBadRequest,
+		Body:       string(validationBody(messages)),
+	}
+}
+
+func validationBody(messages []string) []byte {
+	element := validationElement{}
+	for _, m := range messages {
+		element.ValidationErrors = append(element.ValidationErrors, validationMessage{Message: m})
+	}
+	body, _ := json.Marshal(apiException{
+		ErrorNumber: 10,
+		Type:        "ValidationException",
+		Message:     "A validation exception occurred",
+		Elements:    []validationElement{element},
+	})
+	return body
+}
+
+func (f *Failure) matches(method string, path string) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, method) {
+		return false
+	}
+	return strings.HasPrefix(path, strings.Trim(f.Path, "/"))
+}
+
+func (f *Failure) write(w http.ResponseWriter) {
+	for key, values := range f.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	if f.Body != "" && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(f.StatusCode)
+	w.Write([]byte(f.Body))
+}
+
+type problem struct {
+	Type     *string `json:"Type"`
+	Title    string  `json:"Title"`
+	Status   int     `json:"Status"`
+	Detail   string  `json:"Detail"`
+	Instance string  `json:"Instance,omitempty"`
+}
+
+type apiException struct {
+	ErrorNumber int                 `json:"ErrorNumber"`
+	Type        string              `json:"Type"`
+	Message     string              `json:"Message"`
+	Elements    []validationElement `json:"Elements,omitempty"`
+}
+
+type validationElement struct {
+	ValidationErrors []validationMessage `json:"ValidationErrors"`
+}
+
+type validationMessage struct {
+	Message string `json:"Message"`
+}
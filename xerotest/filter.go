@@ -0,0 +1,433 @@
+package xerotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// predicate tells if the fields of a record match a where filter
+type predicate func(fields map[string]interface{}) bool
+
+// guid is a Guid("...") literal, compared ignoring the case
+type guid string
+
+var dotNetDatePattern = regexp.MustCompile(`^/Date\((-?\d+)([+-]\d{4})?\)/$`)
+
+// parseWhere parses a where filter like the ones of the query builder: the
+// comparisons ==, !=, <, <=, > and >= and the Contains, StartsWith and
+// EndsWith methods on a field, nested ones with a dot like Contact.ContactID,
+// joined with AND, OR and parentheses. The literals are strings, numbers,
+// true, false, null, Guid("...") and DateTime(y,m,d[,h,m,s])
+func parseWhere(where string) (predicate, error) {
+	tokens, err := tokenize(where)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	match, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	return match, nil
+}
+
+// tokenize splits the filter in identifiers, strings, numbers and operators
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string %s", s[i:])
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j + 1
+		case strings.ContainsRune("=!<>", c):
+			j := i + 1
+			if j < len(s) && s[j] == '=' {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		case strings.ContainsRune("(),", c):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '-' || c == '.' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || s[j] == '_' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("unexpected end of the filter")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *parser) expect(token string) error {
+	next, err := p.next()
+	if err != nil {
+		return err
+	}
+	if next != token {
+		return fmt.Errorf("unexpected %s, want %s", next, token)
+	}
+	return nil
+}
+
+func (p *parser) or() (predicate, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "OR") {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(fields map[string]interface{}) bool { return l(fields) || right(fields) }
+	}
+	return left, nil
+}
+
+func (p *parser) and() (predicate, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "AND") {
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(fields map[string]interface{}) bool { return l(fields) && right(fields) }
+	}
+	return left, nil
+}
+
+func (p *parser) term() (predicate, error) {
+	if p.peek() == "(" {
+		p.pos++
+		match, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return match, p.expect(")")
+	}
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	for _, method := range []string{"Contains", "StartsWith", "EndsWith"} {
+		if !strings.HasSuffix(field, "."+method) {
+			continue
+		}
+		path := strings.TrimSuffix(field, "."+method)
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		literal, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		s, ok := literal.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s needs a string", method)
+		}
+		test := map[string]func(string, string) bool{
+			"Contains":   strings.Contains,
+			"StartsWith": strings.HasPrefix,
+			"EndsWith":   strings.HasSuffix,
+		}[method]
+		return func(fields map[string]interface{}) bool {
+			value, _ := lookup(fields, path).(string)
+			return test(value, s)
+		}, p.expect(")")
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	literal, err := p.literal()
+	if err != nil {
+		return nil, err
+	}
+	var test func(int) bool
+	switch op {
+	case "==":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	default:
+		return nil, fmt.Errorf("unknown operator %s", op)
+	}
+	return func(fields map[string]interface{}) bool {
+		value := lookup(fields, field)
+		if literal == nil {
+			// only == and != are meaningful with null
+			return (value == nil) == (op == "==")
+		}
+		c, ok := compare(value, literal)
+		return ok && test(c)
+	}, nil
+}
+
+func (p *parser) literal() (interface{}, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasPrefix(token, `"`):
+		return strconv.Unquote(token)
+	case strings.EqualFold(token, "null"):
+		return nil, nil
+	case strings.EqualFold(token, "true"), strings.EqualFold(token, "false"):
+		return strings.EqualFold(token, "true"), nil
+	case strings.EqualFold(token, "Guid"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		value, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("Guid needs a string")
+		}
+		return guid(s), p.expect(")")
+	case strings.EqualFold(token, "DateTime"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		parts := []int{}
+		for {
+			token, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			n, err := strconv.Atoi(token)
+			if err != nil {
+				return nil, fmt.Errorf("DateTime needs numbers, not %s", token)
+			}
+			parts = append(parts, n)
+			if p.peek() != "," {
+				break
+			}
+			p.pos++
+		}
+		if len(parts) != 3 && len(parts) != 6 {
+			return nil, fmt.Errorf("DateTime needs 3 or 6 numbers")
+		}
+		parts = append(parts, 0, 0, 0)
+		return time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, time.UTC), p.expect(")")
+	}
+	n, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected %s", token)
+	}
+	return n, nil
+}
+
+// lookup returns the value of the field at path ignoring the case, the dates
+// being also found with the String suffix the SDK sends them with, like
+// DateString for Date
+func lookup(fields map[string]interface{}, path string) interface{} {
+	var value interface{} = fields
+	for _, name := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = nil
+		for _, key := range []string{name, name + "String"} {
+			for k, v := range m {
+				if strings.EqualFold(k, key) {
+					value = v
+				}
+			}
+			if value != nil {
+				break
+			}
+		}
+	}
+	return value
+}
+
+// compare compares the value of a field with a literal of the filter, false
+// when they can't be compared
+func compare(value interface{}, literal interface{}) (int, bool) {
+	switch l := literal.(type) {
+	case float64:
+		n, ok := number(value)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case n < l:
+			return -1, true
+		case n > l:
+			return 1, true
+		}
+		return 0, true
+	case time.Time:
+		t, ok := parseTime(value)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case t.Before(l):
+			return -1, true
+		case t.After(l):
+			return 1, true
+		}
+		return 0, true
+	case bool:
+		b, ok := value.(bool)
+		if !ok || b != l {
+			return 1, ok
+		}
+		return 0, true
+	case guid:
+		s, ok := value.(string)
+		return strings.Compare(strings.ToLower(s), strings.ToLower(string(l))), ok
+	case string:
+		s, ok := value.(string)
+		return strings.Compare(s, l), ok
+	}
+	return 0, false
+}
+
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func parseTime(value interface{}) (time.Time, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	if m := dotNetDatePattern.FindStringSubmatch(s); m != nil {
+		ms, _ := strconv.ParseInt(m[1], 10, 64)
+		return time.Unix(0, ms*int64(time.Millisecond)).UTC(), true
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// sortRecords sorts the records by an order like "Date,Total DESC", the
+// fields are compared as numbers, dates or strings and the missing ones come
+// first
+func sortRecords(records []*record, order string) {
+	type key struct {
+		path string
+		desc bool
+	}
+	var keys []key
+	for _, part := range strings.Split(order, ",") {
+		words := strings.Fields(part)
+		if len(words) == 0 {
+			continue
+		}
+		keys = append(keys, key{path: words[0], desc: len(words) > 1 && strings.EqualFold(words[1], "DESC")})
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		for _, k := range keys {
+			c := compareFields(lookup(records[i].fields, k.path), lookup(records[j].fields, k.path))
+			if c == 0 {
+				continue
+			}
+			return (c < 0) != k.desc
+		}
+		return false
+	})
+}
+
+func compareFields(a interface{}, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	var literal interface{} = fmt.Sprint(b)
+	if n, ok := number(b); ok {
+		literal = n
+	} else if t, ok := parseTime(b); ok {
+		literal = t
+	}
+	if c, ok := compare(a, literal); ok {
+		return c
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
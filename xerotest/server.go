@@ -0,0 +1,861 @@
+// Package xerotest provides an in-process fake of the Xero APIs covered by the
+// SDK, to be used in tests together with helpers.Endpoints
+package xerotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/quickaco/xerosdk/auth"
+	"github.com/quickaco/xerosdk/helpers"
+	"golang.org/x/oauth2"
+)
+
+const (
+	accountingPath  = "/api.xro/2.0"
+	connectionsPath = "/connections"
+	authorizePath   = "/identity/connect/authorize"
+	tokenPath       = "/connect/token"
+
+	tenantIDHeader       = "xero-tenant-id"
+	modifiedSinceHeader  = "If-Modified-Since"
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	defaultPageSize = 100
+	tokenExpiry     = 30 * time.Minute
+)
+
+// Request is a request received by the Server
+type Request struct {
+	Method   string
+	Path     string
+	Query    url.Values
+	TenantID string
+	Header   http.Header
+	Body     []byte
+}
+
+// Server is a fake Xero listening on a local address. It emulates the
+// accounting endpoints of the SDK with an in-memory state per tenant, the
+// connections endpoint and the OAuth2 authorize and token endpoints.
+//
+// The lists support the IDs, where, order, page and pageSize parameters and
+// the If-Modified-Since header, any other filter is ignored. The where filters
+// are limited to the comparisons and methods written by the query builder.
+type Server struct {
+	// Now gives the time used for the UpdatedDateUTC of the elements, it can be
+	// replaced for getting deterministic responses
+	Now func() time.Time
+	// Validate is called, when set, with the path of the collection (e.g.
+	// "Invoices") and each element saved. The messages returned reject the
+	// element with a ValidationException, like Xero does with invalid payloads
+	Validate func(path string, element map[string]interface{}) []string
+
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	tenants  []*tenant
+	tokens   map[string]bool
+	refresh  map[string]bool
+	codes    map[string]bool
+	failures []*Failure
+	requests []Request
+}
+
+// NewServer starts a new Server with a single tenant called "Demo Company",
+// it must be closed with Close when the test finishes
+func NewServer() *Server {
+	s := &Server{
+		Now:     time.Now,
+		tokens:  map[string]bool{},
+		refresh: map[string]bool{},
+		codes:   map[string]bool{},
+	}
+	s.AddTenant("Demo Company")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(accountingPath+"/", s.handleAccounting)
+	mux.HandleFunc(connectionsPath, s.handleConnections)
+	mux.HandleFunc(connectionsPath+"/", s.handleConnections)
+	mux.HandleFunc(authorizePath, s.handleAuthorize)
+	mux.HandleFunc(tokenPath, s.handleToken)
+	s.srv = httptest.NewServer(s.record(mux))
+	return s
+}
+
+// Close shuts down the Server
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// URL returns the base URL of the Server
+func (s *Server) URL() string {
+	return s.srv.URL
+}
+
+// Endpoints returns the URLs of the Server to be used in auth.Config,
+// xerosdk.WithEndpoints or helpers.WithEndpoints
+func (s *Server) Endpoints() helpers.Endpoints {
+	return helpers.Endpoints{
+		Accounting:  s.srv.URL + accountingPath,
+		Connections: s.srv.URL + connectionsPath,
+		Authorize:   s.srv.URL + authorizePath,
+		Token:       s.srv.URL + tokenPath,
+	}
+}
+
+// Config returns an auth.Config pointing to the Server
+func (s *Server) Config() auth.Config {
+	return auth.Config{
+		ClientID:     "xerotest",
+		ClientSecret: "xerotest",
+		Scopes:       []string{"offline_access", "accounting.transactions", "accounting.contacts", "accounting.settings"},
+		RedirectURL:  s.srv.URL + "/callback",
+		Endpoints:    s.Endpoints(),
+	}
+}
+
+// Token returns a new valid token for the Server
+func (s *Server) Token() *oauth2.Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newToken()
+}
+
+// Client returns an http.Client authorized for the Server that sends the
+// requests to the given tenant
+func (s *Server) Client(tenantID uuid.UUID) *http.Client {
+	return &http.Client{
+		Transport: &auth.Transport{
+			Base:   &auth.XeroTransport{T: http.DefaultTransport, TenantID: tenantID},
+			Source: oauth2.StaticTokenSource(s.Token()),
+		},
+	}
+}
+
+// AddTenant creates a new organisation with the given name and returns its
+// tenant ID
+func (s *Server) AddTenant(name string) uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := newTenant(name, s.Now())
+	s.tenants = append(s.tenants, t)
+	return t.id
+}
+
+// TenantID returns the ID of the first tenant of the Server
+func (s *Server) TenantID() uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.tenants) == 0 {
+		return uuid.Nil
+	}
+	return s.tenants[0].id
+}
+
+// Seed stores the given elements, e.g. accounting.Contact values, in the
+// collection of the tenant with the given path, e.g. "Contacts"
+func (s *Server) Seed(tenantID uuid.UUID, path string, elements ...interface{}) error {
+	res, ok := findResource(path)
+	if !ok {
+		return errUnknownResource
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tenant(tenantID.String())
+	if t == nil {
+		return errUnknownTenant
+	}
+	for _, element := range elements {
+		buf, err := json.Marshal(element)
+		if err != nil {
+			return err
+		}
+		fields, err := decodeElements(res, buf)
+		if err != nil {
+			return err
+		}
+		for _, f := range fields {
+			if _, err := t.put(res, f, s.Now()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Records returns the elements stored in the collection of the tenant with
+// the given path
+func (s *Server) Records(tenantID uuid.UUID, path string) []json.RawMessage {
+	res, ok := findResource(path)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tenant(tenantID.String())
+	if t == nil {
+		return nil
+	}
+	var records []json.RawMessage
+	for _, r := range t.collections[res.path] {
+		buf, _ := json.Marshal(r.fields)
+		records = append(records, buf)
+	}
+	return records
+}
+
+// Fail makes the Server answer the matching requests with the given Failure
+// instead of handling them
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Times <= 0 {
+		f.Times = 1
+	}
+	s.failures = append(s.failures, &f)
+}
+
+// Requests returns the requests received by the Server so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method:   r.Method,
+			Path:     r.URL.Path,
+			Query:    r.URL.Query(),
+			TenantID: r.Header.Get(tenantIDHeader),
+			Header:   r.Header.Clone(),
+			Body:     body,
+		})
+		failure := s.failure(r.Method, relativePath(r.URL.Path))
+		s.mu.Unlock()
+
+		if failure != nil {
+			failure.write(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// failure returns the first Failure matching the request, if any, and uses
+// one of its times
+func (s *Server) failure(method string, path string) *Failure {
+	for n, f := range s.failures {
+		if !f.matches(method, path) {
+			continue
+		}
+		f.Times--
+		if f.Times == 0 {
+			s.failures = append(s.failures[:n], s.failures[n+1:]...)
+		}
+		return f
+	}
+	return nil
+}
+
+func relativePath(path string) string {
+	switch {
+	case strings.HasPrefix(path, accountingPath+"/"):
+		path = strings.TrimPrefix(path, accountingPath)
+	case path == authorizePath:
+		path = "authorize"
+	case path == tokenPath:
+		path = "token"
+	}
+	return strings.Trim(path, "/")
+}
+
+func (s *Server) tenant(tenantID string) *tenant {
+	for _, t := range s.tenants {
+		if strings.EqualFold(t.id.String(), tenantID) {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[strings.TrimPrefix(header, "Bearer ")]
+}
+
+func (s *Server) handleAccounting(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeProblem(w, http.StatusUnauthorized, "Unauthorized", "AuthenticationUnsuccessful")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.tenant(r.Header.Get(tenantIDHeader))
+	if t == nil {
+		writeProblem(w, http.StatusForbidden, "Forbidden", "AuthenticationUnsuccessful")
+		return
+	}
+
+	// a request repeating the Idempotency-Key of a handled one gets the same
+	// response without being applied again
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" || r.Method == http.MethodGet {
+		s.route(w, r, t)
+		return
+	}
+	rep, ok := t.replies[key]
+	if !ok {
+		recorder := httptest.NewRecorder()
+		s.route(recorder, r, t)
+		rep = &reply{
+			statusCode: recorder.Code,
+			header:     recorder.Header(),
+			body:       recorder.Body.Bytes(),
+		}
+		if rep.statusCode < http.StatusInternalServerError {
+			t.replies[key] = rep
+		}
+	}
+	for k, values := range rep.header {
+		w.Header()[k] = values
+	}
+	w.WriteHeader(rep.statusCode)
+	w.Write(rep.body)
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, t *tenant) {
+	segments := strings.Split(relativePath(r.URL.Path), "/")
+	if len(segments) == 2 && strings.EqualFold(segments[0], "InvoiceReminders") && strings.EqualFold(segments[1], "Settings") {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"InvoiceReminders": []map[string]interface{}{{"Enabled": false}},
+		})
+		return
+	}
+	res, ok := findResource(segments[0])
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+		return
+	}
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		s.list(w, r, t, res)
+	case len(segments) == 1 && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		s.save(w, r, t, res, "")
+	case len(segments) == 2 && r.Method == http.MethodGet:
+		s.get(w, t, res, segments[1])
+	case len(segments) == 2 && r.Method == http.MethodPost:
+		s.save(w, r, t, res, segments[1])
+	case len(segments) == 2 && r.Method == http.MethodDelete:
+		s.remove(w, t, res, segments[1])
+	case len(segments) == 3 && strings.EqualFold(segments[2], "history"):
+		s.history(w, r, t, res, segments[1])
+	case len(segments) == 3 && strings.EqualFold(segments[2], "Allocations") && r.Method == http.MethodPut:
+		s.allocate(w, r, t, res, segments[1])
+	case len(segments) >= 3 && len(segments) <= 4 && strings.EqualFold(segments[2], "Attachments"):
+		s.attachments(w, r, t, res, segments[1], strings.Join(segments[3:], ""))
+	case len(segments) >= 3 && len(segments) <= 4 && strings.EqualFold(segments[2], "Options"):
+		s.options(w, r, t, res, segments[1], strings.Join(segments[3:], ""))
+	default:
+		writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, t *tenant, res resource) {
+	query := r.URL.Query()
+	var ids []string
+	if value := query.Get("IDs"); value != "" {
+		ids = strings.Split(value, ",")
+	}
+	var modifiedSince time.Time
+	if value := r.Header.Get(modifiedSinceHeader); value != "" {
+		var err error
+		if modifiedSince, err = time.Parse(time.RFC3339, value); err != nil {
+			writeValidation(w, "The If-Modified-Since header is not valid")
+			return
+		}
+	}
+	records := t.list(res, ids, modifiedSince)
+	if where := query.Get("where"); where != "" {
+		match, err := parseWhere(where)
+		if err != nil {
+			writeValidation(w, "The where filter is not valid: "+err.Error())
+			return
+		}
+		matching := []*record{}
+		for _, r := range records {
+			if match(r.fields) {
+				matching = append(matching, r)
+			}
+		}
+		records = matching
+	}
+	if order := query.Get("order"); order != "" {
+		sortRecords(records, order)
+	}
+
+	if res.number != "" {
+		s.writeRecords(w, res, t.after(res, records, query))
+		return
+	}
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			page = 1
+		}
+		size := defaultPageSize
+		if value := query.Get("pageSize"); value != "" {
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				size = n
+			}
+		}
+		start := (page - 1) * size
+		if start > len(records) {
+			start = len(records)
+		}
+		end := start + size
+		if end > len(records) {
+			end = len(records)
+		}
+		records = records[start:end]
+	}
+	s.writeRecords(w, res, records)
+}
+
+func (s *Server) get(w http.ResponseWriter, t *tenant, res resource, id string) {
+	r, _ := t.find(res, id)
+	if r == nil {
+		writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+		return
+	}
+	s.writeRecords(w, res, []*record{r})
+}
+
+func (s *Server) save(w http.ResponseWriter, r *http.Request, t *tenant, res resource, id string) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeValidation(w, err.Error())
+		return
+	}
+	elements, err := decodeElements(res, body)
+	if err != nil {
+		writeValidation(w, err.Error())
+		return
+	}
+	if id != "" {
+		if len(elements) != 1 {
+			writeValidation(w, "Only a single element can be updated at "+r.URL.Path)
+			return
+		}
+		elements[0][res.id] = id
+	}
+
+	// with summarizeErrors=false every element is returned with its own status
+	// instead of failing the whole request
+	if strings.EqualFold(r.URL.Query().Get("summarizeErrors"), "false") {
+		s.saveEach(w, t, res, elements)
+		return
+	}
+	// otherwise nothing is saved when any element is invalid
+	for _, fields := range elements {
+		if messages := s.validate(res, fields); len(messages) > 0 {
+			writeValidation(w, messages...)
+			return
+		}
+	}
+	records := make([]*record, 0, len(elements))
+	now := s.Now()
+	for _, fields := range elements {
+		saved, err := t.put(res, fields, now)
+		if err != nil {
+			writeValidation(w, err.Error())
+			return
+		}
+		records = append(records, saved)
+	}
+	s.writeRecords(w, res, records)
+}
+
+func (s *Server) saveEach(w http.ResponseWriter, t *tenant, res resource, elements []map[string]interface{}) {
+	results := make([]map[string]interface{}, 0, len(elements))
+	now := s.Now()
+	for _, fields := range elements {
+		messages := s.validate(res, fields)
+		if len(messages) == 0 {
+			saved, err := t.put(res, fields, now)
+			if err == nil {
+				result := map[string]interface{}{"StatusAttributeString": "OK"}
+				for key, value := range saved.fields {
+					result[key] = value
+				}
+				results = append(results, result)
+				continue
+			}
+			messages = []string{err.Error()}
+		}
+		validationErrors := make([]map[string]interface{}, len(messages))
+		for n, m := range messages {
+			validationErrors[n] = map[string]interface{}{"Message": m}
+		}
+		fields["StatusAttributeString"] = "ERROR"
+		fields["ValidationErrors"] = validationErrors
+		results = append(results, fields)
+	}
+	s.writeElements(w, res, results)
+}
+
+func (s *Server) validate(res resource, fields map[string]interface{}) []string {
+	if s.Validate == nil {
+		return nil
+	}
+	return s.Validate(res.path, fields)
+}
+
+func (s *Server) remove(w http.ResponseWriter, t *tenant, res resource, id string) {
+	r := t.remove(res, id)
+	if r == nil {
+		writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+		return
+	}
+	r.fields["Status"] = "DELETED"
+	s.writeRecords(w, res, []*record{r})
+}
+
+func (s *Server) history(w http.ResponseWriter, r *http.Request, t *tenant, res resource, id string) {
+	if found, _ := t.find(res, id); found == nil {
+		writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+		return
+	}
+	key := res.path + "/" + strings.ToLower(id)
+	switch r.Method {
+	case http.MethodGet:
+		records := t.history[key]
+		if records == nil {
+			records = []map[string]interface{}{}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"HistoryRecords": records})
+	case http.MethodPut:
+		var payload struct {
+			HistoryRecords []map[string]interface{} `json:"HistoryRecords"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeValidation(w, err.Error())
+			return
+		}
+		now := s.Now()
+		for _, fields := range payload.HistoryRecords {
+			fields["Changes"] = "Note"
+			fields["User"] = "xerotest"
+			t.addHistory(res, id, fields, now)
+		}
+		writeJSON(w, http.StatusOK, payload)
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "The method is not allowed")
+	}
+}
+
+// allocate appends the allocations to the ones of the credit, without
+// checking the invoices nor updating the remaining credit
+func (s *Server) allocate(w http.ResponseWriter, r *http.Request, t *tenant, res resource, id string) {
+	found, _ := t.find(res, id)
+	if found == nil {
+		writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+		return
+	}
+	var payload struct {
+		Allocations []interface{} `json:"Allocations"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeValidation(w, err.Error())
+		return
+	}
+	allocations, _ := found.fields["Allocations"].([]interface{})
+	found.fields["Allocations"] = append(allocations, payload.Allocations...)
+	t.touch(res, found, s.Now(), "Allocated")
+	writeJSON(w, http.StatusOK, payload)
+}
+
+// options adds, updates or removes the options nested in a tracking category
+func (s *Server) options(w http.ResponseWriter, r *http.Request, t *tenant, res resource, id string, optionID string) {
+	found, _ := t.find(res, id)
+	if found == nil {
+		writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+		return
+	}
+	options, _ := found.fields["Options"].([]interface{})
+	index := -1
+	for n, option := range options {
+		if fields, ok := option.(map[string]interface{}); ok && optionID != "" && strings.EqualFold(fmt.Sprint(fields["TrackingOptionID"]), optionID) {
+			index = n
+		}
+	}
+	// the options are added with PUT to Options, and updated or removed at
+	// Options/{id}
+	if (optionID == "") != (r.Method == http.MethodPut) || (optionID != "" && index < 0) {
+		writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+		return
+	}
+
+	var option map[string]interface{}
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&option); err != nil {
+			writeValidation(w, err.Error())
+			return
+		}
+		if index < 0 {
+			option["TrackingOptionID"] = uuid.Must(uuid.NewV4()).String()
+			option["Status"] = "ACTIVE"
+			options = append(options, option)
+		} else {
+			for key, value := range option {
+				options[index].(map[string]interface{})[key] = value
+			}
+			option = options[index].(map[string]interface{})
+		}
+	case http.MethodDelete:
+		option = options[index].(map[string]interface{})
+		option["Status"] = "DELETED"
+		options = append(options[:index], options[index+1:]...)
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "The method is not allowed")
+		return
+	}
+	found.fields["Options"] = options
+	t.touch(res, found, s.Now(), "Updated")
+	writeJSON(w, http.StatusOK, map[string]interface{}{"Options": []interface{}{option}})
+}
+
+// attachments lists the files attached to a document, uploads them with PUT
+// or POST to Attachments/{FileName} and downloads them by ID or file name
+func (s *Server) attachments(w http.ResponseWriter, r *http.Request, t *tenant, res resource, id string, name string) {
+	found, _ := t.find(res, id)
+	if found == nil {
+		writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+		return
+	}
+	key := res.path + "/" + strings.ToLower(id)
+	existing := t.attachment(key, name)
+	switch {
+	case name == "" && r.Method == http.MethodGet:
+		elements := []map[string]interface{}{}
+		for _, a := range t.attachments[key] {
+			elements = append(elements, a.fields)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"Attachments": elements})
+	case name != "" && r.Method == http.MethodGet:
+		if existing == nil {
+			writeMessage(w, http.StatusNotFound, "The resource you're looking for cannot be found")
+			return
+		}
+		w.Header().Set("Content-Type", fmt.Sprint(existing.fields["MimeType"]))
+		w.WriteHeader(http.StatusOK)
+		w.Write(existing.content)
+	case name != "" && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		content, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeValidation(w, err.Error())
+			return
+		}
+		if existing == nil {
+			existing = &attachment{fields: map[string]interface{}{
+				"AttachmentID": uuid.Must(uuid.NewV4()).String(),
+			}}
+			t.attachments[key] = append(t.attachments[key], existing)
+		}
+		existing.content = content
+		existing.fields["FileName"] = name
+		existing.fields["MimeType"] = r.Header.Get("Content-Type")
+		existing.fields["ContentLength"] = len(content)
+		existing.fields["Url"] = s.URL() + accountingPath + "/" + res.path + "/" + found.id(res) + "/Attachments/" + url.PathEscape(name)
+		existing.fields["IncludeOnline"] = strings.EqualFold(r.URL.Query().Get("IncludeOnline"), "true")
+		found.fields["HasAttachments"] = true
+		t.touch(res, found, s.Now(), "Attached")
+		writeJSON(w, http.StatusOK, map[string]interface{}{"Attachments": []interface{}{existing.fields}})
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "The method is not allowed")
+	}
+}
+
+func (s *Server) writeRecords(w http.ResponseWriter, res resource, records []*record) {
+	elements := make([]map[string]interface{}, 0, len(records))
+	for _, r := range records {
+		elements = append(elements, r.fields)
+	}
+	s.writeElements(w, res, elements)
+}
+
+func (s *Server) writeElements(w http.ResponseWriter, res resource, elements []map[string]interface{}) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"Id":           uuid.Must(uuid.NewV4()).String(),
+		"Status":       "OK",
+		"ProviderName": "xerotest",
+		"DateTimeUTC":  dotNetDate(s.Now()),
+		res.path:       elements,
+	})
+}
+
+type connection struct {
+	ID             uuid.UUID `json:"id"`
+	TenantID       uuid.UUID `json:"tenantId"`
+	TenantType     string    `json:"tenantType"`
+	TenantName     string    `json:"tenantName"`
+	CreatedDateUtc string    `json:"createdDateUtc"`
+	UpdatedDateUtc string    `json:"updatedDateUtc"`
+}
+
+func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeProblem(w, http.StatusUnauthorized, "Unauthorized", "AuthenticationUnsuccessful")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, connectionsPath), "/")
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		connections := []connection{}
+		for _, t := range s.tenants {
+			connections = append(connections, connection{
+				ID:             t.connectionID,
+				TenantID:       t.id,
+				TenantType:     "ORGANISATION",
+				TenantName:     t.name,
+				CreatedDateUtc: t.created.UTC().Format(time.RFC3339),
+				UpdatedDateUtc: t.created.UTC().Format(time.RFC3339),
+			})
+		}
+		writeJSON(w, http.StatusOK, connections)
+	case id != "" && r.Method == http.MethodDelete:
+		for n, t := range s.tenants {
+			if strings.EqualFold(t.connectionID.String(), id) {
+				s.tenants = append(s.tenants[:n], s.tenants[n+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		writeProblem(w, http.StatusNotFound, "NotFound", "The connection was not found")
+	default:
+		writeProblem(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The method is not allowed")
+	}
+}
+
+// handleAuthorize grants the access straight away, redirecting to the
+// redirect_uri with a new code
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		writeProblem(w, http.StatusBadRequest, "BadRequest", "The redirect_uri is not valid")
+		return
+	}
+	s.mu.Lock()
+	code := newSecret()
+	s.codes[code] = true
+	s.mu.Unlock()
+
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var grants map[string]bool
+	var grant string
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		grants, grant = s.codes, r.PostForm.Get("code")
+	case "refresh_token":
+		grants, grant = s.refresh, r.PostForm.Get("refresh_token")
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	if !grants[grant] {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	delete(grants, grant)
+
+	token := s.newToken()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  token.AccessToken,
+		"refresh_token": token.RefreshToken,
+		"token_type":    token.TokenType,
+		"expires_in":    int(tokenExpiry / time.Second),
+		"scope":         r.PostForm.Get("scope"),
+	})
+}
+
+// newToken must be called holding the lock
+func (s *Server) newToken() *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken:  newSecret(),
+		RefreshToken: newSecret(),
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(tokenExpiry),
+	}
+	s.tokens[token.AccessToken] = true
+	s.refresh[token.RefreshToken] = true
+	return token
+}
+
+func newSecret() string {
+	return strings.Replace(uuid.Must(uuid.NewV4()).String(), "-", "", -1)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeProblem(w http.ResponseWriter, status int, title string, detail string) {
+	writeJSON(w, status, problem{Title: title, Status: status, Detail: detail})
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiException{Type: http.StatusText(status), Message: message})
+}
+
+func writeValidation(w http.ResponseWriter, messages ...string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(validationBody(messages))
+}
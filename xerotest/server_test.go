@@ -0,0 +1,303 @@
+package xerotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerIdempotencyKey(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	cl := s.Client(tenantID)
+	contacts := s.URL() + accountingPath + "/Contacts"
+
+	put := func(key string) (int, string) {
+		req, err := http.NewRequest(http.MethodPut, contacts, strings.NewReader(`{"Contacts":[{"Name":"ACME"}]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(idempotencyKeyHeader, key)
+		}
+		res, err := cl.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		var body bytes.Buffer
+		body.ReadFrom(res.Body)
+		if res.StatusCode != http.StatusOK {
+			return res.StatusCode, ""
+		}
+		return res.StatusCode, firstID(t, body.Bytes(), "Contacts", "ContactID")
+	}
+
+	_, first := put("create-1")
+	_, again := put("create-1")
+	if first == "" || again != first {
+		t.Errorf("repeated Idempotency-Key created %s then %s, want the same contact", first, again)
+	}
+	if _, other := put("create-2"); other == first {
+		t.Error("another Idempotency-Key got the same contact, want a new one")
+	}
+	if _, without := put(""); without == first {
+		t.Error("a request without Idempotency-Key got the same contact, want a new one")
+	}
+	if n := len(s.Records(tenantID, "Contacts")); n != 3 {
+		t.Errorf("%d contacts saved, want 3", n)
+	}
+
+	// the server errors are not kept, so the request can be retried
+	s.Fail(Failure{Path: "Contacts", StatusCode: http.StatusServiceUnavailable})
+	if status, _ := put("create-3"); status != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+	if status, id := put("create-3"); status != http.StatusOK || id == "" {
+		t.Errorf("retry = %d %s, want the contact created", status, id)
+	}
+}
+
+func TestServerSummarizeErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantSaved  int
+		wantResult []string
+	}{
+		{name: "summarized", wantStatus: http.StatusBadRequest, wantSaved: 0},
+		{name: "each element", query: "?summarizeErrors=false", wantStatus: http.StatusOK, wantSaved: 2, wantResult: []string{"OK", "ERROR", "OK"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer()
+			defer s.Close()
+			s.Validate = func(path string, element map[string]interface{}) []string {
+				if element["Name"] == "" {
+					return []string{"The contact name must be specified."}
+				}
+				return nil
+			}
+			tenantID := s.TenantID()
+			status, body := send(t, s.Client(tenantID), http.MethodPut, s.URL()+accountingPath+"/Contacts"+tt.query, "application/json",
+				[]byte(`{"Contacts":[{"Name":"ACME"},{"Name":""},{"Name":"Globex"}]}`))
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d %s, want %d", status, body, tt.wantStatus)
+			}
+			if n := len(s.Records(tenantID, "Contacts")); n != tt.wantSaved {
+				t.Errorf("%d contacts saved, want %d", n, tt.wantSaved)
+			}
+			if tt.wantResult == nil {
+				if !bytes.Contains(body, []byte("ValidationException")) || !bytes.Contains(body, []byte("The contact name must be specified.")) {
+					t.Errorf("body %s, want a ValidationException", body)
+				}
+				return
+			}
+			var payload struct {
+				Contacts []struct {
+					StatusAttributeString string
+					ValidationErrors      []struct{ Message string }
+				}
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatal(err)
+			}
+			var results []string
+			for _, c := range payload.Contacts {
+				results = append(results, c.StatusAttributeString)
+				if c.StatusAttributeString == "ERROR" && (len(c.ValidationErrors) != 1 || c.ValidationErrors[0].Message != "The contact name must be specified.") {
+					t.Errorf("ValidationErrors = %+v, want the message of Validate", c.ValidationErrors)
+				}
+			}
+			if !reflect.DeepEqual(results, tt.wantResult) {
+				t.Errorf("results = %v, want %v", results, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestServerFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		failure Failure
+		method  string
+		path    string
+		// want are the status codes of the requests sent one after the other
+		want []int
+	}{
+		{
+			name:    "once by default",
+			failure: Failure{Path: "Contacts", StatusCode: http.StatusInternalServerError},
+			method:  http.MethodGet,
+			path:    "/Contacts",
+			want:    []int{http.StatusInternalServerError, http.StatusOK},
+		},
+		{
+			name:    "times",
+			failure: Failure{Path: "Contacts", StatusCode: http.StatusServiceUnavailable, Times: 3},
+			method:  http.MethodGet,
+			path:    "/Contacts",
+			want:    []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+		},
+		{
+			name:    "other method",
+			failure: Failure{Method: http.MethodPut, Path: "Contacts", StatusCode: http.StatusInternalServerError},
+			method:  http.MethodGet,
+			path:    "/Contacts",
+			want:    []int{http.StatusOK},
+		},
+		{
+			name:    "other path",
+			failure: Failure{Path: "Invoices", StatusCode: http.StatusInternalServerError},
+			method:  http.MethodGet,
+			path:    "/Contacts",
+			want:    []int{http.StatusOK},
+		},
+		{
+			name:    "path prefix",
+			failure: Failure{Path: "/Invoices/", StatusCode: http.StatusNotFound},
+			method:  http.MethodGet,
+			path:    "/Invoices/5ee2fa2c-7db4-4bc6-9b9a-7bd5f1e6d4c0/History",
+			want:    []int{http.StatusNotFound, http.StatusNotFound},
+		},
+		{
+			name:    "validation",
+			failure: ValidationFailure("Contacts", "The contact name must be unique."),
+			method:  http.MethodGet,
+			path:    "/Contacts",
+			want:    []int{http.StatusBadRequest, http.StatusOK},
+		},
+		{
+			name:    "unauthorized",
+			failure: Unauthorized(""),
+			method:  http.MethodGet,
+			path:    "/Organisations",
+			want:    []int{http.StatusUnauthorized, http.StatusOK},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer()
+			defer s.Close()
+			s.Fail(tt.failure)
+			cl := s.Client(s.TenantID())
+			for n, want := range tt.want {
+				if status, body := send(t, cl, tt.method, s.URL()+accountingPath+tt.path, "", nil); status != want {
+					t.Errorf("request %d = %d %s, want %d", n+1, status, body, want)
+				}
+			}
+		})
+	}
+}
+
+func TestServerRateLimit(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Fail(RateLimit("Invoices", 2*time.Second))
+	res, err := s.Client(s.TenantID()).Get(s.URL() + accountingPath + "/Invoices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", res.StatusCode, http.StatusTooManyRequests)
+	}
+	want := map[string]string{
+		"Retry-After":          "2",
+		"X-Rate-Limit-Problem": "minute",
+		"X-MinLimit-Remaining": "0",
+	}
+	for key, value := range want {
+		if got := res.Header.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestServerList(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	tenantID := s.TenantID()
+	acme := "0c3a4a4f-3b0e-4d59-8f6b-ec57de2e9a11"
+	globex := "7f0e6c2d-8e1b-4c84-9d4e-2d0a5e1fc8b2"
+	invoices := []interface{}{
+		map[string]interface{}{"InvoiceNumber": "INV-1", "Status": "AUTHORISED", "Total": 100, "DateString": "2020-01-15", "Contact": map[string]interface{}{"ContactID": acme, "Name": "ACME"}},
+		map[string]interface{}{"InvoiceNumber": "INV-2", "Status": "DRAFT", "Total": 20.5, "DateString": "2020-02-01", "Contact": map[string]interface{}{"ContactID": globex, "Name": "Globex"}},
+		map[string]interface{}{"InvoiceNumber": "INV-3", "Status": "AUTHORISED", "Total": 300, "DateString": "2020-03-10", "Contact": map[string]interface{}{"ContactID": globex, "Name": "Globex"}},
+		map[string]interface{}{"InvoiceNumber": "INV-4", "Status": "PAID", "Total": 20.5, "DateString": "2019-12-31", "Contact": map[string]interface{}{"ContactID": acme, "Name": "ACME"}, "SentToContact": true},
+	}
+	for _, invoice := range invoices {
+		if err := s.Seed(tenantID, "Invoices", invoice); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		query      url.Values
+		want       []string
+		wantStatus int
+	}{
+		{name: "all", query: url.Values{}, want: []string{"INV-1", "INV-2", "INV-3", "INV-4"}},
+		{name: "string", query: url.Values{"where": {`Status=="AUTHORISED"`}}, want: []string{"INV-1", "INV-3"}},
+		{name: "not equal", query: url.Values{"where": {`Status!="AUTHORISED"`}}, want: []string{"INV-2", "INV-4"}},
+		{name: "number", query: url.Values{"where": {`Total>=100`}}, want: []string{"INV-1", "INV-3"}},
+		{name: "decimal", query: url.Values{"where": {`Total==20.5`}}, want: []string{"INV-2", "INV-4"}},
+		{name: "bool", query: url.Values{"where": {`SentToContact==true`}}, want: []string{"INV-4"}},
+		{name: "null", query: url.Values{"where": {`SentToContact!=null`}}, want: []string{"INV-4"}},
+		{name: "guid", query: url.Values{"where": {`Contact.ContactID==Guid("` + strings.ToUpper(globex) + `")`}}, want: []string{"INV-2", "INV-3"}},
+		{name: "date", query: url.Values{"where": {`Date<DateTime(2020,02,01)`}}, want: []string{"INV-1", "INV-4"}},
+		{name: "method", query: url.Values{"where": {`Contact.Name.StartsWith("Glo")`}}, want: []string{"INV-2", "INV-3"}},
+		{
+			name:  "and or",
+			query: url.Values{"where": {`Status=="AUTHORISED" AND (Total<200 OR Contact.Name.Contains("lob"))`}},
+			want:  []string{"INV-1", "INV-3"},
+		},
+		{name: "order", query: url.Values{"order": {"Date"}}, want: []string{"INV-4", "INV-1", "INV-2", "INV-3"}},
+		{name: "order desc", query: url.Values{"order": {"Total DESC,InvoiceNumber DESC"}}, want: []string{"INV-3", "INV-1", "INV-4", "INV-2"}},
+		{name: "page", query: url.Values{"page": {"2"}, "pageSize": {"3"}}, want: []string{"INV-4"}},
+		{name: "page after the last", query: url.Values{"page": {"3"}, "pageSize": {"3"}}, want: []string{}},
+		{
+			name:  "where, order and page",
+			query: url.Values{"where": {`Total<300`}, "order": {"InvoiceNumber DESC"}, "page": {"1"}, "pageSize": {"2"}},
+			want:  []string{"INV-4", "INV-2"},
+		},
+		{name: "invalid where", query: url.Values{"where": {`Status=="AUTHORISED`}}, wantStatus: http.StatusBadRequest},
+		{name: "unknown operator", query: url.Values{"where": {`Total=<3`}}, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := send(t, s.Client(tenantID), http.MethodGet, s.URL()+accountingPath+"/Invoices?"+tt.query.Encode(), "", nil)
+			if tt.wantStatus == 0 {
+				tt.wantStatus = http.StatusOK
+			}
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d %s, want %d", status, body, tt.wantStatus)
+			}
+			if tt.want == nil {
+				return
+			}
+			var payload struct {
+				Invoices []struct{ InvoiceNumber string }
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, invoice := range payload.Invoices {
+				got = append(got, invoice.InvoiceNumber)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("invoices = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
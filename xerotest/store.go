@@ -0,0 +1,309 @@
+package xerotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+var (
+	errUnknownResource = errors.New("xerotest: unknown resource")
+	errUnknownTenant   = errors.New("xerotest: unknown tenant")
+)
+
+// resource describes an accounting endpoint emulated by the Server
+type resource struct {
+	// path is the URL segment and the key of the collection in the payloads
+	path string
+	// id is the field identifying each element
+	id string
+	// natural is true when the id is given by the caller instead of being
+	// generated, like the Code of the currencies
+	natural bool
+	// number is the field numbering the elements in the order they are
+	// created, like the JournalNumber used as the offset of the journals
+	number string
+}
+
+var resources = []resource{
+	{path: "Accounts", id: "AccountID"},
+	{path: "BankTransactions", id: "BankTransactionID"},
+	{path: "BankTransfers", id: "BankTransferID"},
+	{path: "BatchPayments", id: "BatchPaymentID"},
+	{path: "BrandingThemes", id: "BrandingThemeID"},
+	{path: "ContactGroups", id: "ContactGroupID"},
+	{path: "Contacts", id: "ContactID"},
+	{path: "CreditNotes", id: "CreditNoteID"},
+	{path: "Currencies", id: "Code", natural: true},
+	{path: "Employees", id: "EmployeeID"},
+	{path: "Invoices", id: "InvoiceID"},
+	{path: "Items", id: "ItemID"},
+	{path: "Journals", id: "JournalID", number: "JournalNumber"},
+	{path: "ManualJournals", id: "ManualJournalID"},
+	{path: "Organisations", id: "OrganisationID"},
+	{path: "Overpayments", id: "OverpaymentID"},
+	{path: "Payments", id: "PaymentID"},
+	{path: "Prepayments", id: "PrepaymentID"},
+	{path: "Reports", id: "ReportID", natural: true},
+	{path: "TrackingCategories", id: "TrackingCategoryID"},
+}
+
+func findResource(path string) (resource, bool) {
+	for _, r := range resources {
+		if strings.EqualFold(r.path, path) {
+			return r, true
+		}
+	}
+	return resource{}, false
+}
+
+// record is an element stored in a collection, kept as a generic JSON object
+// so every field sent by the client is given back
+type record struct {
+	fields  map[string]interface{}
+	updated time.Time
+}
+
+func (r *record) id(res resource) string {
+	id, _ := r.fields[res.id].(string)
+	return id
+}
+
+func (r *record) number(res resource) int {
+	n, _ := strconv.Atoi(fmt.Sprint(r.fields[res.number]))
+	return n
+}
+
+// tenant keeps the state of one organisation, nothing is shared between
+// tenants
+type tenant struct {
+	id           uuid.UUID
+	connectionID uuid.UUID
+	name         string
+	created      time.Time
+	collections  map[string][]*record
+	history      map[string][]map[string]interface{}
+	// replies are the responses sent for each Idempotency-Key
+	replies map[string]*reply
+	// attachments are the files of each document, by resource and ID
+	attachments map[string][]*attachment
+}
+
+// attachment is a file attached to a document
+type attachment struct {
+	fields  map[string]interface{}
+	content []byte
+}
+
+// reply is a response kept for answering the requests repeating its
+// Idempotency-Key
+type reply struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+func newTenant(name string, now time.Time) *tenant {
+	t := &tenant{
+		id:           uuid.Must(uuid.NewV4()),
+		connectionID: uuid.Must(uuid.NewV4()),
+		name:         name,
+		created:      now,
+		collections:  map[string][]*record{},
+		history:      map[string][]map[string]interface{}{},
+		replies:      map[string]*reply{},
+		attachments:  map[string][]*attachment{},
+	}
+	org, _ := findResource("Organisations")
+	t.put(org, map[string]interface{}{
+		"OrganisationID":   t.id.String(),
+		"Name":             name,
+		"LegalName":        name,
+		"BaseCurrency":     "NZD",
+		"CountryCode":      "NZ",
+		"OrganisationType": "COMPANY",
+	}, now)
+	return t
+}
+
+func (t *tenant) find(res resource, id string) (*record, int) {
+	for n, r := range t.collections[res.path] {
+		if strings.EqualFold(r.id(res), id) {
+			return r, n
+		}
+	}
+	return nil, -1
+}
+
+// put creates the element, or merges its fields into the stored one when it
+// already exists
+func (t *tenant) put(res resource, fields map[string]interface{}, now time.Time) (*record, error) {
+	id, _ := fields[res.id].(string)
+	if id != "" {
+		if r, _ := t.find(res, id); r != nil {
+			for key, value := range fields {
+				r.fields[key] = value
+			}
+			t.touch(res, r, now, "Updated")
+			return r, nil
+		}
+	}
+	if id == "" {
+		if res.natural {
+			return nil, fmt.Errorf("%s is mandatory", res.id)
+		}
+		fields[res.id] = uuid.Must(uuid.NewV4()).String()
+	}
+	r := &record{fields: fields}
+	if res.number != "" && r.number(res) == 0 {
+		fields[res.number] = json.Number(strconv.Itoa(t.lastNumber(res) + 1))
+	}
+	t.collections[res.path] = append(t.collections[res.path], r)
+	t.touch(res, r, now, "Created")
+	return r, nil
+}
+
+// attachment returns the attachment of the document with the given ID or file
+// name, or nil
+func (t *tenant) attachment(key string, name string) *attachment {
+	if name == "" {
+		return nil
+	}
+	for _, a := range t.attachments[key] {
+		if strings.EqualFold(fmt.Sprint(a.fields["AttachmentID"]), name) || strings.EqualFold(fmt.Sprint(a.fields["FileName"]), name) {
+			return a
+		}
+	}
+	return nil
+}
+
+// lastNumber returns the greatest number of the collection
+func (t *tenant) lastNumber(res resource) int {
+	last := 0
+	for _, r := range t.collections[res.path] {
+		if n := r.number(res); n > last {
+			last = n
+		}
+	}
+	return last
+}
+
+func (t *tenant) remove(res resource, id string) *record {
+	r, n := t.find(res, id)
+	if r == nil {
+		return nil
+	}
+	records := t.collections[res.path]
+	t.collections[res.path] = append(records[:n], records[n+1:]...)
+	return r
+}
+
+func (t *tenant) touch(res resource, r *record, now time.Time, change string) {
+	r.updated = now
+	r.fields["UpdatedDateUTC"] = dotNetDate(now)
+	t.addHistory(res, r.id(res), map[string]interface{}{
+		"Changes": change,
+		"User":    "System Generated",
+	}, now)
+}
+
+func (t *tenant) addHistory(res resource, id string, fields map[string]interface{}, now time.Time) {
+	key := res.path + "/" + strings.ToLower(id)
+	fields["DateUTC"] = dotNetDate(now)
+	t.history[key] = append(t.history[key], fields)
+}
+
+// list returns the elements of the collection matching the given IDs (all of
+// them when empty) modified since the given time, sorted by their update time
+func (t *tenant) list(res resource, ids []string, modifiedSince time.Time) []*record {
+	records := []*record{}
+	for _, r := range t.collections[res.path] {
+		if len(ids) > 0 && !containsFold(ids, r.id(res)) {
+			continue
+		}
+		if !modifiedSince.IsZero() && r.updated.Before(modifiedSince) {
+			continue
+		}
+		records = append(records, r)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].updated.Before(records[j].updated)
+	})
+	return records
+}
+
+// after returns the first defaultPageSize records numbered after the offset
+// of the query, in the order of their numbers. With paymentsOnly only the
+// journals of payments and cash transactions are kept
+func (t *tenant) after(res resource, records []*record, query url.Values) []*record {
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	paymentsOnly := strings.EqualFold(query.Get("paymentsOnly"), "true")
+	result := []*record{}
+	for _, r := range records {
+		if r.number(res) <= offset {
+			continue
+		}
+		if sourceType := fmt.Sprint(r.fields["SourceType"]); paymentsOnly && !strings.HasSuffix(sourceType, "PAYMENT") && !strings.HasPrefix(sourceType, "CASH") {
+			continue
+		}
+		result = append(result, r)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].number(res) < result[j].number(res)
+	})
+	if len(result) > defaultPageSize {
+		result = result[:defaultPageSize]
+	}
+	return result
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeElements reads a payload that may be either the collection envelope,
+// e.g. {"Invoices": [...]}, or a single element
+func decodeElements(res resource, body []byte) ([]map[string]interface{}, error) {
+	var payload map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+	collection, ok := payload[res.path]
+	if !ok {
+		return []map[string]interface{}{payload}, nil
+	}
+	elements, ok := collection.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list", res.path)
+	}
+	result := make([]map[string]interface{}, 0, len(elements))
+	for _, element := range elements {
+		fields, ok := element.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the elements of %s must be objects", res.path)
+		}
+		result = append(result, fields)
+	}
+	return result, nil
+}
+
+// dotNetDate formats the time like the Xero API does, e.g. /Date(1573755038314+0000)/
+func dotNetDate(t time.Time) string {
+	return fmt.Sprintf("/Date(%d+0000)/", t.UnixNano()/int64(time.Millisecond))
+}